@@ -1,12 +1,14 @@
 package main
 
 import (
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/golang/glog"
 	osclientset "github.com/openshift/client-go/config/clientset/versioned"
+	mhcclientset "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned"
 )
 
 // ClientBuilder can create a variety of kubernetes client interface
@@ -25,6 +27,16 @@ func (cb *ClientBuilder) OpenshiftClientOrDie(name string) osclientset.Interface
 	return osclientset.NewForConfigOrDie(rest.AddUserAgent(cb.config, name))
 }
 
+// MachineHealthCheckClientOrDie returns the kubernetes client interface for machine health check objects.
+func (cb *ClientBuilder) MachineHealthCheckClientOrDie(name string) mhcclientset.Interface {
+	return mhcclientset.NewForConfigOrDie(rest.AddUserAgent(cb.config, name))
+}
+
+// DynamicClientOrDie returns the dynamic client interface for the objects without a typed client, like machines.
+func (cb *ClientBuilder) DynamicClientOrDie(name string) dynamic.Interface {
+	return dynamic.NewForConfigOrDie(rest.AddUserAgent(cb.config, name))
+}
+
 // NewClientBuilder returns a *ClientBuilder with the given kubeconfig.
 func NewClientBuilder(kubeconfig string) (*ClientBuilder, error) {
 	var config *rest.Config
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	mhcinformers "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machinedisruptionbudget"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machinehealthcheck"
+	"github.com/openshift/machine-health-check-operator/pkg/metrics"
+	mhcresourcelock "github.com/openshift/machine-health-check-operator/pkg/resourcelock"
+	"github.com/openshift/machine-health-check-operator/pkg/version"
+	"github.com/openshift/machine-health-check-operator/pkg/webhook"
+	"github.com/spf13/cobra"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	coreclientsetv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// controllerName contains the name of the machine health check controller and its leader election lock
+	controllerName = "machine-health-check-controller"
+)
+
+var (
+	controllerCmd = &cobra.Command{
+		Use:   "controller",
+		Short: "Starts Machine Health Check Controller",
+		Long: "Runs the machine health check controller deployed by the operator, it remediates the unhealthy machines selected by the machine health checks " +
+			"and keeps the status of the machine disruption budgets honored by the remediation up to date.",
+		Run: runControllerCmd,
+	}
+
+	controllerOpts struct {
+		kubeconfig  string
+		namespace   string
+		metricsAddr string
+		dryRun      bool
+
+		webhook struct {
+			addr    string
+			certDir string
+		}
+
+		leaderElection struct {
+			enabled      bool
+			resourceLock string
+		}
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(controllerCmd)
+	controllerCmd.PersistentFlags().StringVar(&controllerOpts.kubeconfig, "kubeconfig", "", "Kubeconfig file to access a remote cluster (testing only)")
+	controllerCmd.PersistentFlags().StringVar(&controllerOpts.namespace, "namespace", componentNamespace, "Namespace of the machine health checks and machines watched by the controller")
+	controllerCmd.PersistentFlags().StringVar(&controllerOpts.metricsAddr, "metrics-addr", metrics.DefaultMetricsAddress, "Address the metrics server listens on")
+	controllerCmd.PersistentFlags().BoolVar(&controllerOpts.dryRun, "dry-run", false, "Report the unhealthy machines that would have been remediated without remediating them")
+	controllerCmd.PersistentFlags().StringVar(&controllerOpts.webhook.addr, "webhook-addr", webhook.DefaultWebhookAddress, "Address the admission webhook server listens on")
+	controllerCmd.PersistentFlags().StringVar(&controllerOpts.webhook.certDir, "webhook-cert-dir", "", "Directory with the tls.crt and tls.key serving certificate files of the admission webhook server, the server is not started when empty")
+	controllerCmd.PersistentFlags().BoolVar(&controllerOpts.leaderElection.enabled, "leader-elect", true, "Start a leader election client and gain leadership before running the controller (disable for local development only)")
+	controllerCmd.PersistentFlags().StringVar(&controllerOpts.leaderElection.resourceLock, "leader-elect-resource-lock", ResourceLockType, fmt.Sprintf("Type of the resource object used for locking during leader election, one of %q, %q or %q", resourcelock.ConfigMapsResourceLock, resourcelock.LeasesResourceLock, mhcresourcelock.ConfigMapsLeasesResourceLock))
+}
+
+func runControllerCmd(cmd *cobra.Command, args []string) {
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	// To help debugging, immediately log version
+	glog.Infof("Version: %+v", version.Get())
+
+	cb, err := NewClientBuilder(controllerOpts.kubeconfig)
+	if err != nil {
+		glog.Fatalf("error creating clients: %v", err)
+	}
+	ctx := setupSignalContext()
+
+	// the metrics providers have to be set before the leader elector and the workqueue are created
+	metrics.Register()
+	metrics.StartMetricsServer(controllerOpts.metricsAddr, nil, ctx.Done())
+	// the admission of the machine health checks is reviewed by all the replicas, not only the leader
+	if controllerOpts.webhook.certDir != "" {
+		webhook.StartWebhookServer(controllerOpts.webhook.addr, controllerOpts.webhook.certDir, ctx.Done())
+	} else {
+		glog.Warning("Webhook certificate directory is not set, the machine health checks are not validated on admission")
+	}
+
+	if !controllerOpts.leaderElection.enabled {
+		glog.Warning("Leader election is disabled, make sure only a single controller is running")
+		runMachineControllers(ctx, cb)
+		glog.Info("Machine health check controller stopped")
+		glog.Flush()
+		return
+	}
+
+	lock, err := CreateResourceLock(cb, controllerOpts.leaderElection.resourceLock, controllerOpts.namespace, controllerName)
+	if err != nil {
+		glog.Fatalf("error creating leader election lock: %v", err)
+	}
+
+	// the leader election context is canceled only once the controller stopped,
+	// so the lock is released after the queue is drained
+	leaderElectionCtx, cancelLeaderElection := context.WithCancel(context.Background())
+	shutdown := &controllersShutdown{}
+	go func() {
+		<-ctx.Done()
+		shutdown.wait()
+		cancelLeaderElection()
+	}()
+
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            controllerName,
+		LeaseDuration:   LeaseDuration,
+		RenewDeadline:   RenewDeadline,
+		RetryPeriod:     RetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				if !shutdown.start() {
+					return
+				}
+				defer shutdown.done()
+
+				// the controller stops when the shutdown signal is received or when the leadership is lost
+				controllerCtx, cancel := context.WithCancel(leaderCtx)
+				defer cancel()
+				go func() {
+					select {
+					case <-ctx.Done():
+						cancel()
+					case <-controllerCtx.Done():
+					}
+				}()
+
+				runMachineControllers(controllerCtx, cb)
+			},
+			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					glog.Info("Released leader election lock")
+					return
+				}
+				glog.Error("Leader election lost")
+			},
+		},
+	})
+	if err != nil {
+		glog.Fatalf("error creating leader elector: %v", err)
+	}
+	le.Run(leaderElectionCtx)
+
+	if ctx.Err() == nil {
+		glog.Flush()
+		os.Exit(1)
+	}
+	glog.Info("Machine health check controller stopped")
+	glog.Flush()
+}
+
+// runMachineControllers starts the informers and runs the machine health check and the machine
+// disruption budget controllers until the context is canceled and their queues are drained.
+func runMachineControllers(ctx context.Context, cb *ClientBuilder) {
+	kubeClient := cb.KubeClientOrDie(controllerName)
+	machineClient := cb.DynamicClientOrDie(controllerName)
+	mhcClient := cb.MachineHealthCheckClientOrDie(controllerName)
+
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, resyncPeriod()())
+	machineInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(machineClient, resyncPeriod()(), controllerOpts.namespace, nil)
+	mhcInformerFactory := mhcinformers.NewSharedInformerFactoryWithOptions(mhcClient, resyncPeriod()(), mhcinformers.WithNamespace(controllerOpts.namespace))
+
+	recorder := initControllerRecorder(kubeClient)
+	mhcController := machinehealthcheck.New(
+		mhcInformerFactory.Healthchecking().V1alpha1().MachineHealthChecks(),
+		mhcInformerFactory.Healthchecking().V1alpha1().MachineDisruptionBudgets(),
+		machineInformerFactory.ForResource(machine.Resource),
+		kubeInformerFactory.Core().V1().Nodes(),
+		kubeClient,
+		machineClient,
+		mhcClient,
+		recorder,
+		controllerOpts.dryRun,
+	)
+	mdbController := machinedisruptionbudget.New(
+		mhcInformerFactory.Healthchecking().V1alpha1().MachineDisruptionBudgets(),
+		machineInformerFactory.ForResource(machine.Resource),
+		kubeInformerFactory.Core().V1().Nodes(),
+		mhcClient,
+		recorder,
+	)
+
+	kubeInformerFactory.Start(ctx.Done())
+	machineInformerFactory.Start(ctx.Done())
+	mhcInformerFactory.Start(ctx.Done())
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		mdbController.Run(1, ctx.Done())
+	}()
+	go func() {
+		defer wg.Done()
+		mhcController.Run(2, ctx.Done())
+	}()
+	wg.Wait()
+}
+
+func initControllerRecorder(kubeClient kubernetes.Interface) record.EventRecorder {
+	eventRecorderScheme := runtime.NewScheme()
+	healthcheckingv1alpha1.AddToScheme(eventRecorderScheme)
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(glog.Infof)
+	eventBroadcaster.StartRecordingToSink(&coreclientsetv1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	return eventBroadcaster.NewRecorder(eventRecorderScheme, v1.EventSource{Component: controllerName})
+}
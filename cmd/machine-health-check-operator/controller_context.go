@@ -5,6 +5,7 @@ import (
 
 	"github.com/openshift/machine-health-check-operator/pkg/operator"
 	configinformersv1 "github.com/openshift/client-go/config/informers/externalversions"
+	mhcinformers "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -18,6 +19,7 @@ type ControllerContext struct {
 	DeploymentInformerFactory informers.SharedInformerFactory
 	ConfigMapInformerFactory  informers.SharedInformerFactory
 	ConfigInformerFactory     configinformersv1.SharedInformerFactory
+	MHCInformerFactory        mhcinformers.SharedInformerFactory
 
 	AvailableResources map[schema.GroupVersionResource]bool
 
@@ -32,6 +34,7 @@ type ControllerContext struct {
 func CreateControllerContext(cb *ClientBuilder, stop <-chan struct{}, targetNamespace string) *ControllerContext {
 	kubeClient := cb.KubeClientOrDie("kube-shared-informer")
 	configClient := cb.OpenshiftClientOrDie("config-shared-informer")
+	mhcClient := cb.MachineHealthCheckClientOrDie("mhc-shared-informer")
 
 	configMapInformerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod()(), informers.WithNamespace(targetNamespace))
 	tweakListOptions := func(listOptions *metav1.ListOptions) {
@@ -40,12 +43,14 @@ func CreateControllerContext(cb *ClientBuilder, stop <-chan struct{}, targetName
 	deploymentInformerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, resyncPeriod()(), informers.WithTweakListOptions(tweakListOptions), informers.WithNamespace(targetNamespace))
 
 	configInformerFactory := configinformersv1.NewSharedInformerFactoryWithOptions(configClient, resyncPeriod()(), configinformersv1.WithNamespace(targetNamespace))
+	mhcInformerFactory := mhcinformers.NewSharedInformerFactory(mhcClient, resyncPeriod()())
 
 	return &ControllerContext{
 		ClientBuilder:             cb,
 		DeploymentInformerFactory: deploymentInformerFactory,
 		ConfigMapInformerFactory:  configMapInformerFactory,
 		ConfigInformerFactory:     configInformerFactory,
+		MHCInformerFactory:        mhcInformerFactory,
 		Stop:                      stop,
 		InformersStarted:          make(chan struct{}),
 		ResyncPeriod:              resyncPeriod(),
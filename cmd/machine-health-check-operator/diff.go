@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/openshift/machine-health-check-operator/pkg/operator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffCmd = &cobra.Command{
+		Use:   "diff",
+		Short: "Compares the live operands to the ones applied by the Machine Health Check Operator",
+		Long: "Fetches the live operands and prints the fields managed by the operator that differ from the desired state, " +
+			"built from the cluster configuration the same way the operator does it. " +
+			"The command exits with status 1 when the operands differ or are missing.",
+		Run: runDiffCmd,
+	}
+
+	diffOpts struct {
+		kubeconfig      string
+		namespace       string
+		rolloutDeadline time.Duration
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.PersistentFlags().StringVar(&diffOpts.kubeconfig, "kubeconfig", "", "Kubeconfig file to access the cluster, the in-cluster configuration is used when it is not set")
+	diffCmd.PersistentFlags().StringVar(&config, "config", operator.DefaultOperatorConfigName, "Name of the MachineHealthCheckOperatorConfig object that configures the operator")
+	diffCmd.PersistentFlags().StringVar(&diffOpts.namespace, "namespace", componentNamespace, "Namespace the operands are deployed to")
+	diffCmd.PersistentFlags().DurationVar(&diffOpts.rolloutDeadline, "rollout-deadline", operator.DefaultRolloutDeadline, "Time the machine health check controller deployment has to make a rollout progress before it is reported as failed")
+}
+
+func runDiffCmd(cmd *cobra.Command, args []string) {
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	differ, err := diff(os.Stdout)
+	if err != nil {
+		glog.Exitf("Error comparing machine health check operator operands: %v", err)
+	}
+	glog.Flush()
+	if differ {
+		os.Exit(1)
+	}
+}
+
+// diff prints the differences between the live and the desired operands and
+// returns whether any operand differs from its desired state.
+func diff(out io.Writer) (bool, error) {
+	cb, err := NewClientBuilder(diffOpts.kubeconfig)
+	if err != nil {
+		return false, fmt.Errorf("error creating clients: %v", err)
+	}
+
+	operatorConfig, err := operator.ConfigFromCluster(
+		cb.KubeClientOrDie(componentName),
+		cb.OpenshiftClientOrDie(componentName),
+		cb.MachineHealthCheckClientOrDie(componentName),
+		diffOpts.namespace,
+		config,
+		diffOpts.rolloutDeadline,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	diffs, err := operator.DiffOperands(cb.KubeClientOrDie(componentName), operatorConfig)
+	if err != nil {
+		return false, err
+	}
+	if len(diffs) == 0 {
+		fmt.Fprintf(out, "Operands are not managed by the operator (managementState: %s)\n", operatorConfig.Spec.ManagementState)
+		return false, nil
+	}
+	return printOperandDiffs(out, diffs)
+}
+
+func printOperandDiffs(out io.Writer, diffs []operator.OperandDiff) (bool, error) {
+	differ := false
+	for _, d := range diffs {
+		switch {
+		case d.Missing:
+			differ = true
+			fmt.Fprintf(out, "%s %s: missing\n", d.Kind, operandName(d))
+		case len(d.Fields) == 0:
+			fmt.Fprintf(out, "%s %s: up to date\n", d.Kind, operandName(d))
+		default:
+			differ = true
+			fmt.Fprintf(out, "%s %s:\n", d.Kind, operandName(d))
+			for _, f := range d.Fields {
+				live, err := json.Marshal(f.Live)
+				if err != nil {
+					return false, err
+				}
+				desired, err := json.Marshal(f.Desired)
+				if err != nil {
+					return false, err
+				}
+				fmt.Fprintf(out, "  %s:\n  - live:    %s\n  + desired: %s\n", f.Path, live, desired)
+			}
+		}
+	}
+	return differ, nil
+}
+
+func operandName(d operator.OperandDiff) string {
+	if d.Namespace == "" {
+		return d.Name
+	}
+	return d.Namespace + "/" + d.Name
+}
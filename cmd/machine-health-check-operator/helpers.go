@@ -6,9 +6,9 @@ import (
 	"time"
 
 	"github.com/golang/glog"
+	mhcresourcelock "github.com/openshift/machine-health-check-operator/pkg/resourcelock"
 
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
@@ -22,6 +22,8 @@ const (
 	RenewDeadline = 60 * time.Second
 	// RetryPeriod is the default duration for the leader electrion retrial.
 	RetryPeriod = 30 * time.Second
+	// ResourceLockType is the default type of the leader election lock.
+	ResourceLockType = resourcelock.ConfigMapsResourceLock
 
 	minResyncPeriod = 10 * time.Minute
 )
@@ -33,8 +35,8 @@ func resyncPeriod() func() time.Duration {
 	}
 }
 
-// CreateResourceLock returns an interface for the resource lock.
-func CreateResourceLock(cb *ClientBuilder, componentNamespace, componentName string) resourcelock.Interface {
+// CreateResourceLock returns an interface for the resource lock of the given type.
+func CreateResourceLock(cb *ClientBuilder, lockType, componentNamespace, componentName string) (resourcelock.Interface, error) {
 	recorder := record.
 		NewBroadcaster().
 		NewRecorder(scheme.Scheme, v1.EventSource{Component: componentName})
@@ -47,15 +49,9 @@ func CreateResourceLock(cb *ClientBuilder, componentNamespace, componentName str
 	// add a uniquifier so that two processes on the same host don't accidentally both become active
 	id = id + "_" + string(uuid.NewUUID())
 
-	return &resourcelock.ConfigMapLock{
-		ConfigMapMeta: metav1.ObjectMeta{
-			Namespace: componentNamespace,
-			Name:      componentName,
-		},
-		Client: cb.KubeClientOrDie("leader-election").CoreV1(),
-		LockConfig: resourcelock.ResourceLockConfig{
-			Identity:      id,
-			EventRecorder: recorder,
-		},
-	}
+	kubeClient := cb.KubeClientOrDie("leader-election")
+	return mhcresourcelock.New(lockType, componentNamespace, componentName, kubeClient.CoreV1(), kubeClient.CoordinationV1(), resourcelock.ResourceLockConfig{
+		Identity:      id,
+		EventRecorder: recorder,
+	})
 }
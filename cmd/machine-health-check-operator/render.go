@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/glog"
+	osconfigv1 "github.com/openshift/api/config/v1"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/operator"
+	"github.com/openshift/machine-health-check-operator/tools/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renderCmd = &cobra.Command{
+		Use:   "render",
+		Short: "Prints the operand manifests applied by the Machine Health Check Operator",
+		Long:  "Renders the operand manifests, the operator would apply with the given images, feature set and configuration, without accessing the cluster.",
+		Run:   runRenderCmd,
+	}
+
+	renderOpts struct {
+		imagesJSON      string
+		featureSet      string
+		namespace       string
+		operatorConfig  string
+		rolloutDeadline time.Duration
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+	renderCmd.PersistentFlags().StringVar(&renderOpts.imagesJSON, "images-json", "", "Path to the images.json file with the machine API operator images")
+	renderCmd.PersistentFlags().StringVar(&renderOpts.featureSet, "feature-set", string(osconfigv1.Default), "Feature set enabled in the cluster")
+	renderCmd.PersistentFlags().StringVar(&renderOpts.namespace, "namespace", componentNamespace, "Namespace the operands are deployed to")
+	renderCmd.PersistentFlags().StringVar(&renderOpts.operatorConfig, "operator-config", "", "Path to the MachineHealthCheckOperatorConfig manifest, the default configuration is used when it is not set")
+	renderCmd.PersistentFlags().DurationVar(&renderOpts.rolloutDeadline, "rollout-deadline", operator.DefaultRolloutDeadline, "Time the machine health check controller deployment has to make a rollout progress before it is reported as failed")
+}
+
+func runRenderCmd(cmd *cobra.Command, args []string) {
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	if err := render(); err != nil {
+		glog.Exitf("Error rendering machine health check operator manifests: %v", err)
+	}
+}
+
+func render() error {
+	if renderOpts.imagesJSON == "" {
+		return fmt.Errorf("--images-json is required")
+	}
+	imagesJSON, err := ioutil.ReadFile(renderOpts.imagesJSON)
+	if err != nil {
+		return err
+	}
+
+	operatorConfig := &healthcheckingv1alpha1.MachineHealthCheckOperatorConfig{}
+	if renderOpts.operatorConfig != "" {
+		data, err := ioutil.ReadFile(renderOpts.operatorConfig)
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(data, operatorConfig); err != nil {
+			return fmt.Errorf("failed to parse operator config %q: %v", renderOpts.operatorConfig, err)
+		}
+	}
+
+	config, err := operator.RenderConfig(renderOpts.namespace, imagesJSON, osconfigv1.FeatureSet(renderOpts.featureSet), renderOpts.rolloutDeadline, operatorConfig.Spec)
+	if err != nil {
+		return err
+	}
+
+	objects, err := operator.RenderOperands(config)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		if err := utils.MarshallObject(obj, os.Stdout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
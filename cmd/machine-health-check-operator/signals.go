@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/golang/glog"
+)
+
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// setupSignalContext returns a context that is canceled on SIGTERM or SIGINT.
+// The process exits immediately when the signal is received for the second time.
+func setupSignalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, shutdownSignals...)
+	go func() {
+		sig := <-c
+		glog.Infof("Received %v signal, shutting down", sig)
+		cancel()
+		<-c
+		glog.Warning("Received second shutdown signal, exiting")
+		glog.Flush()
+		os.Exit(1)
+	}()
+
+	return ctx
+}
+
+// controllersShutdown tracks the controllers started by the leader, so the leader
+// election lock is released only once they stopped.
+type controllersShutdown struct {
+	lock     sync.Mutex
+	stopping bool
+	running  sync.WaitGroup
+}
+
+// start returns false when the shutdown already began and the controllers must not be started.
+func (s *controllersShutdown) start() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.stopping {
+		return false
+	}
+	s.running.Add(1)
+	return true
+}
+
+// done marks the started controllers as stopped.
+func (s *controllersShutdown) done() {
+	s.running.Done()
+}
+
+// wait prevents the controllers from being started and waits for the running ones to stop.
+func (s *controllersShutdown) wait() {
+	s.lock.Lock()
+	s.stopping = true
+	s.lock.Unlock()
+	s.running.Wait()
+}
@@ -3,8 +3,15 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
 
+	"github.com/openshift/machine-health-check-operator/pkg/health"
+	"github.com/openshift/machine-health-check-operator/pkg/metrics"
 	"github.com/openshift/machine-health-check-operator/pkg/operator"
+	mhcresourcelock "github.com/openshift/machine-health-check-operator/pkg/resourcelock"
 	"github.com/openshift/machine-health-check-operator/pkg/version"
 	"github.com/golang/glog"
 	osconfigv1 "github.com/openshift/api/config/v1"
@@ -15,6 +22,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	coreclientsetv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -27,13 +35,34 @@ var (
 	}
 
 	startOpts struct {
-		kubeconfig string
+		kubeconfig      string
+		rolloutDeadline time.Duration
+		metricsAddr     string
+
+		workerStuckThreshold time.Duration
+
+		leaderElection struct {
+			enabled       bool
+			resourceLock  string
+			leaseDuration time.Duration
+			renewDeadline time.Duration
+			retryPeriod   time.Duration
+		}
 	}
 )
 
 func init() {
 	rootCmd.AddCommand(startCmd)
 	startCmd.PersistentFlags().StringVar(&startOpts.kubeconfig, "kubeconfig", "", "Kubeconfig file to access a remote cluster (testing only)")
+	startCmd.PersistentFlags().StringVar(&config, "config", operator.DefaultOperatorConfigName, "Name of the MachineHealthCheckOperatorConfig object that configures the operator")
+	startCmd.PersistentFlags().DurationVar(&startOpts.rolloutDeadline, "rollout-deadline", operator.DefaultRolloutDeadline, "Time the machine health check controller deployment has to make a rollout progress before it is reported as failed")
+	startCmd.PersistentFlags().StringVar(&startOpts.metricsAddr, "metrics-addr", metrics.DefaultMetricsAddress, "Address the metrics server, serving also the /healthz and /readyz probes, listens on")
+	startCmd.PersistentFlags().DurationVar(&startOpts.workerStuckThreshold, "worker-stuck-threshold", operator.DefaultWorkerStuckThreshold, "Time a single operator sync can take before the operator is reported as unhealthy")
+	startCmd.PersistentFlags().BoolVar(&startOpts.leaderElection.enabled, "leader-elect", true, "Start a leader election client and gain leadership before running the operator (disable for local development only)")
+	startCmd.PersistentFlags().StringVar(&startOpts.leaderElection.resourceLock, "leader-elect-resource-lock", ResourceLockType, fmt.Sprintf("Type of the resource object used for locking during leader election, one of %q, %q or %q", resourcelock.ConfigMapsResourceLock, resourcelock.LeasesResourceLock, mhcresourcelock.ConfigMapsLeasesResourceLock))
+	startCmd.PersistentFlags().DurationVar(&startOpts.leaderElection.leaseDuration, "leader-elect-lease-duration", LeaseDuration, "Duration non-leader candidates wait after observing a leadership renewal before attempting to acquire the leadership")
+	startCmd.PersistentFlags().DurationVar(&startOpts.leaderElection.renewDeadline, "leader-elect-renew-deadline", RenewDeadline, "Duration the leader retries refreshing the leadership before giving it up, has to be less than the lease duration")
+	startCmd.PersistentFlags().DurationVar(&startOpts.leaderElection.retryPeriod, "leader-elect-retry-period", RetryPeriod, "Duration the clients wait between attempts to acquire or renew the leadership")
 }
 
 func runStartCmd(cmd *cobra.Command, args []string) {
@@ -47,30 +76,107 @@ func runStartCmd(cmd *cobra.Command, args []string) {
 	if err != nil {
 		glog.Fatalf("error creating clients: %v", err)
 	}
-	stopCh := make(chan struct{})
+	ctx := setupSignalContext()
+
+	// a candidate waiting for the leadership is ready, so a rolling update hands the leadership
+	// over to the new pod, the operator checks are added once the leadership is acquired
+	liveness := &health.Checker{}
+	readiness := &health.Checker{}
+
+	// the metrics providers have to be set before the leader elector and the workqueue are created
+	metrics.Register()
+	metrics.StartMetricsServer(startOpts.metricsAddr, map[string]http.Handler{
+		"/healthz": liveness,
+		"/readyz":  readiness,
+	}, ctx.Done())
+
+	if !startOpts.leaderElection.enabled {
+		glog.Warning("Leader election is disabled, make sure only a single operator is running")
+		runControllers(ctx, cb, liveness, readiness)
+		glog.Info("Machine health check operator stopped")
+		glog.Flush()
+		return
+	}
 
-	leaderelection.RunOrDie(context.TODO(), leaderelection.LeaderElectionConfig{
-		Lock:          CreateResourceLock(cb, componentNamespace, componentName),
-		LeaseDuration: LeaseDuration,
-		RenewDeadline: RenewDeadline,
-		RetryPeriod:   RetryPeriod,
+	lock, err := CreateResourceLock(cb, startOpts.leaderElection.resourceLock, componentNamespace, componentName)
+	if err != nil {
+		glog.Fatalf("error creating leader election lock: %v", err)
+	}
+
+	// the leader election context is canceled only once the controllers stopped,
+	// so the lock is released after the queue is drained
+	leaderElectionCtx, cancelLeaderElection := context.WithCancel(context.Background())
+	shutdown := &controllersShutdown{}
+	go func() {
+		<-ctx.Done()
+		shutdown.wait()
+		cancelLeaderElection()
+	}()
+
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            componentName,
+		LeaseDuration:   startOpts.leaderElection.leaseDuration,
+		RenewDeadline:   startOpts.leaderElection.renewDeadline,
+		RetryPeriod:     startOpts.leaderElection.retryPeriod,
+		ReleaseOnCancel: true,
 		Callbacks: leaderelection.LeaderCallbacks{
-			OnStartedLeading: func(ctx context.Context) {
-				ctrlCtx := CreateControllerContext(cb, stopCh, componentNamespace)
-				startControllers(ctrlCtx)
-				ctrlCtx.ConfigMapInformerFactory.Start(ctrlCtx.Stop)
-				ctrlCtx.DeploymentInformerFactory.Start(ctrlCtx.Stop)
-				ctrlCtx.ConfigInformerFactory.Start(ctrlCtx.Stop)
-				close(ctrlCtx.InformersStarted)
-
-				select {}
+			OnStartedLeading: func(leaderCtx context.Context) {
+				if !shutdown.start() {
+					return
+				}
+				defer shutdown.done()
+
+				// the controllers stop when the shutdown signal is received or when the leadership is lost
+				controllersCtx, cancel := context.WithCancel(leaderCtx)
+				defer cancel()
+				go func() {
+					select {
+					case <-ctx.Done():
+						cancel()
+					case <-controllersCtx.Done():
+					}
+				}()
+
+				runControllers(controllersCtx, cb, liveness, readiness)
 			},
 			OnStoppedLeading: func() {
-				glog.Fatalf("Leader election lost")
+				if ctx.Err() != nil {
+					glog.Info("Released leader election lock")
+					return
+				}
+				glog.Error("Leader election lost")
 			},
 		},
 	})
-	panic("unreachable")
+	if err != nil {
+		glog.Fatalf("error creating leader elector: %v", err)
+	}
+	le.Run(leaderElectionCtx)
+
+	// a shut down operator exits cleanly, while the one that lost the leadership
+	// exits with an error to be restarted and to rejoin the leader election
+	if ctx.Err() == nil {
+		glog.Flush()
+		os.Exit(1)
+	}
+	glog.Info("Machine health check operator stopped")
+	glog.Flush()
+}
+
+// runControllers starts the informers and runs the operator until the context is canceled
+// and the operator queue is drained. The informers stop once the context is canceled.
+func runControllers(ctx context.Context, cb *ClientBuilder, liveness, readiness *health.Checker) {
+	ctrlCtx := CreateControllerContext(cb, ctx.Done(), componentNamespace)
+	optr := startControllers(ctrlCtx)
+	addHealthChecks(liveness, readiness, optr)
+	ctrlCtx.ConfigMapInformerFactory.Start(ctrlCtx.Stop)
+	ctrlCtx.DeploymentInformerFactory.Start(ctrlCtx.Stop)
+	ctrlCtx.ConfigInformerFactory.Start(ctrlCtx.Stop)
+	ctrlCtx.MHCInformerFactory.Start(ctrlCtx.Stop)
+	close(ctrlCtx.InformersStarted)
+
+	optr.Run(2, ctrlCtx.Stop)
 }
 
 func initRecorder(kubeClient kubernetes.Interface) record.EventRecorder {
@@ -82,17 +188,34 @@ func initRecorder(kubeClient kubernetes.Interface) record.EventRecorder {
 	return eventBroadcaster.NewRecorder(eventRecorderScheme, v1.EventSource{Component: "machinehealthcheckoperator"})
 }
 
-func startControllers(ctx *ControllerContext) {
+// startControllers creates the operator, which has to be run once the informers are started.
+func startControllers(ctx *ControllerContext) *operator.Operator {
 	kubeClient := ctx.ClientBuilder.KubeClientOrDie(componentName)
 	recorder := initRecorder(kubeClient)
-	go operator.New(
+	optr := operator.New(
 		componentNamespace, componentName,
 		config,
+		startOpts.rolloutDeadline,
 		ctx.ConfigMapInformerFactory.Core().V1().ConfigMaps(),
 		ctx.DeploymentInformerFactory.Apps().V1().Deployments(),
 		ctx.ConfigInformerFactory.Config().V1().FeatureGates(),
+		ctx.MHCInformerFactory.Healthchecking().V1alpha1().MachineHealthCheckOperatorConfigs(),
 		ctx.ClientBuilder.KubeClientOrDie(componentName),
 		ctx.ClientBuilder.OpenshiftClientOrDie(componentName),
+		ctx.ClientBuilder.MachineHealthCheckClientOrDie(componentName),
 		recorder,
-	).Run(2, ctx.Stop)
+	)
+	return optr
+}
+
+// addHealthChecks adds the operator checks to the liveness and readiness checkers.
+// A stuck worker fails both probes, so the operator is restarted, while the
+// informers that are not synced yet only make the operator not ready.
+func addHealthChecks(liveness, readiness *health.Checker, optr *operator.Operator) {
+	workerStuck := func() error {
+		return optr.WorkerStuck(startOpts.workerStuckThreshold)
+	}
+	liveness.AddCheck("worker", workerStuck)
+	readiness.AddCheck("worker", workerStuck)
+	readiness.AddCheck("informers", optr.InformersSynced)
 }
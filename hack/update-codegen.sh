@@ -0,0 +1,41 @@
+#!/usr/bin/env bash
+
+set -e
+
+source $(dirname "$0")/common.sh
+
+# The generators are expected to be built from k8s.io/code-generator
+# (see the replace directive in go.mod) and to be available under CODEGEN_BIN_DIR.
+CODEGEN_BIN_DIR=${CODEGEN_BIN_DIR:-${GOPATH:-$HOME/go}/bin}
+
+PACKAGE=github.com/openshift/machine-health-check-operator
+APIS_PKG=${PACKAGE}/pkg/apis
+CLIENT_PKG=${PACKAGE}/pkg/client
+INPUT_DIRS=${APIS_PKG}/healthchecking/v1alpha1
+HEADER=${REPO_DIR}/hack/boilerplate.go.txt
+
+# The generators work in GOPATH mode, the repository has to be located under ${GOPATH}/src/${PACKAGE}
+${CODEGEN_BIN_DIR}/deepcopy-gen \
+    --input-dirs ${INPUT_DIRS} \
+    -O zz_generated.deepcopy \
+    --bounding-dirs ${APIS_PKG} \
+    --go-header-file ${HEADER}
+
+${CODEGEN_BIN_DIR}/client-gen \
+    --clientset-name versioned \
+    --input-base "" \
+    --input ${INPUT_DIRS} \
+    --output-package ${CLIENT_PKG}/clientset \
+    --go-header-file ${HEADER}
+
+${CODEGEN_BIN_DIR}/lister-gen \
+    --input-dirs ${INPUT_DIRS} \
+    --output-package ${CLIENT_PKG}/listers \
+    --go-header-file ${HEADER}
+
+${CODEGEN_BIN_DIR}/informer-gen \
+    --input-dirs ${INPUT_DIRS} \
+    --versioned-clientset-package ${CLIENT_PKG}/clientset/versioned \
+    --listers-package ${CLIENT_PKG}/listers \
+    --output-package ${CLIENT_PKG}/informers \
+    --go-header-file ${HEADER}
@@ -0,0 +1,138 @@
+package v1alpha1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// DefaultLogLevel contains the default verbosity of the machine health check controller
+	DefaultLogLevel = int32(3)
+	// DefaultReplicas contains the default number of the machine health check controller replicas
+	DefaultReplicas = int32(1)
+	// DefaultMaxUnhealthy contains the default maximum of the unhealthy machines selected by a machine health check
+	DefaultMaxUnhealthy = "100%"
+	// DefaultRemediationStrategy contains the default strategy used to remediate the unhealthy machines
+	DefaultRemediationStrategy = RemediationStrategyDelete
+	// DefaultDrainTimeout contains the default maximum duration the node of an unhealthy machine is drained
+	DefaultDrainTimeout = 5 * time.Minute
+	// DefaultRemediationMaxAttempts contains the default maximum number of the remediations within the retry window
+	DefaultRemediationMaxAttempts = int32(3)
+	// DefaultRemediationWindow contains the default duration the remediations are counted for
+	DefaultRemediationWindow = time.Hour
+	// DefaultRemediationBackoff contains the default minimal duration between two remediations
+	DefaultRemediationBackoff = time.Minute
+	// DefaultZoneTopologyKey contains the default node label whose value is the zone of a machine
+	DefaultZoneTopologyKey = corev1.LabelZoneFailureDomain
+	// DefaultZoneMaxConcurrent contains the default maximum number of the machines of a zone remediated at the same time
+	DefaultZoneMaxConcurrent = int32(1)
+	// DefaultZoneOutageThreshold contains the default share of the unhealthy machines from which a zone is considered unhealthy
+	DefaultZoneOutageThreshold = "100%"
+	// DefaultFlappingMaxTransitions contains the default number of the transitions from which a node is flapping
+	DefaultFlappingMaxTransitions = int32(4)
+	// DefaultFlappingWindow contains the default duration the transitions of the node conditions are counted for
+	DefaultFlappingWindow = 10 * time.Minute
+	// DefaultFlappingRecoveryPeriod contains the default duration a flapping node has to be stable before it is healthy again
+	DefaultFlappingRecoveryPeriod = 5 * time.Minute
+)
+
+// SetDefaultsMachineHealthCheckOperatorConfigSpec sets the default values for the unset fields of the spec.
+func SetDefaultsMachineHealthCheckOperatorConfigSpec(spec *MachineHealthCheckOperatorConfigSpec) {
+	if spec.ManagementState == "" {
+		spec.ManagementState = Managed
+	}
+	if spec.LogLevel == nil {
+		spec.LogLevel = pointer.Int32Ptr(DefaultLogLevel)
+	}
+	if spec.Replicas == nil {
+		spec.Replicas = pointer.Int32Ptr(DefaultReplicas)
+	}
+	if spec.Resources == nil {
+		spec.Resources = &corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceMemory: resource.MustParse("20Mi"),
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+			},
+		}
+	}
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = map[string]string{"node-role.kubernetes.io/master": ""}
+	}
+	if spec.Tolerations == nil {
+		spec.Tolerations = []corev1.Toleration{
+			{
+				Key:    "node-role.kubernetes.io/master",
+				Effect: corev1.TaintEffectNoSchedule,
+			},
+			{
+				Key:      "CriticalAddonsOnly",
+				Operator: corev1.TolerationOpExists,
+			},
+			{
+				Key:               "node.kubernetes.io/not-ready",
+				Effect:            corev1.TaintEffectNoExecute,
+				Operator:          corev1.TolerationOpExists,
+				TolerationSeconds: pointer.Int64Ptr(120),
+			},
+			{
+				Key:               "node.kubernetes.io/unreachable",
+				Effect:            corev1.TaintEffectNoExecute,
+				Operator:          corev1.TolerationOpExists,
+				TolerationSeconds: pointer.Int64Ptr(120),
+			},
+		}
+	}
+}
+
+// SetDefaultsMachineHealthCheckSpec sets the default values for the unset fields of the spec.
+func SetDefaultsMachineHealthCheckSpec(spec *MachineHealthCheckSpec) {
+	if spec.MaxUnhealthy == nil {
+		maxUnhealthy := intstr.FromString(DefaultMaxUnhealthy)
+		spec.MaxUnhealthy = &maxUnhealthy
+	}
+	if spec.RemediationStrategy == "" {
+		spec.RemediationStrategy = DefaultRemediationStrategy
+	}
+	if spec.DrainTimeout == nil {
+		spec.DrainTimeout = &metav1.Duration{Duration: DefaultDrainTimeout}
+	}
+	if retry := spec.RemediationRetry; retry != nil {
+		if retry.MaxAttempts == 0 {
+			retry.MaxAttempts = DefaultRemediationMaxAttempts
+		}
+		if retry.Window == nil {
+			retry.Window = &metav1.Duration{Duration: DefaultRemediationWindow}
+		}
+		if retry.Backoff == nil {
+			retry.Backoff = &metav1.Duration{Duration: DefaultRemediationBackoff}
+		}
+	}
+	if zones := spec.ZoneRemediation; zones != nil {
+		if zones.TopologyKey == "" {
+			zones.TopologyKey = DefaultZoneTopologyKey
+		}
+		if zones.MaxConcurrent == 0 {
+			zones.MaxConcurrent = DefaultZoneMaxConcurrent
+		}
+		if zones.OutageThreshold == nil {
+			outageThreshold := intstr.FromString(DefaultZoneOutageThreshold)
+			zones.OutageThreshold = &outageThreshold
+		}
+	}
+	if flapping := spec.Flapping; flapping != nil {
+		if flapping.MaxTransitions == 0 {
+			flapping.MaxTransitions = DefaultFlappingMaxTransitions
+		}
+		if flapping.Window == nil {
+			flapping.Window = &metav1.Duration{Duration: DefaultFlappingWindow}
+		}
+		if flapping.RecoveryPeriod == nil {
+			flapping.RecoveryPeriod = &metav1.Duration{Duration: DefaultFlappingRecoveryPeriod}
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package,register
+
+// +groupName=healthchecking.openshift.io
+// Package v1alpha1 is the v1alpha1 version of the machine health check API.
+package v1alpha1
@@ -0,0 +1,67 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineDisruptionBudget limits the number of the selected machines that can be
+// disrupted at the same time, it is honored by the machine health check remediation.
+// +kubebuilder:subresource:status
+type MachineDisruptionBudget struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec holds the machine disruption budget policy
+	Spec MachineDisruptionBudgetSpec `json:"spec,omitempty"`
+	// status holds observed values from the cluster. They may not be overridden.
+	Status MachineDisruptionBudgetStatus `json:"status,omitempty"`
+}
+
+// MachineDisruptionBudgetSpec defines the machines protected by the budget and how many of them have to stay healthy.
+// Exactly one of minAvailable and maxUnavailable has to be set.
+type MachineDisruptionBudgetSpec struct {
+	// selector is a label selector matching the machines protected by the budget.
+	// An empty selector matches all the machines in the namespace.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// minAvailable is the number, or percentage, of the selected machines that have to stay
+	// healthy after a disruption. Percentages are rounded up.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// maxUnavailable is the number, or percentage, of the selected machines that can be
+	// unhealthy after a disruption. Percentages are rounded up.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// MachineDisruptionBudgetStatus defines the observed state of the machine disruption budget
+type MachineDisruptionBudgetStatus struct {
+	// observedGeneration is the latest generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// currentHealthy is the number of the selected machines that are healthy.
+	CurrentHealthy int32 `json:"currentHealthy"`
+
+	// desiredHealthy is the minimal number of the selected machines that have to stay healthy.
+	DesiredHealthy int32 `json:"desiredHealthy"`
+
+	// expectedMachines is the total number of the machines selected by the budget.
+	ExpectedMachines int32 `json:"expectedMachines"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineDisruptionBudgetList contains a list of MachineDisruptionBudget
+type MachineDisruptionBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineDisruptionBudget `json:"items"`
+}
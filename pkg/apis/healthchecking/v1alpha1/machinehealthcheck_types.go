@@ -0,0 +1,356 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// MachineHealthCheckConditionType is a valid value for MachineHealthCheckCondition.Type
+type MachineHealthCheckConditionType string
+
+const (
+	// RemediationAllowed indicates whether the unhealthy machines are remediated, it is false
+	// while more machines than allowed by maxUnhealthy are unhealthy
+	RemediationAllowed MachineHealthCheckConditionType = "RemediationAllowed"
+	// RemediationAttemptsExceeded indicates whether a machine, or the machines of a machine set, were
+	// remediated more times than allowed by the remediation retry policy, they need human attention
+	RemediationAttemptsExceeded MachineHealthCheckConditionType = "RemediationAttemptsExceeded"
+	// SpecValid indicates whether the spec is valid, it is false with the validation errors while the
+	// machine health check is ignored because of an invalid spec
+	SpecValid MachineHealthCheckConditionType = "SpecValid"
+)
+
+// RemediationStrategyType is the strategy used to remediate the unhealthy machines
+type RemediationStrategyType string
+
+const (
+	// RemediationStrategyDelete deletes the unhealthy machine, so it is replaced by its machine set
+	RemediationStrategyDelete RemediationStrategyType = "Delete"
+	// RemediationStrategyReboot requests a reboot of the unhealthy machine with the
+	// RebootAnnotation, the reboot is performed by the machine provider
+	RemediationStrategyReboot RemediationStrategyType = "Reboot"
+	// RemediationStrategyExternal creates a remediation object for the unhealthy machine from
+	// the remediation template and waits until the remediation completes
+	RemediationStrategyExternal RemediationStrategyType = "External"
+)
+
+const (
+	// RebootAnnotation is set on the machines remediated by the Reboot strategy, the machine
+	// provider reboots the machine and removes the annotation once the reboot completes
+	RebootAnnotation = "healthchecking.openshift.io/machine-remediation-reboot"
+	// ExternalRemediationAnnotation is set on the machines remediated by the External strategy
+	// to the time the remediation object was created, it is removed once the remediation completes
+	ExternalRemediationAnnotation = "healthchecking.openshift.io/external-remediation"
+	// DrainStartedAnnotation is set on the unhealthy machines to the time the drain of their node
+	// started, it is removed once the node is uncordoned after the machine recovered
+	DrainStartedAnnotation = "healthchecking.openshift.io/drain-started"
+	// ExternalRemediationSucceeded is the type of the remediation object status condition that
+	// reports the completed remediation, the remediation failed when its status is False
+	ExternalRemediationSucceeded = "Succeeded"
+	// RemediationRoleAggregationLabel is set to "true" on the cluster roles granting access to the
+	// remediation templates and to the remediation objects of the External strategy, the rules of
+	// these cluster roles are aggregated into the cluster role bound to the controller
+	RemediationRoleAggregationLabel = "healthchecking.openshift.io/aggregate-to-machine-health-check-controller"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineHealthCheck watches the nodes of the selected machines and remediates
+// the machines whose nodes stay unhealthy for longer than the configured timeouts.
+// +kubebuilder:subresource:status
+type MachineHealthCheck struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec holds the machine health check policy. The spec is validated on admission by the webhook
+	// served by the machine health check controller. The machine health checks are admitted while the
+	// controller is not available, an invalid spec is then ignored by the controller and its validation
+	// errors are reported by the SpecValid condition and the InvalidSpec event.
+	Spec MachineHealthCheckSpec `json:"spec,omitempty"`
+	// status holds observed values from the cluster. They may not be overridden.
+	Status MachineHealthCheckStatus `json:"status,omitempty"`
+}
+
+// MachineHealthCheckSpec defines the machines checked by the machine health check and when they are unhealthy
+type MachineHealthCheckSpec struct {
+	// selector is a label selector matching the machines to be checked.
+	// An empty selector matches all the machines in the namespace.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// unhealthyConditions contains a list of the node conditions that determine whether
+	// a node is considered unhealthy. The conditions are combined in a logical OR. At least
+	// one unhealthy condition, unhealthy expression or required pod is required.
+	// +optional
+	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions,omitempty"`
+
+	// unhealthyExpressions contains a list of the boolean expressions evaluated against the
+	// conditions, labels and annotations of a node and the phase of its machine. The node is
+	// considered unhealthy once one of them is true, they are combined with the unhealthy
+	// conditions in a logical OR. The machines without a node are not evaluated. The syntax errors
+	// of the expressions are reported on admission, or by the SpecValid condition when the machine
+	// health check was admitted without the validation.
+	// +optional
+	UnhealthyExpressions []UnhealthyExpression `json:"unhealthyExpressions,omitempty"`
+
+	// requiredPods contains a list of the pods that have to be ready on every node, for example
+	// the pods of the network or storage daemon sets. The node is considered unhealthy once one of
+	// them is not ready, or missing on the node, for longer than its timeout. They are combined with
+	// the unhealthy conditions in a logical OR.
+	// +optional
+	RequiredPods []RequiredPod `json:"requiredPods,omitempty"`
+
+	// maxUnhealthy is the maximum number, or percentage, of the selected machines that can be
+	// unhealthy at the same time. Once more machines are unhealthy, the remediation is paused
+	// until enough of them recover. Defaults to 100%.
+	// +optional
+	MaxUnhealthy *intstr.IntOrString `json:"maxUnhealthy,omitempty"`
+
+	// nodeStartupTimeout is the duration a machine can exist without a node before it is
+	// considered unhealthy. The machines without a node are not checked when it is not set.
+	// +optional
+	NodeStartupTimeout *metav1.Duration `json:"nodeStartupTimeout,omitempty"`
+
+	// remediationStrategy is the strategy used to remediate the unhealthy machines, one of
+	// Delete, Reboot, External. Defaults to Delete.
+	// +kubebuilder:validation:Enum=Delete,Reboot,External
+	// +optional
+	RemediationStrategy RemediationStrategyType `json:"remediationStrategy,omitempty"`
+
+	// remediationTemplate references the remediation template used by the External strategy, the
+	// template has to be in the namespace of the machine health check. For an unhealthy machine,
+	// an object of the template kind without the Template suffix is created with the machine name
+	// and the spec of the template spec.template, the machine is remediated by its controller.
+	// The remediation completes once the object has the Succeeded status condition. The controller
+	// has to be granted get on the template and get, create and delete on the remediation objects
+	// by a cluster role labeled with RemediationRoleAggregationLabel set to "true".
+	// +optional
+	RemediationTemplate *corev1.ObjectReference `json:"remediationTemplate,omitempty"`
+
+	// dryRun enables the dry-run mode, the unhealthy machines that would have been remediated
+	// are reported in the events, metrics and status, but they are not remediated.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// remediateControlPlane enables the remediation of the selected control plane machines. They
+	// are remediated one at a time and only when the remaining etcd members keep the quorum.
+	// It requires the Reboot or External remediation strategy, since the control plane machines
+	// are not owned by a machine set replacing them once deleted. The control plane machines are
+	// not remediated by default.
+	// +optional
+	RemediateControlPlane bool `json:"remediateControlPlane,omitempty"`
+
+	// drainTimeout is the maximum duration the node of an unhealthy machine is drained before the
+	// machine is deleted or rebooted. The node is cordoned and its pods are evicted honoring the pod
+	// disruption budgets, once the timeout expires the remaining pods are deleted. Defaults to 5m,
+	// 0 disables the drain. The node is not drained by the External strategy.
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
+
+	// remediationRetry limits the repeated remediations of a machine, or of the replacements of the
+	// machines in the same machine set. The remediations are not limited when it is not set.
+	// +optional
+	RemediationRetry *RemediationRetryPolicy `json:"remediationRetry,omitempty"`
+
+	// zoneRemediation limits the remediations per zone, the failure domain of the machines given by
+	// a label of their nodes. The remediations are not limited per zone when it is not set.
+	// +optional
+	ZoneRemediation *ZoneRemediationPolicy `json:"zoneRemediation,omitempty"`
+
+	// flapping considers the nodes whose unhealthy conditions change too often unhealthy, even when
+	// none of the conditions lasts for its timeout. The flapping nodes are not detected when it is
+	// not set.
+	// +optional
+	Flapping *FlappingPolicy `json:"flapping,omitempty"`
+}
+
+// FlappingPolicy detects the nodes flapping between the healthy and the unhealthy state. The
+// transitions of the node conditions are tracked by the controller since it started.
+type FlappingPolicy struct {
+	// maxTransitions is the number of the transitions of a node condition type of the unhealthy
+	// conditions within the window from which the node is flapping. Defaults to 4.
+	// +kubebuilder:validation:Minimum=2
+	// +optional
+	MaxTransitions int32 `json:"maxTransitions,omitempty"`
+
+	// window is the duration the transitions are counted for. Defaults to 10m.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// recoveryPeriod is the duration a flapping node has to be without any transition of the
+	// condition before it is considered healthy again. Defaults to 5m.
+	// +optional
+	RecoveryPeriod *metav1.Duration `json:"recoveryPeriod,omitempty"`
+}
+
+// ZoneRemediationPolicy limits the remediations of the machines per zone. The remediation is suspended
+// in a zone whose machines are mostly unhealthy, for example because of a zone outage, since their
+// replacements would be created in the same zone. The machines whose node does not have the topology
+// label, or that do not have a node, are not limited per zone.
+type ZoneRemediationPolicy struct {
+	// topologyKey is the node label whose value is the zone of a machine. Defaults to
+	// failure-domain.beta.kubernetes.io/zone.
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+
+	// maxConcurrent is the maximum number of the machines of a zone being remediated at the same
+	// time. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrent int32 `json:"maxConcurrent,omitempty"`
+
+	// outageThreshold is the number or the percentage of the unhealthy machines of a zone from which
+	// the whole zone is considered unhealthy and the remediation is suspended in the zone until enough
+	// machines recover. A zone with a single machine is never considered unhealthy as a whole.
+	// Defaults to 100%.
+	// +optional
+	OutageThreshold *intstr.IntOrString `json:"outageThreshold,omitempty"`
+}
+
+// RemediationRetryPolicy limits the repeated remediations of the machines. The remediations of the
+// machines owned by the same machine set are counted together, since the remediated machines are
+// replaced by new ones.
+type RemediationRetryPolicy struct {
+	// maxAttempts is the maximum number of the remediations within the window. Once reached, the
+	// machines are not remediated and the RemediationAttemptsExceeded condition is set until the
+	// remediations fall out of the window. At most 100, defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// window is the duration the remediations are counted for. Defaults to 1h.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// backoff is the minimal duration between two remediations, it doubles with every remediation
+	// within the window up to the window. Defaults to 1m.
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+}
+
+// UnhealthyCondition represents a node condition type and value with a timeout,
+// the node is considered unhealthy once it has the condition for longer than the timeout
+type UnhealthyCondition struct {
+	// type of the node condition, for example Ready.
+	Type corev1.NodeConditionType `json:"type"`
+	// status of the node condition, one of True, False, Unknown.
+	// +kubebuilder:validation:Enum=True,False,Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// timeout is the duration the node has to have the condition before it is considered unhealthy.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// UnhealthyExpression is a named boolean expression, the node is considered unhealthy once it is true.
+// The expression compares the operands with ==, != and, for the durations, with <, <=, >, >=, and it
+// combines the comparisons with &&, || and !. The operands are the "quoted" strings, the durations
+// such as 5m, true, false and the functions condition("Type"), conditionAge("Type"), label("key"),
+// hasLabel("key"), annotation("key"), hasAnnotation("key") and machinePhase(). For example:
+// condition("Ready") == "Unknown" && conditionAge("Ready") >= 5m && !hasLabel("example.com/maintenance")
+type UnhealthyExpression struct {
+	// name identifies the expression in the events.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// expression is the boolean expression.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	Expression string `json:"expression"`
+}
+
+// RequiredPod represents the pods that have to be ready on every node with a timeout. The node is
+// healthy while one of the pods matching the selector on the node is ready.
+type RequiredPod struct {
+	// name identifies the required pods in the events.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// namespace of the pods.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+	// selector is a label selector matching the pods.
+	Selector metav1.LabelSelector `json:"selector"`
+	// timeout is the duration the pods can be not ready before the node is considered unhealthy. The
+	// duration is measured since the node was created when none of the pods is scheduled on the node.
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// MachineHealthCheckStatus defines the observed state of the machine health check
+type MachineHealthCheckStatus struct {
+	// observedGeneration is the latest generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// expectedMachines is the total number of the machines selected by the machine health check.
+	// +optional
+	ExpectedMachines *int32 `json:"expectedMachines,omitempty"`
+
+	// currentHealthy is the total number of the selected machines that are healthy.
+	// +optional
+	CurrentHealthy *int32 `json:"currentHealthy,omitempty"`
+
+	// conditions describe the state of the machine health check.
+	// +optional
+	Conditions []MachineHealthCheckCondition `json:"conditions,omitempty"`
+
+	// dryRunRemediations contains the machines that would have been remediated during the
+	// last check when the machine health check is in the dry-run mode.
+	// +optional
+	DryRunRemediations []DryRunRemediation `json:"dryRunRemediations,omitempty"`
+
+	// remediationHistory contains the remediations within the window of the remediation retry policy.
+	// +optional
+	RemediationHistory []RemediationRecord `json:"remediationHistory,omitempty"`
+
+	// suspendedZones contains the zones considered unhealthy as a whole, the remediation is
+	// suspended in them.
+	// +optional
+	SuspendedZones []string `json:"suspendedZones,omitempty"`
+}
+
+// RemediationRecord describes a remediation of an unhealthy machine.
+type RemediationRecord struct {
+	// machineName is the name of the remediated machine.
+	MachineName string `json:"machineName"`
+	// machineSetName is the name of the machine set owning the remediated machine, if any.
+	// +optional
+	MachineSetName string `json:"machineSetName,omitempty"`
+	// reason is the CamelCase reason the machine was unhealthy for.
+	Reason string `json:"reason"`
+	// time is the time the remediation started.
+	Time metav1.Time `json:"time"`
+}
+
+// DryRunRemediation describes an unhealthy machine that would have been remediated in the dry-run mode.
+type DryRunRemediation struct {
+	// machineName is the name of the unhealthy machine.
+	MachineName string `json:"machineName"`
+	// reason is the CamelCase reason the machine is unhealthy for.
+	Reason string `json:"reason"`
+	// message is a human readable message describing why the machine is unhealthy.
+	Message string `json:"message,omitempty"`
+}
+
+// MachineHealthCheckCondition describes the state of the machine health check at a certain point.
+type MachineHealthCheckCondition struct {
+	// type of the condition.
+	Type MachineHealthCheckConditionType `json:"type"`
+	// status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// lastTransitionTime is the last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// reason is the CamelCase reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// message is a human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineHealthCheckList contains a list of MachineHealthCheck
+type MachineHealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineHealthCheck `json:"items"`
+}
@@ -0,0 +1,128 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ManagementState indicates whether and how the operator should manage its operands
+type ManagementState string
+
+const (
+	// Managed means that the operator is actively managing its operands
+	Managed ManagementState = "Managed"
+	// Unmanaged means that the operator will not take any action related to its operands
+	Unmanaged ManagementState = "Unmanaged"
+	// Removed means that the operator is actively removing its operands
+	Removed ManagementState = "Removed"
+)
+
+// OperatorConfigConditionType is a valid value for MachineHealthCheckOperatorConfigCondition.Type
+type OperatorConfigConditionType string
+
+const (
+	// OperatorConfigValid indicates whether the operator configuration passed the validation
+	OperatorConfigValid OperatorConfigConditionType = "Valid"
+	// OperatorConfigAvailable indicates whether the configuration was applied and the operands are available
+	OperatorConfigAvailable OperatorConfigConditionType = "Available"
+	// OperatorConfigProgressing indicates whether the operands are being rolled out
+	OperatorConfigProgressing OperatorConfigConditionType = "Progressing"
+	// OperatorConfigDegraded indicates whether the operator failed to apply the configuration
+	OperatorConfigDegraded OperatorConfigConditionType = "Degraded"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineHealthCheckOperatorConfig holds cluster-wide configuration of the machine health check operator.
+// The canonical name is `cluster`
+// +kubebuilder:subresource:status
+type MachineHealthCheckOperatorConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// spec holds user settable values for configuration
+	Spec MachineHealthCheckOperatorConfigSpec `json:"spec,omitempty"`
+	// status holds observed values from the cluster. They may not be overridden.
+	Status MachineHealthCheckOperatorConfigStatus `json:"status,omitempty"`
+}
+
+// MachineHealthCheckOperatorConfigSpec defines the desired state of the machine health check operator
+type MachineHealthCheckOperatorConfigSpec struct {
+	// managementState indicates whether and how the operator should manage the machine health check controller.
+	// Defaults to Managed.
+	// +kubebuilder:validation:Enum=Managed,Unmanaged,Removed
+	// +optional
+	ManagementState ManagementState `json:"managementState,omitempty"`
+
+	// logLevel is the verbosity of the machine health check controller logs. Defaults to 3.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	LogLevel *int32 `json:"logLevel,omitempty"`
+
+	// replicas is the number of machine health check controller replicas. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// resources are the compute resources required by the machine health check controller container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// nodeSelector is the node selector applied to the machine health check controller pods.
+	// Defaults to the master nodes.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// tolerations are the tolerations applied to the machine health check controller pods.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// extraArgs are additional command line arguments passed to the machine health check controller.
+	// Arguments managed by the operator can not be overridden.
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// dryRun enables the dry-run mode of the machine health check controller for all the machine
+	// health checks, the unhealthy machines are reported, but they are not remediated.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// MachineHealthCheckOperatorConfigStatus defines the observed state of the machine health check operator
+type MachineHealthCheckOperatorConfigStatus struct {
+	// observedGeneration is the latest generation observed by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// conditions describe the state of the operator configuration.
+	// +optional
+	Conditions []MachineHealthCheckOperatorConfigCondition `json:"conditions,omitempty"`
+}
+
+// MachineHealthCheckOperatorConfigCondition describes the state of the operator configuration at a certain point.
+type MachineHealthCheckOperatorConfigCondition struct {
+	// type of the condition.
+	Type OperatorConfigConditionType `json:"type"`
+	// status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// lastTransitionTime is the last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// reason is the CamelCase reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// message is a human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineHealthCheckOperatorConfigList contains a list of MachineHealthCheckOperatorConfig
+type MachineHealthCheckOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineHealthCheckOperatorConfig `json:"items"`
+}
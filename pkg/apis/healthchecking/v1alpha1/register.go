@@ -0,0 +1,41 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupName contains the name of the API group
+	GroupName = "healthchecking.openshift.io"
+	// GroupVersion contains the group version used to register these objects
+	GroupVersion  = schema.GroupVersion{Group: GroupName, Version: "v1alpha1"}
+	schemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	// Install is a function which adds this version to a scheme
+	Install = schemeBuilder.AddToScheme
+
+	// SchemeGroupVersion generated code relies on this name
+	SchemeGroupVersion = GroupVersion
+	// AddToScheme exists solely to keep the generators creating valid code
+	AddToScheme = schemeBuilder.AddToScheme
+)
+
+// Resource generated code relies on this being here, but it logically belongs to the group
+func Resource(resource string) schema.GroupResource {
+	return schema.GroupResource{Group: GroupName, Resource: resource}
+}
+
+// Adds the list of known types to api.Scheme.
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(GroupVersion,
+		&MachineHealthCheckOperatorConfig{},
+		&MachineHealthCheckOperatorConfigList{},
+		&MachineHealthCheck{},
+		&MachineHealthCheckList{},
+		&MachineDisruptionBudget{},
+		&MachineDisruptionBudgetList{},
+	)
+	metav1.AddToGroupVersion(scheme, GroupVersion)
+	return nil
+}
@@ -0,0 +1,278 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/machine-health-check-operator/pkg/expression"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// reservedArgs contains the machine health check controller arguments managed by the operator
+var reservedArgs = []string{"--logtostderr", "--v", "-v", "--dry-run", "--webhook-addr", "--webhook-cert-dir"}
+
+// maxRemediationAttempts is the maximum of the remediation attempts of the retry policy, every
+// attempt is kept in the remediation history of the status
+const maxRemediationAttempts = 100
+
+// ValidateMachineHealthCheckOperatorConfigSpec validates the operator configuration spec.
+func ValidateMachineHealthCheckOperatorConfigSpec(spec *MachineHealthCheckOperatorConfigSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch spec.ManagementState {
+	case "", Managed, Unmanaged, Removed:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("managementState"), spec.ManagementState, []string{string(Managed), string(Unmanaged), string(Removed)}))
+	}
+
+	if spec.LogLevel != nil && (*spec.LogLevel < 0 || *spec.LogLevel > 10) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("logLevel"), *spec.LogLevel, "must be between 0 and 10"))
+	}
+
+	if spec.Replicas != nil && *spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *spec.Replicas, "must be greater than or equal to 0"))
+	}
+
+	if spec.Resources != nil {
+		for name, request := range spec.Resources.Requests {
+			limit, ok := spec.Resources.Limits[name]
+			if ok && request.Cmp(limit) > 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("resources", "requests").Key(string(name)), request.String(), fmt.Sprintf("must be less than or equal to %s limit", name)))
+			}
+		}
+	}
+
+	for i, toleration := range spec.Tolerations {
+		if toleration.Operator == corev1.TolerationOpExists && toleration.Value != "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("tolerations").Index(i).Child("value"), toleration.Value, "must be empty when operator is Exists"))
+		}
+	}
+
+	for i, arg := range spec.ExtraArgs {
+		if !strings.HasPrefix(arg, "-") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("extraArgs").Index(i), arg, "must be a flag starting with '-'"))
+			continue
+		}
+		name := strings.SplitN(arg, "=", 2)[0]
+		for _, reserved := range reservedArgs {
+			if name == reserved {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("extraArgs").Index(i), fmt.Sprintf("%s is managed by the operator", reserved)))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateMachineHealthCheckSpec validates the machine health check spec.
+func ValidateMachineHealthCheckSpec(spec *MachineHealthCheckSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if _, err := metav1.LabelSelectorAsSelector(&spec.Selector); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("selector"), spec.Selector, err.Error()))
+	}
+
+	if len(spec.UnhealthyConditions) == 0 && len(spec.UnhealthyExpressions) == 0 && len(spec.RequiredPods) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("unhealthyConditions"), "at least one unhealthy condition, unhealthy expression or required pod is required"))
+	}
+	for i, condition := range spec.UnhealthyConditions {
+		conditionPath := fldPath.Child("unhealthyConditions").Index(i)
+		if condition.Type == "" {
+			allErrs = append(allErrs, field.Required(conditionPath.Child("type"), ""))
+		}
+		switch condition.Status {
+		case corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown:
+		default:
+			allErrs = append(allErrs, field.NotSupported(conditionPath.Child("status"), condition.Status, []string{string(corev1.ConditionTrue), string(corev1.ConditionFalse), string(corev1.ConditionUnknown)}))
+		}
+		if condition.Timeout.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(conditionPath.Child("timeout"), condition.Timeout.Duration.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	names := map[string]bool{}
+	for i, e := range spec.UnhealthyExpressions {
+		expressionPath := fldPath.Child("unhealthyExpressions").Index(i)
+		switch {
+		case e.Name == "":
+			allErrs = append(allErrs, field.Required(expressionPath.Child("name"), ""))
+		case names[e.Name]:
+			allErrs = append(allErrs, field.Duplicate(expressionPath.Child("name"), e.Name))
+		}
+		names[e.Name] = true
+		if _, err := expression.Parse(e.Expression); err != nil {
+			allErrs = append(allErrs, field.Invalid(expressionPath.Child("expression"), e.Expression, err.Error()))
+		}
+	}
+
+	podNames := map[string]bool{}
+	for i, pod := range spec.RequiredPods {
+		podPath := fldPath.Child("requiredPods").Index(i)
+		switch {
+		case pod.Name == "":
+			allErrs = append(allErrs, field.Required(podPath.Child("name"), ""))
+		case podNames[pod.Name]:
+			allErrs = append(allErrs, field.Duplicate(podPath.Child("name"), pod.Name))
+		}
+		podNames[pod.Name] = true
+		if pod.Namespace == "" {
+			allErrs = append(allErrs, field.Required(podPath.Child("namespace"), ""))
+		}
+		if _, err := metav1.LabelSelectorAsSelector(&pod.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(podPath.Child("selector"), pod.Selector, err.Error()))
+		}
+		if pod.Timeout.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(podPath.Child("timeout"), pod.Timeout.Duration.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	if spec.MaxUnhealthy != nil {
+		allErrs = append(allErrs, validateIntOrPercent(spec.MaxUnhealthy, fldPath.Child("maxUnhealthy"))...)
+	}
+
+	if spec.NodeStartupTimeout != nil && spec.NodeStartupTimeout.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("nodeStartupTimeout"), spec.NodeStartupTimeout.Duration.String(), "must be greater than or equal to 0"))
+	}
+
+	if spec.DrainTimeout != nil && spec.DrainTimeout.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("drainTimeout"), spec.DrainTimeout.Duration.String(), "must be greater than or equal to 0"))
+	}
+
+	if retry := spec.RemediationRetry; retry != nil {
+		retryPath := fldPath.Child("remediationRetry")
+		if retry.MaxAttempts < 0 {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("maxAttempts"), retry.MaxAttempts, "must be greater than 0"))
+		}
+		if retry.MaxAttempts > maxRemediationAttempts {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("maxAttempts"), retry.MaxAttempts, fmt.Sprintf("must be less than or equal to %d", maxRemediationAttempts)))
+		}
+		if retry.Window != nil && retry.Window.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("window"), retry.Window.Duration.String(), "must be greater than 0"))
+		}
+		if retry.Backoff != nil && retry.Backoff.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(retryPath.Child("backoff"), retry.Backoff.Duration.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	if zones := spec.ZoneRemediation; zones != nil {
+		zonesPath := fldPath.Child("zoneRemediation")
+		if zones.TopologyKey != "" {
+			for _, msg := range validation.IsQualifiedName(zones.TopologyKey) {
+				allErrs = append(allErrs, field.Invalid(zonesPath.Child("topologyKey"), zones.TopologyKey, msg))
+			}
+		}
+		if zones.MaxConcurrent < 0 {
+			allErrs = append(allErrs, field.Invalid(zonesPath.Child("maxConcurrent"), zones.MaxConcurrent, "must be greater than 0"))
+		}
+		if zones.OutageThreshold != nil {
+			allErrs = append(allErrs, validateIntOrPercent(zones.OutageThreshold, zonesPath.Child("outageThreshold"))...)
+		}
+	}
+
+	if flapping := spec.Flapping; flapping != nil {
+		flappingPath := fldPath.Child("flapping")
+		if flapping.MaxTransitions < 0 || flapping.MaxTransitions == 1 {
+			allErrs = append(allErrs, field.Invalid(flappingPath.Child("maxTransitions"), flapping.MaxTransitions, "must be greater than 1"))
+		}
+		if flapping.Window != nil && flapping.Window.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(flappingPath.Child("window"), flapping.Window.Duration.String(), "must be greater than 0"))
+		}
+		if flapping.RecoveryPeriod != nil && flapping.RecoveryPeriod.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(flappingPath.Child("recoveryPeriod"), flapping.RecoveryPeriod.Duration.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	switch spec.RemediationStrategy {
+	case "", RemediationStrategyDelete, RemediationStrategyReboot:
+		if spec.RemediationTemplate != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("remediationTemplate"), "may only be set with the External remediation strategy"))
+		}
+	case RemediationStrategyExternal:
+		allErrs = append(allErrs, validateRemediationTemplate(spec.RemediationTemplate, fldPath.Child("remediationTemplate"))...)
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("remediationStrategy"), spec.RemediationStrategy, []string{string(RemediationStrategyDelete), string(RemediationStrategyReboot), string(RemediationStrategyExternal)}))
+	}
+
+	// the control plane machines are not owned by a machine set, so they are not replaced once deleted
+	if spec.RemediateControlPlane {
+		switch spec.RemediationStrategy {
+		case "", RemediationStrategyDelete:
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("remediateControlPlane"), "may only be set with the Reboot or External remediation strategy"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateMachineDisruptionBudgetSpec validates the machine disruption budget spec.
+func ValidateMachineDisruptionBudgetSpec(spec *MachineDisruptionBudgetSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if _, err := metav1.LabelSelectorAsSelector(&spec.Selector); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("selector"), spec.Selector, err.Error()))
+	}
+
+	switch {
+	case spec.MinAvailable == nil && spec.MaxUnavailable == nil:
+		allErrs = append(allErrs, field.Required(fldPath, "one of minAvailable or maxUnavailable is required"))
+	case spec.MinAvailable != nil && spec.MaxUnavailable != nil:
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), spec.MaxUnavailable.String(), "minAvailable and maxUnavailable can not be set at the same time"))
+	case spec.MinAvailable != nil:
+		allErrs = append(allErrs, validateIntOrPercent(spec.MinAvailable, fldPath.Child("minAvailable"))...)
+	default:
+		allErrs = append(allErrs, validateIntOrPercent(spec.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
+	}
+
+	return allErrs
+}
+
+// validateRemediationTemplate validates the reference of the remediation template used by the External strategy
+func validateRemediationTemplate(template *corev1.ObjectReference, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if template == nil {
+		return append(allErrs, field.Required(fldPath, "the External remediation strategy requires a remediation template"))
+	}
+	if template.APIVersion == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("apiVersion"), ""))
+	} else if _, err := schema.ParseGroupVersion(template.APIVersion); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("apiVersion"), template.APIVersion, err.Error()))
+	}
+	if template.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), ""))
+	} else if !strings.HasSuffix(template.Kind, "Template") || template.Kind == "Template" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("kind"), template.Kind, "must be the kind of a remediation template ending with 'Template'"))
+	}
+	if template.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+	if template.Namespace != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("namespace"), "the template has to be in the namespace of the machine health check"))
+	}
+	return allErrs
+}
+
+// validateIntOrPercent validates that the value is a non-negative integer or a percentage between 0% and 100%
+func validateIntOrPercent(value *intstr.IntOrString, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch value.Type {
+	case intstr.Int:
+		if value.IntVal < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath, value.IntVal, "must be greater than or equal to 0"))
+		}
+	case intstr.String:
+		percent, err := strconv.Atoi(strings.TrimSuffix(value.StrVal, "%"))
+		if err != nil || !strings.HasSuffix(value.StrVal, "%") {
+			allErrs = append(allErrs, field.Invalid(fldPath, value.StrVal, "must be an integer or a percentage, e.g. '10%'"))
+		} else if percent < 0 || percent > 100 {
+			allErrs = append(allErrs, field.Invalid(fldPath, value.StrVal, "must be between 0% and 100%"))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,334 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateMachineHealthCheckOperatorConfigSpec(t *testing.T) {
+	tests := []struct {
+		name           string
+		spec           MachineHealthCheckOperatorConfigSpec
+		expectedErrors int
+	}{{
+		name:           "empty spec",
+		spec:           MachineHealthCheckOperatorConfigSpec{},
+		expectedErrors: 0,
+	}, {
+		name: "valid spec",
+		spec: MachineHealthCheckOperatorConfigSpec{
+			ManagementState: Unmanaged,
+			LogLevel:        pointer.Int32Ptr(4),
+			Replicas:        pointer.Int32Ptr(0),
+			ExtraArgs:       []string{"--leader-elect=true"},
+		},
+		expectedErrors: 0,
+	}, {
+		name:           "unknown management state",
+		spec:           MachineHealthCheckOperatorConfigSpec{ManagementState: "Ignored"},
+		expectedErrors: 1,
+	}, {
+		name:           "log level out of range",
+		spec:           MachineHealthCheckOperatorConfigSpec{LogLevel: pointer.Int32Ptr(11)},
+		expectedErrors: 1,
+	}, {
+		name:           "negative replicas",
+		spec:           MachineHealthCheckOperatorConfigSpec{Replicas: pointer.Int32Ptr(-1)},
+		expectedErrors: 1,
+	}, {
+		name: "requests greater than limits",
+		spec: MachineHealthCheckOperatorConfigSpec{Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("20Mi")},
+		}},
+		expectedErrors: 1,
+	}, {
+		name:           "reserved and malformed extra args",
+		spec:           MachineHealthCheckOperatorConfigSpec{ExtraArgs: []string{"--v=10", "--logtostderr=false", "namespace"}},
+		expectedErrors: 3,
+	}}
+
+	for _, tc := range tests {
+		errs := ValidateMachineHealthCheckOperatorConfigSpec(&tc.spec, field.NewPath("spec"))
+		if len(errs) != tc.expectedErrors {
+			t.Errorf("%s: expected %d errors, got %d: %v", tc.name, tc.expectedErrors, len(errs), errs)
+		}
+	}
+}
+
+func TestSetDefaultsMachineHealthCheckOperatorConfigSpec(t *testing.T) {
+	spec := MachineHealthCheckOperatorConfigSpec{Replicas: pointer.Int32Ptr(0)}
+	SetDefaultsMachineHealthCheckOperatorConfigSpec(&spec)
+
+	if spec.ManagementState != Managed {
+		t.Errorf("Expected management state %q, got %q", Managed, spec.ManagementState)
+	}
+	if *spec.Replicas != 0 {
+		t.Errorf("Expected replicas to keep the user value 0, got %d", *spec.Replicas)
+	}
+	if *spec.LogLevel != DefaultLogLevel {
+		t.Errorf("Expected log level %d, got %d", DefaultLogLevel, *spec.LogLevel)
+	}
+	if spec.Resources == nil || len(spec.NodeSelector) == 0 || len(spec.Tolerations) == 0 {
+		t.Errorf("Expected resources, node selector and tolerations to be defaulted, got %+v", spec)
+	}
+}
+
+func TestValidateMachineHealthCheckSpec(t *testing.T) {
+	readyTimeout := []UnhealthyCondition{{
+		Type:    corev1.NodeReady,
+		Status:  corev1.ConditionUnknown,
+		Timeout: metav1.Duration{Duration: 5 * time.Minute},
+	}}
+	intOrString := func(value intstr.IntOrString) *intstr.IntOrString {
+		return &value
+	}
+
+	tests := []struct {
+		name           string
+		spec           MachineHealthCheckSpec
+		expectedErrors int
+	}{{
+		name: "valid spec",
+		spec: MachineHealthCheckSpec{
+			Selector:            metav1.LabelSelector{MatchLabels: map[string]string{"machine.openshift.io/cluster-api-machine-role": "worker"}},
+			UnhealthyConditions: readyTimeout,
+			MaxUnhealthy:        intOrString(intstr.FromString("40%")),
+		},
+		expectedErrors: 0,
+	}, {
+		name:           "missing unhealthy conditions",
+		spec:           MachineHealthCheckSpec{},
+		expectedErrors: 1,
+	}, {
+		name: "invalid unhealthy condition",
+		spec: MachineHealthCheckSpec{UnhealthyConditions: []UnhealthyCondition{{
+			Status:  "Maybe",
+			Timeout: metav1.Duration{Duration: -time.Second},
+		}}},
+		expectedErrors: 3,
+	}, {
+		name: "invalid selector",
+		spec: MachineHealthCheckSpec{
+			Selector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "role",
+				Operator: "Matches",
+			}}},
+			UnhealthyConditions: readyTimeout,
+		},
+		expectedErrors: 1,
+	}, {
+		name:           "negative max unhealthy",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, MaxUnhealthy: intOrString(intstr.FromInt(-1))},
+		expectedErrors: 1,
+	}, {
+		name:           "max unhealthy percentage out of range",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, MaxUnhealthy: intOrString(intstr.FromString("120%"))},
+		expectedErrors: 1,
+	}, {
+		name:           "malformed max unhealthy",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, MaxUnhealthy: intOrString(intstr.FromString("half"))},
+		expectedErrors: 1,
+	}, {
+		name:           "negative node startup timeout",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, NodeStartupTimeout: &metav1.Duration{Duration: -time.Minute}},
+		expectedErrors: 1,
+	}, {
+		name:           "negative drain timeout",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, DrainTimeout: &metav1.Duration{Duration: -time.Minute}},
+		expectedErrors: 1,
+	}, {
+		name: "valid external remediation",
+		spec: MachineHealthCheckSpec{
+			UnhealthyConditions: readyTimeout,
+			RemediationStrategy: RemediationStrategyExternal,
+			RemediationTemplate: &corev1.ObjectReference{APIVersion: "remediation.example.com/v1", Kind: "PowerCycleTemplate", Name: "power-cycle"},
+		},
+		expectedErrors: 0,
+	}, {
+		name: "invalid remediation retry policy",
+		spec: MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, RemediationRetry: &RemediationRetryPolicy{
+			MaxAttempts: -1,
+			Window:      &metav1.Duration{},
+			Backoff:     &metav1.Duration{Duration: -time.Minute},
+		}},
+		expectedErrors: 3,
+	}, {
+		name:           "too many remediation attempts",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, RemediationRetry: &RemediationRetryPolicy{MaxAttempts: 1000}},
+		expectedErrors: 1,
+	}, {
+		name: "valid zone remediation policy",
+		spec: MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, ZoneRemediation: &ZoneRemediationPolicy{
+			TopologyKey: "topology.kubernetes.io/zone",
+		}},
+	}, {
+		name: "invalid zone remediation policy",
+		spec: MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, ZoneRemediation: &ZoneRemediationPolicy{
+			TopologyKey:     "zone/of/node",
+			MaxConcurrent:   -1,
+			OutageThreshold: intOrString(intstr.FromString("150%")),
+		}},
+		expectedErrors: 3,
+	}, {
+		name: "invalid flapping policy",
+		spec: MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, Flapping: &FlappingPolicy{
+			MaxTransitions: 1,
+			Window:         &metav1.Duration{Duration: -time.Minute},
+			RecoveryPeriod: &metav1.Duration{Duration: -time.Minute},
+		}},
+		expectedErrors: 3,
+	}, {
+		name: "valid unhealthy expressions without unhealthy conditions",
+		spec: MachineHealthCheckSpec{UnhealthyExpressions: []UnhealthyExpression{{
+			Name:       "pressure",
+			Expression: `condition("DiskPressure") == "True" && condition("MemoryPressure") == "True"`,
+		}}},
+	}, {
+		name: "invalid unhealthy expressions",
+		spec: MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, UnhealthyExpressions: []UnhealthyExpression{{
+			Name:       "pressure",
+			Expression: `condition("DiskPressure") = "True"`,
+		}, {
+			Name:       "pressure",
+			Expression: `hasLabel("example.com/maintenance")`,
+		}, {
+			Expression: `conditionAge("Ready") > 5m`,
+		}}},
+		expectedErrors: 3,
+	}, {
+		name: "valid required pods without unhealthy conditions",
+		spec: MachineHealthCheckSpec{RequiredPods: []RequiredPod{{
+			Name:      "sdn",
+			Namespace: "openshift-sdn",
+			Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "sdn"}},
+			Timeout:   metav1.Duration{Duration: 5 * time.Minute},
+		}}},
+	}, {
+		name: "invalid required pods",
+		spec: MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, RequiredPods: []RequiredPod{{
+			Name:     "sdn",
+			Selector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Like"}}},
+			Timeout:  metav1.Duration{Duration: -time.Minute},
+		}, {
+			Name:      "sdn",
+			Namespace: "openshift-sdn",
+		}}},
+		expectedErrors: 4,
+	}, {
+		name:           "unknown remediation strategy",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, RemediationStrategy: "Replace"},
+		expectedErrors: 1,
+	}, {
+		name:           "external remediation without a template",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, RemediationStrategy: RemediationStrategyExternal},
+		expectedErrors: 1,
+	}, {
+		name: "invalid remediation template",
+		spec: MachineHealthCheckSpec{
+			UnhealthyConditions: readyTimeout,
+			RemediationStrategy: RemediationStrategyExternal,
+			RemediationTemplate: &corev1.ObjectReference{Kind: "PowerCycle", Namespace: "other"},
+		},
+		expectedErrors: 4,
+	}, {
+		name: "remediation template with the reboot strategy",
+		spec: MachineHealthCheckSpec{
+			UnhealthyConditions: readyTimeout,
+			RemediationStrategy: RemediationStrategyReboot,
+			RemediationTemplate: &corev1.ObjectReference{APIVersion: "remediation.example.com/v1", Kind: "PowerCycleTemplate", Name: "power-cycle"},
+		},
+		expectedErrors: 1,
+	}, {
+		name:           "control plane remediation with the reboot strategy",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, RemediationStrategy: RemediationStrategyReboot, RemediateControlPlane: true},
+		expectedErrors: 0,
+	}, {
+		name:           "control plane remediation with the delete strategy",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, RemediationStrategy: RemediationStrategyDelete, RemediateControlPlane: true},
+		expectedErrors: 1,
+	}, {
+		name:           "control plane remediation with the default strategy",
+		spec:           MachineHealthCheckSpec{UnhealthyConditions: readyTimeout, RemediateControlPlane: true},
+		expectedErrors: 1,
+	}, {
+		name: "control plane remediation with the external strategy",
+		spec: MachineHealthCheckSpec{
+			UnhealthyConditions:   readyTimeout,
+			RemediationStrategy:   RemediationStrategyExternal,
+			RemediationTemplate:   &corev1.ObjectReference{APIVersion: "remediation.example.com/v1", Kind: "PowerCycleTemplate", Name: "power-cycle"},
+			RemediateControlPlane: true,
+		},
+		expectedErrors: 0,
+	}}
+
+	for _, tc := range tests {
+		errs := ValidateMachineHealthCheckSpec(&tc.spec, field.NewPath("spec"))
+		if len(errs) != tc.expectedErrors {
+			t.Errorf("%s: expected %d errors, got %d: %v", tc.name, tc.expectedErrors, len(errs), errs)
+		}
+	}
+}
+
+func TestValidateMachineDisruptionBudgetSpec(t *testing.T) {
+	intOrString := func(value intstr.IntOrString) *intstr.IntOrString {
+		return &value
+	}
+
+	tests := []struct {
+		name           string
+		spec           MachineDisruptionBudgetSpec
+		expectedErrors int
+	}{{
+		name:           "valid min available",
+		spec:           MachineDisruptionBudgetSpec{MinAvailable: intOrString(intstr.FromInt(2))},
+		expectedErrors: 0,
+	}, {
+		name:           "valid max unavailable",
+		spec:           MachineDisruptionBudgetSpec{MaxUnavailable: intOrString(intstr.FromString("25%"))},
+		expectedErrors: 0,
+	}, {
+		name:           "missing budget",
+		spec:           MachineDisruptionBudgetSpec{},
+		expectedErrors: 1,
+	}, {
+		name: "both min available and max unavailable",
+		spec: MachineDisruptionBudgetSpec{
+			MinAvailable:   intOrString(intstr.FromInt(2)),
+			MaxUnavailable: intOrString(intstr.FromInt(1)),
+		},
+		expectedErrors: 1,
+	}, {
+		name:           "negative min available",
+		spec:           MachineDisruptionBudgetSpec{MinAvailable: intOrString(intstr.FromInt(-1))},
+		expectedErrors: 1,
+	}, {
+		name:           "max unavailable percentage out of range",
+		spec:           MachineDisruptionBudgetSpec{MaxUnavailable: intOrString(intstr.FromString("150%"))},
+		expectedErrors: 1,
+	}, {
+		name: "invalid selector",
+		spec: MachineDisruptionBudgetSpec{
+			Selector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "role",
+				Operator: "Matches",
+			}}},
+			MinAvailable: intOrString(intstr.FromInt(1)),
+		},
+		expectedErrors: 1,
+	}}
+
+	for _, tc := range tests {
+		errs := ValidateMachineDisruptionBudgetSpec(&tc.spec, field.NewPath("spec"))
+		if len(errs) != tc.expectedErrors {
+			t.Errorf("%s: expected %d errors, got %d: %v", tc.name, tc.expectedErrors, len(errs), errs)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * This file is part of the machine-health-check-operator project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	internalinterfaces "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// MachineDisruptionBudgets returns a MachineDisruptionBudgetInformer.
+	MachineDisruptionBudgets() MachineDisruptionBudgetInformer
+	// MachineHealthChecks returns a MachineHealthCheckInformer.
+	MachineHealthChecks() MachineHealthCheckInformer
+	// MachineHealthCheckOperatorConfigs returns a MachineHealthCheckOperatorConfigInformer.
+	MachineHealthCheckOperatorConfigs() MachineHealthCheckOperatorConfigInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// MachineDisruptionBudgets returns a MachineDisruptionBudgetInformer.
+func (v *version) MachineDisruptionBudgets() MachineDisruptionBudgetInformer {
+	return &machineDisruptionBudgetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// MachineHealthChecks returns a MachineHealthCheckInformer.
+func (v *version) MachineHealthChecks() MachineHealthCheckInformer {
+	return &machineHealthCheckInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// MachineHealthCheckOperatorConfigs returns a MachineHealthCheckOperatorConfigInformer.
+func (v *version) MachineHealthCheckOperatorConfigs() MachineHealthCheckOperatorConfigInformer {
+	return &machineHealthCheckOperatorConfigInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,68 @@
+/*
+ * This file is part of the machine-health-check-operator project
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// MachineHealthCheckOperatorConfigLister helps list MachineHealthCheckOperatorConfigs.
+type MachineHealthCheckOperatorConfigLister interface {
+	// List lists all MachineHealthCheckOperatorConfigs in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.MachineHealthCheckOperatorConfig, err error)
+	// Get retrieves the MachineHealthCheckOperatorConfig from the index for a given name.
+	Get(name string) (*v1alpha1.MachineHealthCheckOperatorConfig, error)
+	MachineHealthCheckOperatorConfigListerExpansion
+}
+
+// machineHealthCheckOperatorConfigLister implements the MachineHealthCheckOperatorConfigLister interface.
+type machineHealthCheckOperatorConfigLister struct {
+	indexer cache.Indexer
+}
+
+// NewMachineHealthCheckOperatorConfigLister returns a new MachineHealthCheckOperatorConfigLister.
+func NewMachineHealthCheckOperatorConfigLister(indexer cache.Indexer) MachineHealthCheckOperatorConfigLister {
+	return &machineHealthCheckOperatorConfigLister{indexer: indexer}
+}
+
+// List lists all MachineHealthCheckOperatorConfigs in the indexer.
+func (s *machineHealthCheckOperatorConfigLister) List(selector labels.Selector) (ret []*v1alpha1.MachineHealthCheckOperatorConfig, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.MachineHealthCheckOperatorConfig))
+	})
+	return ret, err
+}
+
+// Get retrieves the MachineHealthCheckOperatorConfig from the index for a given name.
+func (s *machineHealthCheckOperatorConfigLister) Get(name string) (*v1alpha1.MachineHealthCheckOperatorConfig, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("machinehealthcheckoperatorconfig"), name)
+	}
+	return obj.(*v1alpha1.MachineHealthCheckOperatorConfig), nil
+}
@@ -0,0 +1,70 @@
+package machine
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// NodeAnnotation contains the annotation key set on the nodes with the namespaced name of their machine
+	NodeAnnotation = "machine.openshift.io/machine"
+	// RoleLabel contains the label key set on the machines with their role
+	RoleLabel = "machine.openshift.io/cluster-api-machine-role"
+	// ControlPlaneRole contains the role label value of the control plane machines
+	ControlPlaneRole = "master"
+	// ControlPlaneNodeLabel contains the label key set on the control plane nodes
+	ControlPlaneNodeLabel = "node-role.kubernetes.io/master"
+)
+
+// Resource contains the group version resource of the machines watched by the controllers
+var Resource = schema.GroupVersionResource{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machines"}
+
+// NodeName returns the name of the node referenced by the machine status
+func NodeName(machine *unstructured.Unstructured) string {
+	name, _, _ := unstructured.NestedString(machine.Object, "status", "nodeRef", "name")
+	return name
+}
+
+// Phase returns the phase of the machine status
+func Phase(machine *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(machine.Object, "status", "phase")
+	return phase
+}
+
+// IsControlPlane returns true when the machine or its node, if any, has the control plane role
+func IsControlPlane(machine *unstructured.Unstructured, node *corev1.Node) bool {
+	if machine.GetLabels()[RoleLabel] == ControlPlaneRole {
+		return true
+	}
+	if node == nil {
+		return false
+	}
+	_, ok := node.Labels[ControlPlaneNodeLabel]
+	return ok
+}
+
+// ForNode returns the machine of the node from the lister. It returns nil
+// without an error when the node does not have the machine annotation.
+func ForNode(machineLister cache.GenericLister, node *corev1.Node) (*unstructured.Unstructured, error) {
+	machineKey, ok := node.Annotations[NodeAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(machineKey)
+	if err != nil {
+		return nil, fmt.Errorf("node %s has invalid %s annotation %q: %v", node.Name, NodeAnnotation, machineKey, err)
+	}
+	obj, err := machineLister.ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	machine, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected machine type %T", obj)
+	}
+	return machine, nil
+}
@@ -0,0 +1,228 @@
+package machinedisruptionbudget
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	mhcclientset "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned"
+	healthcheckinginformersv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions/healthchecking/v1alpha1"
+	healthcheckinglistersv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/client/listers/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// maxRetries is the number of times a machine disruption budget will be retried before it is dropped out of the queue.
+	maxRetries = 15
+)
+
+// Controller keeps the status of the machine disruption budgets up to date with
+// the number of the healthy machines they select.
+type Controller struct {
+	mhcClient     mhcclientset.Interface
+	eventRecorder record.EventRecorder
+
+	syncHandler func(key string) error
+
+	mdbLister       healthcheckinglistersv1alpha1.MachineDisruptionBudgetLister
+	mdbListerSynced cache.InformerSynced
+
+	machineLister       cache.GenericLister
+	machineListerSynced cache.InformerSynced
+
+	nodeLister       corelistersv1.NodeLister
+	nodeListerSynced cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+}
+
+// New returns a new machine disruption budget controller.
+func New(
+	mdbInformer healthcheckinginformersv1alpha1.MachineDisruptionBudgetInformer,
+	machineInformer informers.GenericInformer,
+	nodeInformer coreinformersv1.NodeInformer,
+
+	mhcClient mhcclientset.Interface,
+
+	recorder record.EventRecorder,
+) *Controller {
+	c := &Controller{
+		mhcClient:     mhcClient,
+		eventRecorder: recorder,
+		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "machinedisruptionbudget"),
+	}
+
+	mdbInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(old, new interface{}) { c.enqueue(new) },
+	})
+	machineInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.machineEvent,
+		UpdateFunc: func(old, new interface{}) { c.machineEvent(new) },
+		DeleteFunc: c.machineEvent,
+	})
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) { c.nodeEvent(new) },
+		DeleteFunc: c.nodeEvent,
+	})
+
+	c.syncHandler = c.sync
+
+	c.mdbLister = mdbInformer.Lister()
+	c.mdbListerSynced = mdbInformer.Informer().HasSynced
+
+	c.machineLister = machineInformer.Lister()
+	c.machineListerSynced = machineInformer.Informer().HasSynced
+
+	c.nodeLister = nodeInformer.Lister()
+	c.nodeListerSynced = nodeInformer.Informer().HasSynced
+
+	return c
+}
+
+// Run runs the machine disruption budget controller. Once the stop channel is closed, it shuts
+// down the queue and returns after the workers finished processing the queued keys.
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	glog.Info("Starting Machine Disruption Budget Controller")
+	defer glog.Info("Shutting down Machine Disruption Budget Controller")
+
+	if !cache.WaitForCacheSync(stopCh,
+		c.mdbListerSynced,
+		c.machineListerSynced,
+		c.nodeListerSynced) {
+		glog.Error("Failed to sync caches")
+		c.queue.ShutDown()
+		return
+	}
+	glog.Info("Synced up caches")
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(c.worker, time.Second, stopCh)
+		}()
+	}
+
+	<-stopCh
+
+	// the workers return once the shut down queue is drained
+	glog.Info("Draining the controller queue")
+	c.queue.ShutDown()
+	wg.Wait()
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// machineEvent enqueues the machine disruption budgets selecting the machine
+func (c *Controller) machineEvent(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	m, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	c.enqueueMachineDisruptionBudgets(m)
+}
+
+// nodeEvent enqueues the machine disruption budgets selecting the machine of the node
+func (c *Controller) nodeEvent(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return
+	}
+	m, err := machine.ForNode(c.machineLister, node)
+	if err != nil {
+		glog.V(4).Infof("Failed to get machine of node %s: %v", node.Name, err)
+		return
+	}
+	if m != nil {
+		c.enqueueMachineDisruptionBudgets(m)
+	}
+}
+
+func (c *Controller) enqueueMachineDisruptionBudgets(m *unstructured.Unstructured) {
+	mdbs, err := c.mdbLister.MachineDisruptionBudgets(m.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, mdb := range mdbs {
+		if Selects(mdb, m) {
+			c.enqueue(mdb)
+		}
+	}
+}
+
+// Selects returns true when the machine disruption budget selector matches the machine labels
+func Selects(mdb *healthcheckingv1alpha1.MachineDisruptionBudget, m *unstructured.Unstructured) bool {
+	selector, err := metav1.LabelSelectorAsSelector(&mdb.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(m.GetLabels()))
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	glog.V(4).Infof("Processing key %s", key)
+	err := c.syncHandler(key.(string))
+	c.handleErr(err, key)
+
+	return true
+}
+
+func (c *Controller) handleErr(err error, key interface{}) {
+	if err == nil {
+		c.queue.Forget(key)
+		return
+	}
+
+	if c.queue.NumRequeues(key) < maxRetries {
+		glog.V(1).Infof("Error syncing machine disruption budget %v: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	glog.V(1).Infof("Dropping machine disruption budget %q out of the queue: %v", key, err)
+	c.queue.Forget(key)
+}
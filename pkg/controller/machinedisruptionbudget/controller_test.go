@@ -0,0 +1,178 @@
+package machinedisruptionbudget
+
+import (
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	fakemhc "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned/fake"
+	mhcinformers "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	namespace = "openshift-machine-api"
+	mdbName   = "workers"
+)
+
+var workerLabels = map[string]string{"machine.openshift.io/cluster-api-machine-role": "worker"}
+
+func newMachine(name, nodeName string) *unstructured.Unstructured {
+	m := &unstructured.Unstructured{}
+	m.SetAPIVersion("machine.openshift.io/v1beta1")
+	m.SetKind("Machine")
+	m.SetNamespace(namespace)
+	m.SetName(name)
+	m.SetLabels(workerLabels)
+	if nodeName != "" {
+		unstructured.SetNestedField(m.Object, nodeName, "status", "nodeRef", "name")
+	}
+	return m
+}
+
+func newNode(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{machine.NodeAnnotation: namespace + "/" + name},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{
+				Type:   corev1.NodeReady,
+				Status: ready,
+			}},
+		},
+	}
+}
+
+func newMachineDisruptionBudget(minAvailable, maxUnavailable *intstr.IntOrString) *healthcheckingv1alpha1.MachineDisruptionBudget {
+	return &healthcheckingv1alpha1.MachineDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       mdbName,
+			Namespace:  namespace,
+			Generation: 1,
+		},
+		Spec: healthcheckingv1alpha1.MachineDisruptionBudgetSpec{
+			Selector:       metav1.LabelSelector{MatchLabels: workerLabels},
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+		},
+	}
+}
+
+func newFakeController(t *testing.T, nodes []runtime.Object, machines []runtime.Object, mdbs []runtime.Object, stopCh <-chan struct{}) *Controller {
+	kubeClient := fakekube.NewSimpleClientset(nodes...)
+	machineClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), machines...)
+	mhcClient := fakemhc.NewSimpleClientset(mdbs...)
+
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	machineInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(machineClient, 0)
+	mhcInformerFactory := mhcinformers.NewSharedInformerFactory(mhcClient, 0)
+
+	c := New(
+		mhcInformerFactory.Healthchecking().V1alpha1().MachineDisruptionBudgets(),
+		machineInformerFactory.ForResource(machine.Resource),
+		kubeInformerFactory.Core().V1().Nodes(),
+		mhcClient,
+		record.NewFakeRecorder(50),
+	)
+
+	kubeInformerFactory.Start(stopCh)
+	machineInformerFactory.Start(stopCh)
+	mhcInformerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, c.mdbListerSynced, c.machineListerSynced, c.nodeListerSynced) {
+		t.Fatal("Failed to sync caches")
+	}
+	return c
+}
+
+func TestSync(t *testing.T) {
+	intOrString := func(value intstr.IntOrString) *intstr.IntOrString {
+		return &value
+	}
+	deleting := newMachine("c", "c")
+	deleting.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+
+	tests := []struct {
+		name             string
+		nodes            []runtime.Object
+		machines         []runtime.Object
+		minAvailable     *intstr.IntOrString
+		maxUnavailable   *intstr.IntOrString
+		expectedHealthy  int32
+		expectedDesired  int32
+		expectedExpected int32
+	}{{
+		name:             "healthy machines with min available",
+		nodes:            []runtime.Object{newNode("a", corev1.ConditionTrue), newNode("b", corev1.ConditionTrue)},
+		machines:         []runtime.Object{newMachine("a", "a"), newMachine("b", "b")},
+		minAvailable:     intOrString(intstr.FromInt(1)),
+		expectedHealthy:  2,
+		expectedDesired:  1,
+		expectedExpected: 2,
+	}, {
+		name:             "unhealthy machines with max unavailable percentage",
+		nodes:            []runtime.Object{newNode("a", corev1.ConditionTrue), newNode("b", corev1.ConditionUnknown), newNode("c", corev1.ConditionTrue)},
+		machines:         []runtime.Object{newMachine("a", "a"), newMachine("b", "b"), deleting, newMachine("d", "")},
+		maxUnavailable:   intOrString(intstr.FromString("25%")),
+		expectedHealthy:  1,
+		expectedDesired:  3,
+		expectedExpected: 4,
+	}, {
+		name:             "min available percentage rounded up",
+		nodes:            []runtime.Object{newNode("a", corev1.ConditionTrue), newNode("b", corev1.ConditionTrue), newNode("c", corev1.ConditionTrue)},
+		machines:         []runtime.Object{newMachine("a", "a"), newMachine("b", "b"), newMachine("c", "c")},
+		minAvailable:     intOrString(intstr.FromString("50%")),
+		expectedHealthy:  3,
+		expectedDesired:  2,
+		expectedExpected: 3,
+	}, {
+		name:             "max unavailable larger than the machines",
+		nodes:            []runtime.Object{newNode("a", corev1.ConditionTrue)},
+		machines:         []runtime.Object{newMachine("a", "a")},
+		maxUnavailable:   intOrString(intstr.FromInt(3)),
+		expectedHealthy:  1,
+		expectedDesired:  0,
+		expectedExpected: 1,
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mdb := newMachineDisruptionBudget(tc.minAvailable, tc.maxUnavailable)
+		c := newFakeController(t, tc.nodes, tc.machines, []runtime.Object{mdb}, stopCh)
+
+		if err := c.sync(namespace + "/" + mdbName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		updated, err := c.mhcClient.HealthcheckingV1alpha1().MachineDisruptionBudgets(namespace).Get(mdbName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get machine disruption budget: %v", tc.name, err)
+		}
+		if updated.Status.ObservedGeneration != 1 {
+			t.Errorf("%s: expected observed generation 1, got %d", tc.name, updated.Status.ObservedGeneration)
+		}
+		if updated.Status.ExpectedMachines != tc.expectedExpected {
+			t.Errorf("%s: expected %d expected machines, got %d", tc.name, tc.expectedExpected, updated.Status.ExpectedMachines)
+		}
+		if updated.Status.CurrentHealthy != tc.expectedHealthy {
+			t.Errorf("%s: expected %d healthy machines, got %d", tc.name, tc.expectedHealthy, updated.Status.CurrentHealthy)
+		}
+		if updated.Status.DesiredHealthy != tc.expectedDesired {
+			t.Errorf("%s: expected %d desired healthy machines, got %d", tc.name, tc.expectedDesired, updated.Status.DesiredHealthy)
+		}
+		close(stopCh)
+	}
+}
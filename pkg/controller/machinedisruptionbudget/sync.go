@@ -0,0 +1,133 @@
+package machinedisruptionbudget
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// EventReasonInvalidSpec is the reason of the event reporting an invalid machine disruption budget spec
+	EventReasonInvalidSpec = "InvalidSpec"
+)
+
+func (c *Controller) sync(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	mdb, err := c.mdbLister.MachineDisruptionBudgets(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		glog.V(4).Infof("Machine disruption budget %s was deleted", key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	mdb = mdb.DeepCopy()
+	if errs := healthcheckingv1alpha1.ValidateMachineDisruptionBudgetSpec(&mdb.Spec, field.NewPath("spec")); len(errs) > 0 {
+		// the spec is not retried until it is updated
+		glog.Errorf("Invalid machine disruption budget %s: %v", key, errs.ToAggregate())
+		c.eventRecorder.Eventf(mdb, corev1.EventTypeWarning, EventReasonInvalidSpec, "Invalid spec: %v", errs.ToAggregate())
+		return nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&mdb.Spec.Selector)
+	if err != nil {
+		return err
+	}
+	objs, err := c.machineLister.ByNamespace(mdb.Namespace).List(selector)
+	if err != nil {
+		return err
+	}
+
+	healthy := 0
+	for _, obj := range objs {
+		m, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected machine type %T", obj)
+		}
+		node, err := c.getNode(m)
+		if err != nil {
+			return err
+		}
+		if IsMachineHealthy(m, node) {
+			healthy++
+		}
+	}
+
+	desired, err := DesiredHealthy(&mdb.Spec, len(objs))
+	if err != nil {
+		return err
+	}
+	return c.updateStatus(mdb, len(objs), healthy, desired)
+}
+
+// getNode returns the node of the machine or nil when the machine does not have one
+func (c *Controller) getNode(m *unstructured.Unstructured) (*corev1.Node, error) {
+	name := machine.NodeName(m)
+	if name == "" {
+		return nil, nil
+	}
+	node, err := c.nodeLister.Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return node, err
+}
+
+// IsMachineHealthy returns true when the machine is not being deleted and its node is ready
+func IsMachineHealthy(m *unstructured.Unstructured, node *corev1.Node) bool {
+	if m.GetDeletionTimestamp() != nil || node == nil {
+		return false
+	}
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// DesiredHealthy returns the minimal number of the expected machines that have to stay healthy
+func DesiredHealthy(spec *healthcheckingv1alpha1.MachineDisruptionBudgetSpec, expected int) (int, error) {
+	if spec.MinAvailable != nil {
+		return intstr.GetValueFromIntOrPercent(spec.MinAvailable, expected, true)
+	}
+	maxUnavailable, err := intstr.GetValueFromIntOrPercent(spec.MaxUnavailable, expected, true)
+	if err != nil {
+		return 0, err
+	}
+	if desired := expected - maxUnavailable; desired > 0 {
+		return desired, nil
+	}
+	return 0, nil
+}
+
+// updateStatus updates the machine disruption budget status when the observed machines changed
+func (c *Controller) updateStatus(mdb *healthcheckingv1alpha1.MachineDisruptionBudget, expected, healthy, desired int) error {
+	status := mdb.Status.DeepCopy()
+	status.ObservedGeneration = mdb.Generation
+	status.ExpectedMachines = int32(expected)
+	status.CurrentHealthy = int32(healthy)
+	status.DesiredHealthy = int32(desired)
+	if equality.Semantic.DeepEqual(&mdb.Status, status) {
+		return nil
+	}
+
+	mdb.Status = *status
+	_, err := c.mhcClient.HealthcheckingV1alpha1().MachineDisruptionBudgets(mdb.Namespace).UpdateStatus(mdb)
+	return err
+}
@@ -0,0 +1,64 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machinedisruptionbudget"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// disruptionBudgets tracks the machine disruption budgets of a namespace during a single sync.
+// The status of the budgets is updated only once their controller observes the deleted
+// machines, so the machines deleted by the sync are subtracted from the budgets in place.
+type disruptionBudgets struct {
+	budgets []*healthcheckingv1alpha1.MachineDisruptionBudget
+}
+
+func (c *Controller) getDisruptionBudgets(namespace string) (*disruptionBudgets, error) {
+	mdbs, err := c.mdbLister.MachineDisruptionBudgets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	budgets := &disruptionBudgets{}
+	for _, mdb := range mdbs {
+		budgets.budgets = append(budgets.budgets, mdb.DeepCopy())
+	}
+	return budgets, nil
+}
+
+// violation returns the reason why deleting the target machine violates one of the budgets
+// selecting it, or an empty string when the machine can be deleted.
+func (b *disruptionBudgets) violation(t *target) string {
+	healthy := machinedisruptionbudget.IsMachineHealthy(t.Machine, t.Node)
+	for _, mdb := range b.budgets {
+		if !machinedisruptionbudget.Selects(mdb, t.Machine) {
+			continue
+		}
+		// the budget status does not reflect its spec yet
+		if mdb.Status.ObservedGeneration < mdb.Generation {
+			return fmt.Sprintf("machine disruption budget %s is not observed yet", mdb.Name)
+		}
+		remaining := mdb.Status.CurrentHealthy
+		if healthy {
+			remaining--
+		}
+		if remaining < mdb.Status.DesiredHealthy {
+			return fmt.Sprintf("machine disruption budget %s requires %d healthy machines, %d would remain", mdb.Name, mdb.Status.DesiredHealthy, remaining)
+		}
+	}
+	return ""
+}
+
+// disrupt subtracts the deleted target machine from the budgets counting it as healthy
+func (b *disruptionBudgets) disrupt(t *target) {
+	if !machinedisruptionbudget.IsMachineHealthy(t.Machine, t.Node) {
+		return
+	}
+	for _, mdb := range b.budgets {
+		if machinedisruptionbudget.Selects(mdb, t.Machine) {
+			mdb.Status.CurrentHealthy--
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package machinehealthcheck
+
+import (
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+)
+
+// setCondition sets the corresponding condition in conditions to newCondition.
+// The last transition time is only updated when the condition status changes.
+func setCondition(conditions *[]healthcheckingv1alpha1.MachineHealthCheckCondition, newCondition healthcheckingv1alpha1.MachineHealthCheckCondition) {
+	for i := range *conditions {
+		existingCondition := &(*conditions)[i]
+		if existingCondition.Type != newCondition.Type {
+			continue
+		}
+		if existingCondition.Status != newCondition.Status {
+			existingCondition.Status = newCondition.Status
+			existingCondition.LastTransitionTime = newCondition.LastTransitionTime
+		}
+		existingCondition.Reason = newCondition.Reason
+		existingCondition.Message = newCondition.Message
+		return
+	}
+	*conditions = append(*conditions, newCondition)
+}
+
+// findCondition returns the condition of the given type or nil when it is not set
+func findCondition(conditions []healthcheckingv1alpha1.MachineHealthCheckCondition, conditionType healthcheckingv1alpha1.MachineHealthCheckConditionType) *healthcheckingv1alpha1.MachineHealthCheckCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package machinehealthcheck
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	mhcclientset "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned"
+	healthcheckinginformersv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions/healthchecking/v1alpha1"
+	healthcheckinglistersv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/client/listers/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/informers"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelistersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// maxRetries is the number of times a machine health check will be retried before it is dropped out of the queue.
+	maxRetries = 15
+)
+
+// Controller checks the health of the nodes of the machines selected by the machine
+// health checks and remediates the machines whose nodes are unhealthy.
+type Controller struct {
+	kubeClient    kubernetes.Interface
+	machineClient dynamic.Interface
+	mhcClient     mhcclientset.Interface
+	eventRecorder record.EventRecorder
+
+	// dryRun enables the dry-run mode for all the machine health checks
+	dryRun bool
+	// transitions tracks the transitions of the node conditions to detect the flapping nodes
+	transitions *transitionTracker
+	// unhealthyReports tracks the unhealthy machines already reported by an event
+	unhealthyReports *unhealthyReports
+
+	syncHandler func(key string) error
+	// now returns the current time, it is replaced in tests
+	now func() time.Time
+
+	mhcLister       healthcheckinglistersv1alpha1.MachineHealthCheckLister
+	mhcListerSynced cache.InformerSynced
+
+	mdbLister       healthcheckinglistersv1alpha1.MachineDisruptionBudgetLister
+	mdbListerSynced cache.InformerSynced
+
+	machineLister       cache.GenericLister
+	machineListerSynced cache.InformerSynced
+
+	nodeLister       corelistersv1.NodeLister
+	nodeListerSynced cache.InformerSynced
+
+	// pods watches the pods of the namespaces of the required pods
+	pods *podInformers
+
+	queue workqueue.RateLimitingInterface
+}
+
+// New returns a new machine health check controller.
+func New(
+	mhcInformer healthcheckinginformersv1alpha1.MachineHealthCheckInformer,
+	mdbInformer healthcheckinginformersv1alpha1.MachineDisruptionBudgetInformer,
+	machineInformer informers.GenericInformer,
+	nodeInformer coreinformersv1.NodeInformer,
+
+	kubeClient kubernetes.Interface,
+	machineClient dynamic.Interface,
+	mhcClient mhcclientset.Interface,
+
+	recorder record.EventRecorder,
+	dryRun bool,
+) *Controller {
+	c := &Controller{
+		kubeClient:       kubeClient,
+		machineClient:    machineClient,
+		mhcClient:        mhcClient,
+		eventRecorder:    recorder,
+		dryRun:           dryRun,
+		transitions:      newTransitionTracker(),
+		unhealthyReports: newUnhealthyReports(),
+		now:              time.Now,
+		queue:            workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "machinehealthcheck"),
+	}
+
+	mhcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.enqueue,
+		UpdateFunc: func(old, new interface{}) { c.enqueue(new) },
+		// the metrics of a deleted machine health check are removed once its key is synced
+		DeleteFunc: c.enqueue,
+	})
+	// the remediations refused by a budget are retried once the budget status changes
+	mdbInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) { c.machineDisruptionBudgetEvent(new) },
+	})
+	machineInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.machineEvent,
+		UpdateFunc: func(old, new interface{}) { c.machineEvent(new) },
+		DeleteFunc: c.machineEvent,
+	})
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, new interface{}) { c.nodeEvent(new) },
+		DeleteFunc: c.nodeDeleted,
+	})
+	// the required pods of a node becoming ready or not ready change the health of the node
+	c.pods = newPodInformers(kubeClient, cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.podEvent,
+		UpdateFunc: func(old, new interface{}) { c.podEvent(new) },
+		DeleteFunc: c.podEvent,
+	})
+
+	c.syncHandler = c.sync
+
+	c.mhcLister = mhcInformer.Lister()
+	c.mhcListerSynced = mhcInformer.Informer().HasSynced
+
+	c.mdbLister = mdbInformer.Lister()
+	c.mdbListerSynced = mdbInformer.Informer().HasSynced
+
+	c.machineLister = machineInformer.Lister()
+	c.machineListerSynced = machineInformer.Informer().HasSynced
+
+	c.nodeLister = nodeInformer.Lister()
+	c.nodeListerSynced = nodeInformer.Informer().HasSynced
+
+	return c
+}
+
+// Run runs the machine health check controller. Once the stop channel is closed, it shuts
+// down the queue and returns after the workers finished processing the queued keys.
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	glog.Info("Starting Machine Health Check Controller")
+	defer glog.Info("Shutting down Machine Health Check Controller")
+
+	if !cache.WaitForCacheSync(stopCh,
+		c.mhcListerSynced,
+		c.mdbListerSynced,
+		c.machineListerSynced,
+		c.nodeListerSynced) {
+		glog.Error("Failed to sync caches")
+		c.queue.ShutDown()
+		return
+	}
+	glog.Info("Synced up caches")
+
+	// the pod informers are started by the workers once a machine health check requires pods,
+	// the remaining ones are stopped after the workers returned
+	defer c.pods.stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(c.worker, time.Second, stopCh)
+		}()
+	}
+
+	<-stopCh
+
+	// the workers return once the shut down queue is drained
+	glog.Info("Draining the controller queue")
+	c.queue.ShutDown()
+	wg.Wait()
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.queue.Add(key)
+}
+
+// machineEvent enqueues the machine health checks selecting the machine
+func (c *Controller) machineEvent(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	machine, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	c.enqueueMachineHealthChecks(machine)
+}
+
+// nodeEvent enqueues the machine health checks selecting the machine of the node
+func (c *Controller) nodeEvent(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return
+	}
+	c.transitions.observe(node)
+	m, err := machine.ForNode(c.machineLister, node)
+	if err != nil {
+		glog.V(4).Infof("Failed to get machine of node %s: %v", node.Name, err)
+		return
+	}
+	if m != nil {
+		c.enqueueMachineHealthChecks(m)
+	}
+}
+
+// nodeDeleted enqueues the machine health checks selecting the machine of the deleted node
+// and forgets the transitions of its conditions
+func (c *Controller) nodeDeleted(obj interface{}) {
+	c.nodeEvent(obj)
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if node, ok := obj.(*corev1.Node); ok {
+		c.transitions.forget(node.Name)
+	}
+}
+
+// machineDisruptionBudgetEvent enqueues all the machine health checks in the namespace of the budget
+func (c *Controller) machineDisruptionBudgetEvent(obj interface{}) {
+	mdb, ok := obj.(*healthcheckingv1alpha1.MachineDisruptionBudget)
+	if !ok {
+		return
+	}
+	mhcs, err := c.mhcLister.MachineHealthChecks(mdb.Namespace).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, mhc := range mhcs {
+		c.enqueue(mhc)
+	}
+}
+
+func (c *Controller) enqueueMachineHealthChecks(machine *unstructured.Unstructured) {
+	mhcs, err := c.mhcLister.MachineHealthChecks(machine.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, mhc := range mhcs {
+		if selects(mhc, machine) {
+			c.enqueue(mhc)
+		}
+	}
+}
+
+// selects returns true when the machine health check selector matches the machine labels
+func selects(mhc *healthcheckingv1alpha1.MachineHealthCheck, machine *unstructured.Unstructured) bool {
+	selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(machine.GetLabels()))
+}
+
+func (c *Controller) worker() {
+	for c.processNextWorkItem() {
+	}
+}
+
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	glog.V(4).Infof("Processing key %s", key)
+	err := c.syncHandler(key.(string))
+	c.handleErr(err, key)
+
+	return true
+}
+
+func (c *Controller) handleErr(err error, key interface{}) {
+	if err == nil {
+		c.queue.Forget(key)
+		return
+	}
+
+	if c.queue.NumRequeues(key) < maxRetries {
+		glog.V(1).Infof("Error syncing machine health check %v: %v", key, err)
+		c.queue.AddRateLimited(key)
+		return
+	}
+
+	utilruntime.HandleError(err)
+	glog.V(1).Infof("Dropping machine health check %q out of the queue: %v", key, err)
+	c.queue.Forget(key)
+}
@@ -0,0 +1,491 @@
+package machinehealthcheck
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	fakemhc "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned/fake"
+	mhcinformers "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions"
+	healthcheckinglistersv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/client/listers/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	namespace = "openshift-machine-api"
+	mhcName   = "workers"
+)
+
+var workerLabels = map[string]string{"machine.openshift.io/cluster-api-machine-role": "worker"}
+
+func newMachine(name, nodeName string, ownedByMachineSet bool) *unstructured.Unstructured {
+	machine := &unstructured.Unstructured{}
+	machine.SetAPIVersion("machine.openshift.io/v1beta1")
+	machine.SetKind("Machine")
+	machine.SetNamespace(namespace)
+	machine.SetName(name)
+	machine.SetLabels(workerLabels)
+	if ownedByMachineSet {
+		machine.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: "machine.openshift.io/v1beta1",
+			Kind:       machineSetKind,
+			Name:       "workers",
+			Controller: pointer.BoolPtr(true),
+		}})
+	}
+	if nodeName != "" {
+		unstructured.SetNestedField(machine.Object, nodeName, "status", "nodeRef", "name")
+	}
+	return machine
+}
+
+// newMachineCreatedAt returns a machine owned by a machine set without a node
+func newMachineCreatedAt(name string, created time.Time) *unstructured.Unstructured {
+	machine := newMachine(name, "", true)
+	machine.SetCreationTimestamp(metav1.NewTime(created))
+	return machine
+}
+
+func newNode(name string, ready corev1.ConditionStatus, lastTransition time.Time) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{machine.NodeAnnotation: namespace + "/" + name},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{
+				Type:               corev1.NodeReady,
+				Status:             ready,
+				LastTransitionTime: metav1.NewTime(lastTransition),
+			}},
+		},
+	}
+}
+
+func newMachineHealthCheck(maxUnhealthy *intstr.IntOrString) *healthcheckingv1alpha1.MachineHealthCheck {
+	return &healthcheckingv1alpha1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mhcName,
+			Namespace: namespace,
+		},
+		Spec: healthcheckingv1alpha1.MachineHealthCheckSpec{
+			Selector: metav1.LabelSelector{MatchLabels: workerLabels},
+			UnhealthyConditions: []healthcheckingv1alpha1.UnhealthyCondition{{
+				Type:    corev1.NodeReady,
+				Status:  corev1.ConditionUnknown,
+				Timeout: metav1.Duration{Duration: 5 * time.Minute},
+			}},
+			MaxUnhealthy: maxUnhealthy,
+		},
+	}
+}
+
+// newMachineDisruptionBudget returns a budget selecting the worker machines with an up to date status
+func newMachineDisruptionBudget(currentHealthy, desiredHealthy int32) *healthcheckingv1alpha1.MachineDisruptionBudget {
+	minAvailable := intstr.FromInt(int(desiredHealthy))
+	return &healthcheckingv1alpha1.MachineDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "workers",
+			Namespace:  namespace,
+			Generation: 1,
+		},
+		Spec: healthcheckingv1alpha1.MachineDisruptionBudgetSpec{
+			Selector:     metav1.LabelSelector{MatchLabels: workerLabels},
+			MinAvailable: &minAvailable,
+		},
+		Status: healthcheckingv1alpha1.MachineDisruptionBudgetStatus{
+			ObservedGeneration: 1,
+			CurrentHealthy:     currentHealthy,
+			DesiredHealthy:     desiredHealthy,
+		},
+	}
+}
+
+// newFakeController returns a controller with synced informers, the mhcObjects contain
+// the machine health checks and the machine disruption budgets
+func newFakeController(t *testing.T, nodes []runtime.Object, machines []runtime.Object, mhcObjects []runtime.Object, stopCh <-chan struct{}) (*Controller, *record.FakeRecorder) {
+	kubeClient := fakekube.NewSimpleClientset(nodes...)
+	machineClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), machines...)
+	mhcClient := fakemhc.NewSimpleClientset(mhcObjects...)
+
+	kubeInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	machineInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(machineClient, 0)
+	mhcInformerFactory := mhcinformers.NewSharedInformerFactory(mhcClient, 0)
+
+	recorder := record.NewFakeRecorder(50)
+	c := New(
+		mhcInformerFactory.Healthchecking().V1alpha1().MachineHealthChecks(),
+		mhcInformerFactory.Healthchecking().V1alpha1().MachineDisruptionBudgets(),
+		machineInformerFactory.ForResource(machine.Resource),
+		kubeInformerFactory.Core().V1().Nodes(),
+		kubeClient,
+		machineClient,
+		mhcClient,
+		recorder,
+		false,
+	)
+	go func() {
+		<-stopCh
+		c.pods.stop()
+	}()
+
+	kubeInformerFactory.Start(stopCh)
+	machineInformerFactory.Start(stopCh)
+	mhcInformerFactory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, c.mhcListerSynced, c.mdbListerSynced, c.machineListerSynced, c.nodeListerSynced) {
+		t.Fatal("Failed to sync caches")
+	}
+	return c, recorder
+}
+
+// events returns the reasons of the recorded events
+func events(recorder *record.FakeRecorder) []string {
+	reasons := []string{}
+	for {
+		select {
+		case event := <-recorder.Events:
+			reasons = append(reasons, strings.Fields(event)[1])
+		default:
+			return reasons
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	now := time.Now()
+	unhealthyNode := func(name string) runtime.Object {
+		return newNode(name, corev1.ConditionUnknown, now.Add(-10*time.Minute))
+	}
+	healthyNode := func(name string) runtime.Object {
+		return newNode(name, corev1.ConditionTrue, now.Add(-10*time.Minute))
+	}
+	maxOne := intstr.FromInt(1)
+
+	tests := []struct {
+		name         string
+		nodes        []runtime.Object
+		machines     []runtime.Object
+		mdbs         []runtime.Object
+		maxUnhealthy *intstr.IntOrString
+		// nodeStartupTimeout sets the node startup timeout of the machine health check
+		nodeStartupTimeout *metav1.Duration
+		// dryRun sets the dry-run mode of the machine health check, controllerDryRun of the controller
+		dryRun           bool
+		controllerDryRun bool
+		expectedDryRun   []string
+		// wasRestricted sets the RemediationAllowed condition to false before the sync
+		wasRestricted      bool
+		expectedDeleted    []string
+		expectedHealthy    int32
+		expectedEvents     []string
+		expectedExpected   int32
+		expectedRestricted bool
+	}{{
+		name:             "healthy machines",
+		nodes:            []runtime.Object{healthyNode("a"), healthyNode("b")},
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true)},
+		expectedHealthy:  2,
+		expectedExpected: 2,
+		expectedEvents:   []string{},
+	}, {
+		name:             "unhealthy machine",
+		nodes:            []runtime.Object{healthyNode("a"), unhealthyNode("b")},
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true)},
+		expectedDeleted:  []string{"b"},
+		expectedHealthy:  1,
+		expectedExpected: 2,
+		expectedEvents:   []string{ReasonUnhealthyNodeCondition, EventReasonNodeDrainStarted, EventReasonMachineDeleted},
+	}, {
+		name:             "machine with a deleted node",
+		nodes:            []runtime.Object{healthyNode("a")},
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true)},
+		expectedDeleted:  []string{"b"},
+		expectedHealthy:  1,
+		expectedExpected: 2,
+		expectedEvents:   []string{ReasonNodeNotFound, EventReasonMachineDeleted},
+	}, {
+		name:               "machine without a node within the node startup timeout",
+		nodes:              []runtime.Object{healthyNode("a")},
+		machines:           []runtime.Object{newMachine("a", "a", true), newMachineCreatedAt("b", now.Add(-5*time.Minute))},
+		nodeStartupTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+		expectedHealthy:    2,
+		expectedExpected:   2,
+		expectedEvents:     []string{},
+	}, {
+		name:               "machine without a node after the node startup timeout",
+		nodes:              []runtime.Object{healthyNode("a")},
+		machines:           []runtime.Object{newMachine("a", "a", true), newMachineCreatedAt("b", now.Add(-20*time.Minute))},
+		nodeStartupTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+		expectedDeleted:    []string{"b"},
+		expectedHealthy:    1,
+		expectedExpected:   2,
+		expectedEvents:     []string{ReasonNodeStartupTimeout, EventReasonMachineDeleted},
+	}, {
+		name:             "unhealthy machine in the dry-run mode",
+		nodes:            []runtime.Object{healthyNode("a"), unhealthyNode("b")},
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true)},
+		dryRun:           true,
+		expectedHealthy:  1,
+		expectedExpected: 2,
+		expectedDryRun:   []string{"b"},
+		expectedEvents:   []string{ReasonUnhealthyNodeCondition, EventReasonRemediationDryRun},
+	}, {
+		name:             "unhealthy machines in the cluster-wide dry-run mode",
+		nodes:            []runtime.Object{unhealthyNode("a"), unhealthyNode("b")},
+		machines:         []runtime.Object{newMachine("b", "b", true), newMachine("a", "a", true)},
+		controllerDryRun: true,
+		expectedHealthy:  0,
+		expectedExpected: 2,
+		expectedDryRun:   []string{"a", "b"},
+		expectedEvents:   []string{ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition, EventReasonRemediationDryRun, EventReasonRemediationDryRun},
+	}, {
+		name:             "unhealthy machine not owned by a machine set",
+		nodes:            []runtime.Object{healthyNode("a"), unhealthyNode("b")},
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", false)},
+		expectedHealthy:  1,
+		expectedExpected: 2,
+		expectedEvents:   []string{ReasonUnhealthyNodeCondition, EventReasonRemediationSkipped},
+	}, {
+		name:               "more unhealthy machines than allowed",
+		nodes:              []runtime.Object{healthyNode("a"), unhealthyNode("b"), unhealthyNode("c")},
+		machines:           []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true), newMachine("c", "c", true)},
+		maxUnhealthy:       &maxOne,
+		expectedHealthy:    1,
+		expectedExpected:   3,
+		expectedEvents:     []string{ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition, EventReasonRemediationRestricted},
+		expectedRestricted: true,
+	}, {
+		name:               "remediation still restricted",
+		nodes:              []runtime.Object{healthyNode("a"), unhealthyNode("b"), unhealthyNode("c")},
+		machines:           []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true), newMachine("c", "c", true)},
+		maxUnhealthy:       &maxOne,
+		wasRestricted:      true,
+		expectedHealthy:    1,
+		expectedExpected:   3,
+		expectedEvents:     []string{ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition},
+		expectedRestricted: true,
+	}, {
+		name:             "remediation resumed",
+		nodes:            []runtime.Object{healthyNode("a"), healthyNode("b"), unhealthyNode("c")},
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true), newMachine("c", "c", true)},
+		maxUnhealthy:     &maxOne,
+		wasRestricted:    true,
+		expectedDeleted:  []string{"c"},
+		expectedHealthy:  2,
+		expectedExpected: 3,
+		expectedEvents:   []string{ReasonUnhealthyNodeCondition, EventReasonRemediationResumed, EventReasonNodeDrainStarted, EventReasonMachineDeleted},
+	}, {
+		name:             "deletion violating the disruption budget",
+		nodes:            []runtime.Object{healthyNode("a"), unhealthyNode("b")},
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true)},
+		mdbs:             []runtime.Object{newMachineDisruptionBudget(1, 2)},
+		expectedHealthy:  1,
+		expectedExpected: 2,
+		expectedEvents:   []string{ReasonUnhealthyNodeCondition, EventReasonDisruptionBudgetViolated},
+	}, {
+		name:             "deletion honoring the disruption budget",
+		nodes:            []runtime.Object{healthyNode("a"), unhealthyNode("b")},
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true)},
+		mdbs:             []runtime.Object{newMachineDisruptionBudget(1, 1)},
+		expectedDeleted:  []string{"b"},
+		expectedHealthy:  1,
+		expectedExpected: 2,
+		expectedEvents:   []string{ReasonUnhealthyNodeCondition, EventReasonNodeDrainStarted, EventReasonMachineDeleted},
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(tc.maxUnhealthy)
+		mhc.Spec.NodeStartupTimeout = tc.nodeStartupTimeout
+		mhc.Spec.DryRun = tc.dryRun
+		if tc.wasRestricted {
+			mhc.Status.Conditions = []healthcheckingv1alpha1.MachineHealthCheckCondition{{
+				Type:   healthcheckingv1alpha1.RemediationAllowed,
+				Status: corev1.ConditionFalse,
+			}}
+		}
+		c, recorder := newFakeController(t, tc.nodes, tc.machines, append([]runtime.Object{mhc}, tc.mdbs...), stopCh)
+		c.now = func() time.Time { return now }
+		c.dryRun = tc.controllerDryRun
+
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		deleted := []string{}
+		for _, obj := range tc.machines {
+			name := obj.(*unstructured.Unstructured).GetName()
+			_, err := c.machineClient.Resource(machine.Resource).Namespace(namespace).Get(name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				deleted = append(deleted, name)
+			}
+		}
+		if len(deleted) != len(tc.expectedDeleted) || (len(deleted) > 0 && strings.Join(deleted, ",") != strings.Join(tc.expectedDeleted, ",")) {
+			t.Errorf("%s: expected deleted machines %v, got %v", tc.name, tc.expectedDeleted, deleted)
+		}
+
+		updated, err := c.mhcClient.HealthcheckingV1alpha1().MachineHealthChecks(namespace).Get(mhcName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get machine health check: %v", tc.name, err)
+		}
+		if updated.Status.ExpectedMachines == nil || *updated.Status.ExpectedMachines != tc.expectedExpected {
+			t.Errorf("%s: expected %d expected machines, got %v", tc.name, tc.expectedExpected, updated.Status.ExpectedMachines)
+		}
+		if updated.Status.CurrentHealthy == nil || *updated.Status.CurrentHealthy != tc.expectedHealthy {
+			t.Errorf("%s: expected %d healthy machines, got %v", tc.name, tc.expectedHealthy, updated.Status.CurrentHealthy)
+		}
+		dryRun := []string{}
+		for _, remediation := range updated.Status.DryRunRemediations {
+			dryRun = append(dryRun, remediation.MachineName)
+		}
+		if strings.Join(dryRun, ",") != strings.Join(tc.expectedDryRun, ",") {
+			t.Errorf("%s: expected dry-run remediations %v, got %v", tc.name, tc.expectedDryRun, dryRun)
+		}
+		condition := findCondition(updated.Status.Conditions, healthcheckingv1alpha1.RemediationAllowed)
+		if condition == nil {
+			t.Errorf("%s: expected RemediationAllowed condition", tc.name)
+		} else if restricted := condition.Status == corev1.ConditionFalse; restricted != tc.expectedRestricted {
+			t.Errorf("%s: expected restricted remediation %t, got condition %v", tc.name, tc.expectedRestricted, condition)
+		}
+
+		if reasons := events(recorder); strings.Join(reasons, ",") != strings.Join(tc.expectedEvents, ",") {
+			t.Errorf("%s: expected events %v, got %v", tc.name, tc.expectedEvents, reasons)
+		}
+		close(stopCh)
+	}
+}
+
+func TestNodeEventEnqueuesMachineHealthCheck(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	node := newNode("a", corev1.ConditionTrue, time.Now())
+	c, _ := newFakeController(t, []runtime.Object{node}, []runtime.Object{newMachine("a", "a", true)}, []runtime.Object{newMachineHealthCheck(nil)}, stopCh)
+
+	// drain the keys added by the informers
+	for c.queue.Len() > 0 {
+		key, _ := c.queue.Get()
+		c.queue.Done(key)
+		c.queue.Forget(key)
+	}
+
+	c.nodeEvent(node)
+	if c.queue.Len() != 1 {
+		t.Fatalf("Expected the machine health check to be enqueued, got %d keys", c.queue.Len())
+	}
+	if key, _ := c.queue.Get(); key != namespace+"/"+mhcName {
+		t.Errorf("Expected key %q, got %q", namespace+"/"+mhcName, key)
+	}
+}
+
+func TestUnhealthyMachineReportedOnce(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	mhc := newMachineHealthCheck(nil)
+	mhc.Spec.DryRun = true
+	node := newNode("a", corev1.ConditionUnknown, time.Now().Add(-10*time.Minute))
+	c, recorder := newFakeController(t, []runtime.Object{node}, []runtime.Object{newMachine("a", "a", true)}, []runtime.Object{mhc}, stopCh)
+
+	for i, expected := range []int{1, 0} {
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Fatalf("sync %d: failed to sync: %v", i, err)
+		}
+		reported := 0
+		for _, reason := range events(recorder) {
+			if reason == ReasonUnhealthyNodeCondition {
+				reported++
+			}
+		}
+		if reported != expected {
+			t.Errorf("sync %d: expected %d unhealthy machine events, got %d", i, expected, reported)
+		}
+	}
+
+	// the unhealthy machines of a deleted machine health check are forgotten
+	c.unhealthyReports.update(namespace+"/deleted", map[string]string{namespace + "/a": ReasonUnhealthyNodeCondition})
+	if err := c.sync(namespace + "/deleted"); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if _, ok := c.unhealthyReports.reasons[namespace+"/deleted"]; ok {
+		t.Errorf("expected the unhealthy machines of the deleted machine health check to be forgotten")
+	}
+}
+
+func TestInvalidSpec(t *testing.T) {
+	tests := []struct {
+		name            string
+		expressions     []healthcheckingv1alpha1.UnhealthyExpression
+		expectedStatus  corev1.ConditionStatus
+		expectedMessage string
+		expectedEvents  []string
+	}{{
+		name:           "valid spec",
+		expressions:    []healthcheckingv1alpha1.UnhealthyExpression{{Name: "pressure", Expression: `condition("DiskPressure") == "True"`}},
+		expectedStatus: corev1.ConditionTrue,
+		expectedEvents: []string{},
+	}, {
+		name:            "expression with a syntax error",
+		expressions:     []healthcheckingv1alpha1.UnhealthyExpression{{Name: "pressure", Expression: `condition("DiskPressure") = "True"`}},
+		expectedStatus:  corev1.ConditionFalse,
+		expectedMessage: `spec.unhealthyExpressions[0].expression: Invalid value: "condition(\"DiskPressure\") = \"True\"": column 27: unexpected character '=', did you mean "=="?`,
+		expectedEvents:  []string{EventReasonInvalidSpec},
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.UnhealthyExpressions = tc.expressions
+		node := newNode("a", corev1.ConditionTrue, time.Now())
+		c, recorder := newFakeController(t, []runtime.Object{node}, []runtime.Object{newMachine("a", "a", true)}, []runtime.Object{mhc}, stopCh)
+
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		updated, err := c.mhcClient.HealthcheckingV1alpha1().MachineHealthChecks(namespace).Get(mhcName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get machine health check: %v", tc.name, err)
+		}
+		var condition *healthcheckingv1alpha1.MachineHealthCheckCondition
+		for i := range updated.Status.Conditions {
+			if updated.Status.Conditions[i].Type == healthcheckingv1alpha1.SpecValid {
+				condition = &updated.Status.Conditions[i]
+			}
+		}
+		if condition == nil || condition.Status != tc.expectedStatus || condition.Message != tc.expectedMessage {
+			t.Errorf("%s: expected condition %s %s with message %q, got %v", tc.name, healthcheckingv1alpha1.SpecValid, tc.expectedStatus, tc.expectedMessage, condition)
+		}
+
+		if reasons := events(recorder); strings.Join(reasons, ",") != strings.Join(tc.expectedEvents, ",") {
+			t.Errorf("%s: expected events %v, got %v", tc.name, tc.expectedEvents, reasons)
+		}
+
+		// the invalid spec is reported once
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		if err := indexer.Add(updated); err != nil {
+			t.Fatalf("%s: failed to index machine health check: %v", tc.name, err)
+		}
+		c.mhcLister = healthcheckinglistersv1alpha1.NewMachineHealthCheckLister(indexer)
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync again: %v", tc.name, err)
+		}
+		if reasons := events(recorder); len(reasons) > 0 {
+			t.Errorf("%s: expected no events once reported, got %v", tc.name, reasons)
+		}
+		close(stopCh)
+	}
+}
@@ -0,0 +1,94 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machinedisruptionbudget"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// controlPlane tracks the control plane machines of a namespace during a single sync. Every
+// control plane machine runs an etcd member, so at most one of them is remediated at a time
+// and only when the remaining healthy members keep the etcd quorum.
+type controlPlane struct {
+	// members is the number of the control plane machines
+	members int
+	// healthy contains the names of the healthy control plane machines
+	healthy map[string]bool
+	// remediating contains the names of the control plane machines being remediated
+	remediating []string
+}
+
+func (c *Controller) getControlPlane(namespace string) (*controlPlane, error) {
+	objs, err := c.machineLister.ByNamespace(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	cp := &controlPlane{healthy: map[string]bool{}}
+	for _, obj := range objs {
+		m, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected machine type %T", obj)
+		}
+		var node *corev1.Node
+		if name := machine.NodeName(m); name != "" {
+			node, err = c.nodeLister.Get(name)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+		}
+		if !machine.IsControlPlane(m, node) {
+			continue
+		}
+		cp.members++
+		if machinedisruptionbudget.IsMachineHealthy(m, node) {
+			cp.healthy[m.GetName()] = true
+		}
+		if isBeingRemediated(m) {
+			cp.remediating = append(cp.remediating, m.GetName())
+		}
+	}
+	return cp, nil
+}
+
+// isBeingRemediated returns true when the machine is being deleted, drained or remediated by any of the strategies
+func isBeingRemediated(m *unstructured.Unstructured) bool {
+	if m.GetDeletionTimestamp() != nil {
+		return true
+	}
+	annotations := m.GetAnnotations()
+	_, reboot := annotations[healthcheckingv1alpha1.RebootAnnotation]
+	_, external := annotations[healthcheckingv1alpha1.ExternalRemediationAnnotation]
+	_, drain := annotations[healthcheckingv1alpha1.DrainStartedAnnotation]
+	return reboot || external || drain
+}
+
+// violation returns the reason why remediating the target control plane machine is refused,
+// or an empty string when the machine can be remediated.
+func (cp *controlPlane) violation(t *target) string {
+	for _, name := range cp.remediating {
+		if name != t.Machine.GetName() {
+			return fmt.Sprintf("control plane machine %s is being remediated", name)
+		}
+	}
+	remaining := len(cp.healthy)
+	if cp.healthy[t.Machine.GetName()] {
+		remaining--
+	}
+	if quorum := cp.members/2 + 1; remaining < quorum {
+		return fmt.Sprintf("etcd quorum requires %d of %d members, %d healthy would remain", quorum, cp.members, remaining)
+	}
+	return ""
+}
+
+// disrupt records the remediated target control plane machine, so no other one is remediated during the sync
+func (cp *controlPlane) disrupt(t *target) {
+	delete(cp.healthy, t.Machine.GetName())
+	cp.remediating = append(cp.remediating, t.Machine.GetName())
+}
@@ -0,0 +1,123 @@
+package machinehealthcheck
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newControlPlaneMachine(name string) *unstructured.Unstructured {
+	m := newMachine(name, name, false)
+	m.SetLabels(map[string]string{machine.RoleLabel: machine.ControlPlaneRole})
+	return m
+}
+
+func TestControlPlaneRemediation(t *testing.T) {
+	now := time.Now()
+	unhealthyNode := func(name string) runtime.Object {
+		return newNode(name, corev1.ConditionUnknown, now.Add(-10*time.Minute))
+	}
+	healthyNode := func(name string) runtime.Object {
+		return newNode(name, corev1.ConditionTrue, now.Add(-10*time.Minute))
+	}
+	controlPlaneNode := func(name string) runtime.Object {
+		node := newNode(name, corev1.ConditionUnknown, now.Add(-10*time.Minute))
+		node.Labels = map[string]string{machine.ControlPlaneNodeLabel: ""}
+		return node
+	}
+	rebooting := func(m *unstructured.Unstructured) *unstructured.Unstructured {
+		return withAnnotation(m, healthcheckingv1alpha1.RebootAnnotation)
+	}
+
+	tests := []struct {
+		name                  string
+		nodes                 []runtime.Object
+		machines              []runtime.Object
+		remediateControlPlane bool
+		// expectedRebooted is the number of the machines with the reboot annotation after the sync
+		expectedRebooted int
+		expectedEvents   []string
+	}{{
+		name:           "control plane remediation not enabled",
+		nodes:          []runtime.Object{healthyNode("a"), healthyNode("b"), unhealthyNode("c")},
+		machines:       []runtime.Object{newControlPlaneMachine("a"), newControlPlaneMachine("b"), newControlPlaneMachine("c")},
+		expectedEvents: []string{EventReasonRemediationSkipped, ReasonUnhealthyNodeCondition},
+	}, {
+		name:           "control plane recognized by the node role",
+		nodes:          []runtime.Object{controlPlaneNode("a")},
+		machines:       []runtime.Object{newMachine("a", "a", false)},
+		expectedEvents: []string{EventReasonRemediationSkipped, ReasonUnhealthyNodeCondition},
+	}, {
+		name:                  "etcd quorum kept",
+		nodes:                 []runtime.Object{healthyNode("a"), healthyNode("b"), unhealthyNode("c")},
+		machines:              []runtime.Object{newControlPlaneMachine("a"), newControlPlaneMachine("b"), newControlPlaneMachine("c")},
+		remediateControlPlane: true,
+		expectedRebooted:      1,
+		expectedEvents:        []string{EventReasonMachineRebootRequested, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition},
+	}, {
+		name:                  "etcd quorum lost",
+		nodes:                 []runtime.Object{healthyNode("a"), unhealthyNode("b"), unhealthyNode("c")},
+		machines:              []runtime.Object{newControlPlaneMachine("a"), newControlPlaneMachine("b"), newControlPlaneMachine("c")},
+		remediateControlPlane: true,
+		expectedEvents:        []string{EventReasonControlPlaneProtected, EventReasonControlPlaneProtected, ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition},
+	}, {
+		name:                  "another control plane machine being remediated",
+		nodes:                 []runtime.Object{healthyNode("a"), healthyNode("b"), healthyNode("c"), healthyNode("d"), unhealthyNode("e")},
+		machines:              []runtime.Object{rebooting(newControlPlaneMachine("a")), newControlPlaneMachine("b"), newControlPlaneMachine("c"), newControlPlaneMachine("d"), newControlPlaneMachine("e")},
+		remediateControlPlane: true,
+		expectedRebooted:      1,
+		expectedEvents:        []string{EventReasonControlPlaneProtected, ReasonUnhealthyNodeCondition},
+	}, {
+		name:                  "one control plane machine at a time",
+		nodes:                 []runtime.Object{healthyNode("a"), healthyNode("b"), healthyNode("c"), unhealthyNode("d"), unhealthyNode("e")},
+		machines:              []runtime.Object{newControlPlaneMachine("a"), newControlPlaneMachine("b"), newControlPlaneMachine("c"), newControlPlaneMachine("d"), newControlPlaneMachine("e")},
+		remediateControlPlane: true,
+		expectedRebooted:      1,
+		expectedEvents:        []string{EventReasonControlPlaneProtected, EventReasonMachineRebootRequested, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition},
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.Selector = metav1.LabelSelector{}
+		mhc.Spec.RemediationStrategy = healthcheckingv1alpha1.RemediationStrategyReboot
+		mhc.Spec.RemediateControlPlane = tc.remediateControlPlane
+		c, recorder := newFakeController(t, tc.nodes, tc.machines, []runtime.Object{mhc}, stopCh)
+		c.now = func() time.Time { return now }
+
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		rebooted := 0
+		for _, obj := range tc.machines {
+			m, err := c.machineClient.Resource(machine.Resource).Namespace(namespace).Get(obj.(*unstructured.Unstructured).GetName(), metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("%s: failed to get machine: %v", tc.name, err)
+			}
+			if _, ok := m.GetAnnotations()[healthcheckingv1alpha1.RebootAnnotation]; ok {
+				rebooted++
+			}
+		}
+		if rebooted != tc.expectedRebooted {
+			t.Errorf("%s: expected %d rebooted machines, got %d", tc.name, tc.expectedRebooted, rebooted)
+		}
+
+		// the machines are checked in a random order
+		reasons := events(recorder)
+		sort.Strings(reasons)
+		if strings.Join(reasons, ",") != strings.Join(tc.expectedEvents, ",") {
+			t.Errorf("%s: expected events %v, got %v", tc.name, tc.expectedEvents, reasons)
+		}
+		close(stopCh)
+	}
+}
@@ -0,0 +1,175 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// EventReasonNodeDrainStarted is the reason of the event reporting a cordoned node of an unhealthy machine
+	EventReasonNodeDrainStarted = "NodeDrainStarted"
+	// EventReasonNodeDrainBlocked is the reason of the event reporting the pods whose eviction is refused by a pod disruption budget
+	EventReasonNodeDrainBlocked = "NodeDrainBlocked"
+	// EventReasonNodeDrainTimeout is the reason of the event reporting the pods deleted once the drain timed out
+	EventReasonNodeDrainTimeout = "NodeDrainTimeout"
+	// EventReasonNodeUncordoned is the reason of the event reporting an uncordoned node of a recovered machine
+	EventReasonNodeUncordoned = "NodeUncordoned"
+)
+
+const (
+	// drainCheckInterval is the interval the pods of a node being drained are evicted again
+	drainCheckInterval = 10 * time.Second
+	// mirrorPodAnnotation is set on the mirror pods of the static pods, they can not be evicted
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+// drain cordons the node of the unhealthy target and evicts its pods honoring the pod disruption
+// budgets. It returns true once the node is drained, or once the drain timed out and the remaining
+// pods were deleted, the drain is continued by the next syncs until then.
+func (c *Controller) drain(mhc *healthcheckingv1alpha1.MachineHealthCheck, t *target) (bool, error) {
+	if t.Node == nil || mhc.Spec.DrainTimeout == nil || mhc.Spec.DrainTimeout.Duration == 0 ||
+		mhc.Spec.RemediationStrategy == healthcheckingv1alpha1.RemediationStrategyExternal {
+		return true, nil
+	}
+
+	now := c.now()
+	started, err := drainStarted(t.Machine.GetAnnotations())
+	if err != nil {
+		glog.Warningf("Machine %s has invalid %s annotation, restarting drain: %v", t, healthcheckingv1alpha1.DrainStartedAnnotation, err)
+	}
+	if started.IsZero() {
+		if err := c.setUnschedulable(t.Node.Name, true); err != nil {
+			return false, fmt.Errorf("error cordoning node %s: %v", t.Node.Name, err)
+		}
+		if err := setMachineAnnotation(c.machineClient, t.Machine, healthcheckingv1alpha1.DrainStartedAnnotation, now.UTC().Format(time.RFC3339)); err != nil {
+			return false, fmt.Errorf("error annotating machine %s: %v", t, err)
+		}
+		glog.Infof("Draining node %s of unhealthy machine %s", t.Node.Name, t)
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeNormal, EventReasonNodeDrainStarted, "Cordoned node %s of unhealthy machine %s, evicting its pods", t.Node.Name, t)
+		started = now
+	}
+
+	pods, err := c.podsToEvict(t.Node.Name)
+	if err != nil {
+		return false, err
+	}
+	if len(pods) == 0 {
+		glog.V(3).Infof("Node %s of machine %s is drained", t.Node.Name, t)
+		return true, nil
+	}
+
+	// the node is unhealthy, so the pods are deleted without waiting for them once the drain times out
+	elapsed := now.Sub(started)
+	if elapsed >= mhc.Spec.DrainTimeout.Duration {
+		deleted := []string{}
+		for _, pod := range pods {
+			err := c.kubeClient.CoreV1().Pods(pod.Namespace).Delete(pod.Name, &metav1.DeleteOptions{GracePeriodSeconds: pointer.Int64Ptr(0)})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return false, fmt.Errorf("error deleting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+			deleted = append(deleted, pod.Namespace+"/"+pod.Name)
+		}
+		glog.Warningf("Draining node %s of machine %s timed out after %s, deleted pods %s", t.Node.Name, t, mhc.Spec.DrainTimeout.Duration, strings.Join(deleted, ", "))
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonNodeDrainTimeout, "Draining node %s of machine %s timed out after %s, deleted pods %s", t.Node.Name, t, mhc.Spec.DrainTimeout.Duration, strings.Join(deleted, ", "))
+		return true, nil
+	}
+
+	blocked := []string{}
+	for _, pod := range pods {
+		// the evicted pods are terminating
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		err := c.kubeClient.PolicyV1beta1().Evictions(pod.Namespace).Evict(&policyv1beta1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		})
+		switch {
+		case apierrors.IsTooManyRequests(err):
+			blocked = append(blocked, pod.Namespace+"/"+pod.Name)
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return false, fmt.Errorf("error evicting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	if len(blocked) > 0 {
+		glog.Warningf("Draining node %s of machine %s is blocked by pods %s", t.Node.Name, t, strings.Join(blocked, ", "))
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonNodeDrainBlocked, "Draining node %s of machine %s is blocked by the disruption budgets of pods %s", t.Node.Name, t, strings.Join(blocked, ", "))
+	}
+
+	next := drainCheckInterval
+	if remaining := mhc.Spec.DrainTimeout.Duration - elapsed; remaining < next {
+		next = remaining
+	}
+	if key, err := cache.MetaNamespaceKeyFunc(mhc); err == nil {
+		c.queue.AddAfter(key, next)
+	}
+	return false, nil
+}
+
+// uncordon uncordons the node of the recovered target drained before its remediation
+func (c *Controller) uncordon(mhc *healthcheckingv1alpha1.MachineHealthCheck, t *target) error {
+	if _, ok := t.Machine.GetAnnotations()[healthcheckingv1alpha1.DrainStartedAnnotation]; !ok {
+		return nil
+	}
+	if t.Node != nil && t.Node.Spec.Unschedulable {
+		if err := c.setUnschedulable(t.Node.Name, false); err != nil {
+			return fmt.Errorf("error uncordoning node %s: %v", t.Node.Name, err)
+		}
+		glog.Infof("Uncordoned node %s of recovered machine %s", t.Node.Name, t)
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeNormal, EventReasonNodeUncordoned, "Uncordoned node %s of recovered machine %s", t.Node.Name, t)
+	}
+	return removeMachineAnnotation(c.machineClient, t.Machine, healthcheckingv1alpha1.DrainStartedAnnotation)
+}
+
+// podsToEvict returns the pods of the node that have to be evicted, the daemon set and mirror
+// pods are not evicted and the completed pods do not have to be
+func (c *Controller) podsToEvict(nodeName string) ([]corev1.Pod, error) {
+	list, err := c.kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods of node %s: %v", nodeName, err)
+	}
+	pods := []corev1.Pod{}
+	for _, pod := range list.Items {
+		if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if ref := metav1.GetControllerOf(&pod); ref != nil && ref.Kind == "DaemonSet" {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+func (c *Controller) setUnschedulable(nodeName string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	_, err := c.kubeClient.CoreV1().Nodes().Patch(nodeName, types.StrategicMergePatchType, []byte(patch))
+	return err
+}
+
+// drainStarted returns the time the drain started from the machine annotations, it is zero when the drain did not start
+func drainStarted(annotations map[string]string) (time.Time, error) {
+	value, ok := annotations[healthcheckingv1alpha1.DrainStartedAnnotation]
+	if !ok {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,145 @@
+package machinehealthcheck
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+)
+
+func newPod(name, nodeName, ownerKind string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec:   corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if ownerKind != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1",
+			Kind:       ownerKind,
+			Name:       name,
+			Controller: pointer.BoolPtr(true),
+		}}
+	}
+	return pod
+}
+
+func TestDrain(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	unhealthyNode := newNode("b", corev1.ConditionUnknown, now.Add(-10*time.Minute))
+	cordonedNode := newNode("b", corev1.ConditionTrue, now.Add(-10*time.Minute))
+	cordonedNode.Spec.Unschedulable = true
+	mirrorPod := newPod("mirror", "b", "")
+	mirrorPod.Annotations = map[string]string{mirrorPodAnnotation: ""}
+
+	tests := []struct {
+		name string
+		node *corev1.Node
+		pods []runtime.Object
+		// drainStarted sets the drain started annotation of the machine
+		drainStarted          time.Time
+		blockEvictions        bool
+		expectedDeleted       bool
+		expectedPods          int
+		expectedUnschedulable bool
+		expectedEvents        []string
+	}{{
+		name:                  "drain started",
+		node:                  unhealthyNode,
+		pods:                  []runtime.Object{newPod("web", "b", "ReplicaSet")},
+		expectedPods:          1,
+		expectedUnschedulable: true,
+		expectedEvents:        []string{ReasonUnhealthyNodeCondition, EventReasonNodeDrainStarted},
+	}, {
+		name:           "eviction blocked by a disruption budget",
+		node:           unhealthyNode,
+		pods:           []runtime.Object{newPod("web", "b", "ReplicaSet")},
+		drainStarted:   now.Add(-time.Minute),
+		blockEvictions: true,
+		expectedPods:   1,
+		expectedEvents: []string{ReasonUnhealthyNodeCondition, EventReasonNodeDrainBlocked},
+	}, {
+		name:            "drain timed out",
+		node:            unhealthyNode,
+		pods:            []runtime.Object{newPod("web", "b", "ReplicaSet")},
+		drainStarted:    now.Add(-10 * time.Minute),
+		blockEvictions:  true,
+		expectedDeleted: true,
+		expectedEvents:  []string{ReasonUnhealthyNodeCondition, EventReasonNodeDrainTimeout, EventReasonMachineDeleted},
+	}, {
+		name:            "node drained",
+		node:            unhealthyNode,
+		pods:            []runtime.Object{newPod("logging", "b", "DaemonSet"), mirrorPod},
+		drainStarted:    now.Add(-time.Minute),
+		expectedDeleted: true,
+		expectedPods:    2,
+		expectedEvents:  []string{ReasonUnhealthyNodeCondition, EventReasonMachineDeleted},
+	}, {
+		name:           "recovered machine uncordoned",
+		node:           cordonedNode,
+		drainStarted:   now.Add(-time.Minute),
+		expectedEvents: []string{EventReasonNodeUncordoned},
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		m := newMachine("b", "b", true)
+		if !tc.drainStarted.IsZero() {
+			m.SetAnnotations(map[string]string{healthcheckingv1alpha1.DrainStartedAnnotation: tc.drainStarted.UTC().Format(time.RFC3339)})
+		}
+		c, recorder := newFakeController(t, append([]runtime.Object{tc.node}, tc.pods...), []runtime.Object{m}, []runtime.Object{newMachineHealthCheck(nil)}, stopCh)
+		c.now = func() time.Time { return now }
+		// the evicted pods are terminated by their kubelet, so they are not removed by the eviction
+		c.kubeClient.(*fakekube.Clientset).PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "eviction" {
+				return false, nil, nil
+			}
+			if tc.blockEvictions {
+				return true, nil, apierrors.NewTooManyRequests("cannot evict pod as it would violate the pod's disruption budget", 10)
+			}
+			return true, nil, nil
+		})
+
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		_, err := c.machineClient.Resource(machine.Resource).Namespace(namespace).Get(m.GetName(), metav1.GetOptions{})
+		if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+			t.Errorf("%s: expected deleted machine %t, got %t", tc.name, tc.expectedDeleted, deleted)
+		}
+
+		node, err := c.kubeClient.CoreV1().Nodes().Get(tc.node.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get node: %v", tc.name, err)
+		}
+		if node.Spec.Unschedulable != tc.expectedUnschedulable {
+			t.Errorf("%s: expected unschedulable node %t, got %t", tc.name, tc.expectedUnschedulable, node.Spec.Unschedulable)
+		}
+		pods, err := c.kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to list pods: %v", tc.name, err)
+		}
+		if len(pods.Items) != tc.expectedPods {
+			t.Errorf("%s: expected %d pods, got %d", tc.name, tc.expectedPods, len(pods.Items))
+		}
+
+		if reasons := events(recorder); strings.Join(reasons, ",") != strings.Join(tc.expectedEvents, ",") {
+			t.Errorf("%s: expected events %v, got %v", tc.name, tc.expectedEvents, reasons)
+		}
+		close(stopCh)
+	}
+}
@@ -0,0 +1,137 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// maxTrackedTransitions is the number of the latest transitions tracked for every node condition
+	maxTrackedTransitions = 32
+)
+
+// transitionTracker records the transitions of the node conditions observed by the controller. The
+// node conditions only contain their last transition, so they are observed on every node update. The
+// transitions are kept in memory and they are tracked again from scratch once the controller restarts.
+type transitionTracker struct {
+	lock        sync.Mutex
+	transitions map[string]map[corev1.NodeConditionType][]time.Time
+}
+
+func newTransitionTracker() *transitionTracker {
+	return &transitionTracker{transitions: map[string]map[corev1.NodeConditionType][]time.Time{}}
+}
+
+// observe records the last transitions of the node conditions that were not recorded yet
+func (tt *transitionTracker) observe(node *corev1.Node) {
+	tt.lock.Lock()
+	defer tt.lock.Unlock()
+	conditions := tt.transitions[node.Name]
+	if conditions == nil {
+		conditions = map[corev1.NodeConditionType][]time.Time{}
+		tt.transitions[node.Name] = conditions
+	}
+	for _, condition := range node.Status.Conditions {
+		transition := condition.LastTransitionTime.Time
+		if transition.IsZero() {
+			continue
+		}
+		times := conditions[condition.Type]
+		if len(times) > 0 && !transition.After(times[len(times)-1]) {
+			continue
+		}
+		times = append(times, transition)
+		if len(times) > maxTrackedTransitions {
+			times = times[len(times)-maxTrackedTransitions:]
+		}
+		conditions[condition.Type] = times
+	}
+}
+
+// get returns a copy of the transitions recorded for the conditions of the node
+func (tt *transitionTracker) get(nodeName string) map[corev1.NodeConditionType][]time.Time {
+	tt.lock.Lock()
+	defer tt.lock.Unlock()
+	transitions := map[corev1.NodeConditionType][]time.Time{}
+	for conditionType, times := range tt.transitions[nodeName] {
+		transitions[conditionType] = append([]time.Time(nil), times...)
+	}
+	return transitions
+}
+
+// forget drops the transitions of the deleted node
+func (tt *transitionTracker) forget(nodeName string) {
+	tt.lock.Lock()
+	defer tt.lock.Unlock()
+	delete(tt.transitions, nodeName)
+}
+
+// isFlapping evaluates the transitions of the node conditions of the target against the flapping
+// policy. A node is flapping when one of the condition types of the unhealthy conditions transitioned
+// at least maxTransitions times within the window. Once flapping, the node is considered flapping
+// until the condition does not transition for the recovery period, so it does not become healthy
+// right after the transitions fall out of the window. The flapping node is checked again after the
+// returned duration, once the transitions may fall out of the window or the recovery period expires.
+func (t *target) isFlapping(policy *healthcheckingv1alpha1.FlappingPolicy, conditions []healthcheckingv1alpha1.UnhealthyCondition, now time.Time) (*unhealthyReason, time.Duration) {
+	if policy == nil || t.Node == nil {
+		return nil, 0
+	}
+	checked := map[corev1.NodeConditionType]bool{}
+	for _, c := range conditions {
+		if checked[c.Type] {
+			continue
+		}
+		checked[c.Type] = true
+
+		times := t.transitions[c.Type]
+		if len(times) == 0 {
+			continue
+		}
+		recovered := times[len(times)-1].Add(policy.RecoveryPeriod.Duration).Sub(now)
+		if n := transitionsWithin(times, now.Add(-policy.Window.Duration), now); int32(n) >= policy.MaxTransitions {
+			// the number of the transitions within the window drops below the maximum once the
+			// oldest of the latest maxTransitions transitions falls out of it
+			recheck := times[len(times)-int(policy.MaxTransitions)].Add(policy.Window.Duration).Sub(now)
+			if recovered > 0 && recovered < recheck {
+				recheck = recovered
+			}
+			return &unhealthyReason{
+				Reason:  ReasonNodeFlapping,
+				Message: fmt.Sprintf("condition %s transitioned %d times within %s", c.Type, n, policy.Window.Duration),
+			}, recheck
+		}
+		if recovered <= 0 {
+			continue
+		}
+		// the transitions separated by less than the recovery period belong to the same flapping
+		first := len(times) - 1
+		for first > 0 && times[first].Sub(times[first-1]) < policy.RecoveryPeriod.Duration {
+			first--
+		}
+		for _, end := range times[first:] {
+			if int32(transitionsWithin(times[first:], end.Add(-policy.Window.Duration), end)) >= policy.MaxTransitions {
+				return &unhealthyReason{
+					Reason:  ReasonNodeFlapping,
+					Message: fmt.Sprintf("condition %s was flapping and did not stay unchanged for %s yet", c.Type, policy.RecoveryPeriod.Duration),
+				}, recovered
+			}
+		}
+	}
+	return nil, 0
+}
+
+// transitionsWithin returns the number of the sorted transitions after the start and not after the end
+func transitionsWithin(times []time.Time, start, end time.Time) int {
+	n := 0
+	for _, transition := range times {
+		if transition.After(start) && !transition.After(end) {
+			n++
+		}
+	}
+	return n
+}
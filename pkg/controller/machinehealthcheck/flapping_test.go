@@ -0,0 +1,120 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTransitionTracker(t *testing.T) {
+	now := time.Now()
+	tracker := newTransitionTracker()
+
+	tracker.observe(newNode("node", corev1.ConditionTrue, now.Add(-time.Minute)))
+	tracker.observe(newNode("node", corev1.ConditionTrue, now.Add(-time.Minute)))
+	if n := len(tracker.get("node")[corev1.NodeReady]); n != 1 {
+		t.Errorf("expected the same transition to be recorded once, got %d transitions", n)
+	}
+
+	tracker.observe(newNode("node", corev1.ConditionFalse, now))
+	tracker.observe(newNode("node", corev1.ConditionTrue, now.Add(-time.Hour)))
+	if n := len(tracker.get("node")[corev1.NodeReady]); n != 2 {
+		t.Errorf("expected the older transition to be ignored, got %d transitions", n)
+	}
+
+	for i := 1; i <= 2*maxTrackedTransitions; i++ {
+		tracker.observe(newNode("node", corev1.ConditionTrue, now.Add(time.Duration(i)*time.Second)))
+	}
+	transitions := tracker.get("node")[corev1.NodeReady]
+	if len(transitions) != maxTrackedTransitions {
+		t.Errorf("expected %d tracked transitions, got %d", maxTrackedTransitions, len(transitions))
+	}
+	if last := now.Add(2 * maxTrackedTransitions * time.Second); !transitions[len(transitions)-1].Equal(last) {
+		t.Errorf("expected the latest transition %s to be tracked, got %s", last, transitions[len(transitions)-1])
+	}
+
+	tracker.forget("node")
+	if n := len(tracker.get("node")); n != 0 {
+		t.Errorf("expected the transitions of the deleted node to be forgotten, got %d condition types", n)
+	}
+}
+
+func TestFlapping(t *testing.T) {
+	now := time.Now()
+	spec := &healthcheckingv1alpha1.MachineHealthCheckSpec{
+		UnhealthyConditions: []healthcheckingv1alpha1.UnhealthyCondition{{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		}},
+		Flapping: &healthcheckingv1alpha1.FlappingPolicy{},
+	}
+	healthcheckingv1alpha1.SetDefaultsMachineHealthCheckSpec(spec)
+	// transitions returns the transitions of the Ready condition the given minutes ago
+	transitions := func(minutes ...int) map[corev1.NodeConditionType][]time.Time {
+		times := []time.Time{}
+		for _, m := range minutes {
+			times = append(times, now.Add(-time.Duration(m)*time.Minute))
+		}
+		return map[corev1.NodeConditionType][]time.Time{corev1.NodeReady: times}
+	}
+
+	tests := []struct {
+		name              string
+		transitions       map[corev1.NodeConditionType][]time.Time
+		expectedReason    string
+		expectedNextCheck time.Duration
+	}{{
+		name:        "stable node",
+		transitions: transitions(60),
+	}, {
+		name:        "transitions below the maximum",
+		transitions: transitions(6, 4, 2),
+	}, {
+		name:              "flapping within the window",
+		transitions:       transitions(8, 6, 4, 2),
+		expectedReason:    ReasonNodeFlapping,
+		expectedNextCheck: 2 * time.Minute,
+	}, {
+		name:              "flapping within the recovery period",
+		transitions:       transitions(14, 13, 12, 11, 9, 7, 4),
+		expectedReason:    ReasonNodeFlapping,
+		expectedNextCheck: time.Minute,
+	}, {
+		name:        "recovered after the recovery period",
+		transitions: transitions(16, 15, 14, 13, 6),
+	}, {
+		name:        "transitions of another condition",
+		transitions: map[corev1.NodeConditionType][]time.Time{corev1.NodeMemoryPressure: transitions(8, 6, 4, 2)[corev1.NodeReady]},
+	}}
+
+	for _, tc := range tests {
+		target := &target{
+			Machine:     newMachine("machine", "node", true),
+			Node:        newNode("node", corev1.ConditionTrue, now.Add(-time.Minute)),
+			transitions: tc.transitions,
+		}
+		reason, nextCheck := target.needsRemediation(spec, now)
+		got := ""
+		if reason != nil {
+			got = reason.Reason
+		}
+		if got != tc.expectedReason {
+			t.Errorf("%s: expected reason %q, got %q", tc.name, tc.expectedReason, got)
+		}
+		if nextCheck != tc.expectedNextCheck {
+			t.Errorf("%s: expected next check in %s, got %s", tc.name, tc.expectedNextCheck, nextCheck)
+		}
+	}
+
+	// the flapping nodes are not detected without a flapping policy
+	spec.Flapping = nil
+	target := &target{Machine: newMachine("machine", "node", true), Node: newNode("node", corev1.ConditionTrue, now), transitions: transitions(8, 6, 4, 2)}
+	if reason, _ := target.needsRemediation(spec, now); reason != nil {
+		t.Errorf("expected flapping node to be healthy without a flapping policy, got %s", reason)
+	}
+}
@@ -0,0 +1,153 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// EventReasonRemediationAttemptsExceeded is the reason of the event reporting an unhealthy machine remediated more times than allowed
+	EventReasonRemediationAttemptsExceeded = "RemediationAttemptsExceeded"
+
+	// conditionReasonAttemptsExceeded is the reason of the RemediationAttemptsExceeded condition set to true
+	conditionReasonAttemptsExceeded = "AttemptsExceeded"
+	// conditionReasonAttemptsWithinLimit is the reason of the RemediationAttemptsExceeded condition set to false
+	conditionReasonAttemptsWithinLimit = "AttemptsWithinLimit"
+)
+
+// remediationHistory tracks the remediations within the window of the remediation retry policy,
+// the remediations of the machines owned by a machine set are counted together.
+type remediationHistory struct {
+	policy  *healthcheckingv1alpha1.RemediationRetryPolicy
+	now     time.Time
+	records []healthcheckingv1alpha1.RemediationRecord
+}
+
+// newRemediationHistory returns the remediation history of the defaulted machine health check
+// without the remediations that fell out of the window
+func newRemediationHistory(mhc *healthcheckingv1alpha1.MachineHealthCheck, now time.Time) *remediationHistory {
+	h := &remediationHistory{policy: mhc.Spec.RemediationRetry, now: now}
+	if h.policy == nil {
+		return h
+	}
+	for _, record := range mhc.Status.RemediationHistory {
+		if now.Sub(record.Time.Time) < h.policy.Window.Duration {
+			h.records = append(h.records, record)
+		}
+	}
+	return h
+}
+
+// historyKey returns the key the remediations of the machine are counted by
+func historyKey(machineName, machineSetName string) string {
+	if machineSetName != "" {
+		return "machine set " + machineSetName
+	}
+	return "machine " + machineName
+}
+
+// attempts returns the remediations counted together with the remediations of the machine
+func (h *remediationHistory) attempts(m *unstructured.Unstructured) []healthcheckingv1alpha1.RemediationRecord {
+	key := historyKey(m.GetName(), machineSetName(m))
+	attempts := []healthcheckingv1alpha1.RemediationRecord{}
+	for _, record := range h.records {
+		if historyKey(record.MachineName, record.MachineSetName) == key {
+			attempts = append(attempts, record)
+		}
+	}
+	return attempts
+}
+
+// allowed returns the reason why remediating the target is not allowed by the retry policy, or
+// an empty string when it is allowed. The backoff is the duration after which the remediation
+// is allowed again, it is zero when the maximum of the attempts was reached.
+func (h *remediationHistory) allowed(t *target) (string, time.Duration) {
+	if h.policy == nil {
+		return "", 0
+	}
+	attempts := h.attempts(t.Machine)
+	if len(attempts) == 0 {
+		return "", 0
+	}
+	if int32(len(attempts)) >= h.policy.MaxAttempts {
+		return fmt.Sprintf("%s was remediated %d times within %s", historyKey(t.Machine.GetName(), machineSetName(t.Machine)), len(attempts), h.policy.Window.Duration), 0
+	}
+
+	last := attempts[0].Time.Time
+	for _, record := range attempts[1:] {
+		if record.Time.After(last) {
+			last = record.Time.Time
+		}
+	}
+	// the backoff doubles up to the window, the attempts fall out of the window afterwards anyway
+	backoff := h.policy.Backoff.Duration
+	for i := 1; i < len(attempts) && backoff < h.policy.Window.Duration; i++ {
+		backoff *= 2
+	}
+	if backoff > h.policy.Window.Duration {
+		backoff = h.policy.Window.Duration
+	}
+	if remaining := backoff - h.now.Sub(last); remaining > 0 {
+		return fmt.Sprintf("%s was remediated %d times, backing off for %s", historyKey(t.Machine.GetName(), machineSetName(t.Machine)), len(attempts), remaining), remaining
+	}
+	return "", 0
+}
+
+// withRecord returns the history with the remediation of the target added, or nil when the
+// remediations are not tracked by the machine health check
+func (h *remediationHistory) withRecord(t *target, reason *unhealthyReason) []healthcheckingv1alpha1.RemediationRecord {
+	if h.policy == nil {
+		return nil
+	}
+	records := make([]healthcheckingv1alpha1.RemediationRecord, len(h.records), len(h.records)+1)
+	copy(records, h.records)
+	return append(records, healthcheckingv1alpha1.RemediationRecord{
+		MachineName:    t.Machine.GetName(),
+		MachineSetName: machineSetName(t.Machine),
+		Reason:         reason.Reason,
+		Time:           metav1.NewTime(h.now),
+	})
+}
+
+// exceeded returns the keys of the machines and machine sets that reached the maximum of the attempts
+func (h *remediationHistory) exceeded() []string {
+	if h.policy == nil {
+		return nil
+	}
+	counts := map[string]int32{}
+	for _, record := range h.records {
+		counts[historyKey(record.MachineName, record.MachineSetName)]++
+	}
+	keys := []string{}
+	for key, count := range counts {
+		if count >= h.policy.MaxAttempts {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// condition returns the RemediationAttemptsExceeded condition reflecting the history
+func (h *remediationHistory) condition() healthcheckingv1alpha1.MachineHealthCheckCondition {
+	condition := healthcheckingv1alpha1.MachineHealthCheckCondition{
+		Type:               healthcheckingv1alpha1.RemediationAttemptsExceeded,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.NewTime(h.now),
+		Reason:             conditionReasonAttemptsWithinLimit,
+	}
+	if exceeded := h.exceeded(); len(exceeded) > 0 {
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = conditionReasonAttemptsExceeded
+		condition.Message = fmt.Sprintf("Remediation stopped, %s reached %d attempts within %s and need attention", strings.Join(exceeded, ", "), h.policy.MaxAttempts, h.policy.Window.Duration)
+	}
+	return condition
+}
@@ -0,0 +1,201 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	fakemhc "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned/fake"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestRemediationRetry(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	remediated := func(machineName string, ago time.Duration) healthcheckingv1alpha1.RemediationRecord {
+		return healthcheckingv1alpha1.RemediationRecord{
+			MachineName:    machineName,
+			MachineSetName: "workers",
+			Reason:         ReasonUnhealthyNodeCondition,
+			Time:           metav1.NewTime(now.Add(-ago)),
+		}
+	}
+	// manyRemediated contains the remediations of 29 machines during the last hour
+	manyRemediated := []healthcheckingv1alpha1.RemediationRecord{}
+	for i := 0; i < 29; i++ {
+		manyRemediated = append(manyRemediated, remediated(fmt.Sprintf("m%d", i), time.Duration(59-i)*time.Minute))
+	}
+
+	tests := []struct {
+		name        string
+		history     []healthcheckingv1alpha1.RemediationRecord
+		maxAttempts int32
+		// expectedDeleted is whether the unhealthy machine is deleted by the sync
+		expectedDeleted   bool
+		expectedHistory   int
+		expectedCondition corev1.ConditionStatus
+		expectedEvents    []string
+	}{{
+		name:              "first remediation",
+		expectedDeleted:   true,
+		expectedHistory:   1,
+		expectedCondition: corev1.ConditionFalse,
+		expectedEvents:    []string{EventReasonMachineDeleted, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition},
+	}, {
+		name:              "backing off",
+		history:           []healthcheckingv1alpha1.RemediationRecord{remediated("a", 30*time.Second)},
+		expectedHistory:   1,
+		expectedCondition: corev1.ConditionFalse,
+		expectedEvents:    []string{ReasonUnhealthyNodeCondition},
+	}, {
+		name:              "backoff doubled",
+		history:           []healthcheckingv1alpha1.RemediationRecord{remediated("a", 10*time.Minute), remediated("b", 90*time.Second)},
+		expectedHistory:   2,
+		expectedCondition: corev1.ConditionFalse,
+		expectedEvents:    []string{ReasonUnhealthyNodeCondition},
+	}, {
+		name:              "backoff elapsed",
+		history:           []healthcheckingv1alpha1.RemediationRecord{remediated("a", 10*time.Minute), remediated("b", 3*time.Minute)},
+		expectedDeleted:   true,
+		expectedHistory:   3,
+		expectedCondition: corev1.ConditionTrue,
+		expectedEvents:    []string{EventReasonMachineDeleted, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition},
+	}, {
+		name:              "attempts exceeded",
+		history:           []healthcheckingv1alpha1.RemediationRecord{remediated("a", 30*time.Minute), remediated("b", 20*time.Minute), remediated("c", 10*time.Minute)},
+		expectedHistory:   3,
+		expectedCondition: corev1.ConditionTrue,
+		expectedEvents:    []string{EventReasonRemediationAttemptsExceeded, ReasonUnhealthyNodeCondition},
+	}, {
+		name:              "attempts out of the window",
+		history:           []healthcheckingv1alpha1.RemediationRecord{remediated("a", 3*time.Hour), remediated("b", 2*time.Hour), remediated("c", 90*time.Minute)},
+		expectedDeleted:   true,
+		expectedHistory:   1,
+		expectedCondition: corev1.ConditionFalse,
+		expectedEvents:    []string{EventReasonMachineDeleted, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition},
+	}, {
+		name:              "backoff capped at the window",
+		history:           manyRemediated,
+		maxAttempts:       100,
+		expectedHistory:   29,
+		expectedCondition: corev1.ConditionFalse,
+		expectedEvents:    []string{ReasonUnhealthyNodeCondition},
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.RemediationRetry = &healthcheckingv1alpha1.RemediationRetryPolicy{MaxAttempts: tc.maxAttempts}
+		mhc.Status.RemediationHistory = tc.history
+		node := newNode("d", corev1.ConditionUnknown, now.Add(-10*time.Minute))
+		c, recorder := newFakeController(t, []runtime.Object{node}, []runtime.Object{newMachine("d", "d", true)}, []runtime.Object{mhc}, stopCh)
+		c.now = func() time.Time { return now }
+
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		_, err := c.machineClient.Resource(machine.Resource).Namespace(namespace).Get("d", metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("%s: failed to get machine: %v", tc.name, err)
+		}
+		if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+			t.Errorf("%s: expected machine deleted %t, got %t", tc.name, tc.expectedDeleted, deleted)
+		}
+
+		updated, err := c.mhcClient.HealthcheckingV1alpha1().MachineHealthChecks(namespace).Get(mhcName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get machine health check: %v", tc.name, err)
+		}
+		if len(updated.Status.RemediationHistory) != tc.expectedHistory {
+			t.Errorf("%s: expected %d remediations in the history, got %v", tc.name, tc.expectedHistory, updated.Status.RemediationHistory)
+		}
+		var condition *healthcheckingv1alpha1.MachineHealthCheckCondition
+		for i := range updated.Status.Conditions {
+			if updated.Status.Conditions[i].Type == healthcheckingv1alpha1.RemediationAttemptsExceeded {
+				condition = &updated.Status.Conditions[i]
+			}
+		}
+		if condition == nil || condition.Status != tc.expectedCondition {
+			t.Errorf("%s: expected condition %s %s, got %v", tc.name, healthcheckingv1alpha1.RemediationAttemptsExceeded, tc.expectedCondition, condition)
+		}
+
+		reasons := events(recorder)
+		sort.Strings(reasons)
+		if strings.Join(reasons, ",") != strings.Join(tc.expectedEvents, ",") {
+			t.Errorf("%s: expected events %v, got %v", tc.name, tc.expectedEvents, reasons)
+		}
+		close(stopCh)
+	}
+}
+
+func TestRemediationRecordedBeforeStatusUpdate(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name string
+		// failedUpdate is the number of the status update failing during the sync
+		failedUpdate int
+		// expectedDeleted is whether the unhealthy machine is deleted by the sync
+		expectedDeleted bool
+		expectedHistory int
+	}{{
+		name:            "history update failed",
+		failedUpdate:    1,
+		expectedDeleted: false,
+		expectedHistory: 0,
+	}, {
+		name:            "status update failed after the remediation",
+		failedUpdate:    2,
+		expectedDeleted: true,
+		expectedHistory: 1,
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.RemediationRetry = &healthcheckingv1alpha1.RemediationRetryPolicy{}
+		node := newNode("d", corev1.ConditionUnknown, now.Add(-10*time.Minute))
+		c, _ := newFakeController(t, []runtime.Object{node}, []runtime.Object{newMachine("d", "d", true)}, []runtime.Object{mhc}, stopCh)
+		c.now = func() time.Time { return now }
+		updates := 0
+		c.mhcClient.(*fakemhc.Clientset).PrependReactor("update", "machinehealthchecks", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "status" {
+				return false, nil, nil
+			}
+			if updates++; updates == tc.failedUpdate {
+				return true, nil, apierrors.NewConflict(healthcheckingv1alpha1.Resource("machinehealthchecks"), mhcName, fmt.Errorf("the object has been modified"))
+			}
+			return false, nil, nil
+		})
+
+		if err := c.sync(namespace + "/" + mhcName); err == nil {
+			t.Errorf("%s: expected the sync to fail", tc.name)
+		}
+
+		_, err := c.machineClient.Resource(machine.Resource).Namespace(namespace).Get("d", metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("%s: failed to get machine: %v", tc.name, err)
+		}
+		if deleted := apierrors.IsNotFound(err); deleted != tc.expectedDeleted {
+			t.Errorf("%s: expected machine deleted %t, got %t", tc.name, tc.expectedDeleted, deleted)
+		}
+
+		updated, err := c.mhcClient.HealthcheckingV1alpha1().MachineHealthChecks(namespace).Get(mhcName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get machine health check: %v", tc.name, err)
+		}
+		if len(updated.Status.RemediationHistory) != tc.expectedHistory {
+			t.Errorf("%s: expected %d remediations in the history, got %v", tc.name, tc.expectedHistory, updated.Status.RemediationHistory)
+		}
+		close(stopCh)
+	}
+}
@@ -0,0 +1,297 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	coreinformersv1 "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// podNodeNameIndex is the name of the index of the pods by the name of their node
+	podNodeNameIndex = "nodeName"
+)
+
+// podInformers runs an informer of the pods for every namespace of the required pods. An informer
+// is started once a machine health check requires pods in its namespace and it is stopped once
+// no machine health check requires them anymore, so the pods are not watched when no machine
+// health check requires them.
+type podInformers struct {
+	lock    sync.Mutex
+	client  kubernetes.Interface
+	handler cache.ResourceEventHandler
+	// informers contains the informers by the namespace of their pods
+	informers map[string]*podInformer
+	// namespaces contains the namespaces of the required pods by the key of the machine health check
+	namespaces map[string]map[string]bool
+}
+
+// podInformer is the informer of the pods of a namespace with the keys of the machine health checks
+// requiring pods in the namespace, it is stopped once its stop channel is closed
+type podInformer struct {
+	informer   cache.SharedIndexInformer
+	stopCh     chan struct{}
+	requiredBy map[string]bool
+}
+
+func newPodInformers(client kubernetes.Interface, handler cache.ResourceEventHandler) *podInformers {
+	return &podInformers{
+		client:     client,
+		handler:    handler,
+		informers:  map[string]*podInformer{},
+		namespaces: map[string]map[string]bool{},
+	}
+}
+
+// require updates the namespaces of the pods required by the machine health check of the key, the
+// informers of the newly required namespaces are started and the informers of the namespaces no
+// longer required by any machine health check are stopped. A deleted machine health check does
+// not require any pods.
+func (pi *podInformers) require(key string, requiredPods []healthcheckingv1alpha1.RequiredPod) {
+	namespaces := map[string]bool{}
+	for _, required := range requiredPods {
+		namespaces[required.Namespace] = true
+	}
+
+	pi.lock.Lock()
+	defer pi.lock.Unlock()
+	for namespace := range pi.namespaces[key] {
+		if namespaces[namespace] {
+			continue
+		}
+		pi.release(key, namespace)
+	}
+	for namespace := range namespaces {
+		pi.acquire(key, namespace)
+	}
+	if len(namespaces) == 0 {
+		delete(pi.namespaces, key)
+	} else {
+		pi.namespaces[key] = namespaces
+	}
+}
+
+// acquire starts the informer of the namespace unless it runs already, the lock must be held
+func (pi *podInformers) acquire(key, namespace string) {
+	pinf, ok := pi.informers[namespace]
+	if !ok {
+		glog.V(2).Infof("Watching the pods of namespace %s", namespace)
+		pinf = &podInformer{
+			informer:   coreinformersv1.NewPodInformer(pi.client, namespace, 0, cache.Indexers{podNodeNameIndex: indexPodByNodeName}),
+			stopCh:     make(chan struct{}),
+			requiredBy: map[string]bool{},
+		}
+		pinf.informer.AddEventHandler(pi.handler)
+		pi.informers[namespace] = pinf
+		go pinf.informer.Run(pinf.stopCh)
+	}
+	pinf.requiredBy[key] = true
+}
+
+// release stops the informer of the namespace once the machine health check of the key was the
+// last one requiring pods in the namespace, the lock must be held
+func (pi *podInformers) release(key, namespace string) {
+	pinf, ok := pi.informers[namespace]
+	if !ok {
+		return
+	}
+	delete(pinf.requiredBy, key)
+	if len(pinf.requiredBy) == 0 {
+		glog.V(2).Infof("Stopped watching the pods of namespace %s", namespace)
+		close(pinf.stopCh)
+		delete(pi.informers, namespace)
+	}
+}
+
+// stop stops all the informers
+func (pi *podInformers) stop() {
+	pi.lock.Lock()
+	defer pi.lock.Unlock()
+	for namespace, pinf := range pi.informers {
+		close(pinf.stopCh)
+		delete(pi.informers, namespace)
+	}
+	pi.namespaces = map[string]map[string]bool{}
+}
+
+// indexer returns the synced indexer of the pods of the namespace, the pods of the namespace must
+// be required by a machine health check
+func (pi *podInformers) indexer(namespace string) (cache.Indexer, error) {
+	pi.lock.Lock()
+	pinf, ok := pi.informers[namespace]
+	pi.lock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("the pods of namespace %s are not watched", namespace)
+	}
+
+	if !cache.WaitForCacheSync(pinf.stopCh, pinf.informer.HasSynced) {
+		return nil, fmt.Errorf("failed to sync the pods of namespace %s", namespace)
+	}
+	return pinf.informer.GetIndexer(), nil
+}
+
+func indexPodByNodeName(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return []string{}, nil
+	}
+	return []string{pod.Spec.NodeName}, nil
+}
+
+// podEvent enqueues the machine health checks selecting the machine of the node of the pod and
+// requiring the pod
+func (c *Controller) podEvent(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return
+	}
+	node, err := c.nodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		glog.V(4).Infof("Failed to get node %s of pod %s/%s: %v", pod.Spec.NodeName, pod.Namespace, pod.Name, err)
+		return
+	}
+	m, err := machine.ForNode(c.machineLister, node)
+	if err != nil {
+		glog.V(4).Infof("Failed to get machine of node %s: %v", node.Name, err)
+		return
+	}
+	if m == nil {
+		return
+	}
+	mhcs, err := c.mhcLister.MachineHealthChecks(m.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	for _, mhc := range mhcs {
+		if selects(mhc, m) && requires(mhc, pod) {
+			c.enqueue(mhc)
+		}
+	}
+}
+
+// requires returns true when one of the required pods of the machine health check matches the pod
+func requires(mhc *healthcheckingv1alpha1.MachineHealthCheck, pod *corev1.Pod) bool {
+	for _, required := range mhc.Spec.RequiredPods {
+		if required.Namespace != pod.Namespace {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&required.Selector)
+		if err == nil && selector.Matches(labels.Set(pod.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// podsOnNode returns the pods scheduled on the node in the namespaces of the required pods
+func (c *Controller) podsOnNode(requiredPods []healthcheckingv1alpha1.RequiredPod, nodeName string) ([]*corev1.Pod, error) {
+	pods := []*corev1.Pod{}
+	namespaces := map[string]bool{}
+	for _, required := range requiredPods {
+		if namespaces[required.Namespace] {
+			continue
+		}
+		namespaces[required.Namespace] = true
+		indexer, err := c.pods.indexer(required.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		objs, err := indexer.ByIndex(podNodeNameIndex, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				pods = append(pods, pod)
+			}
+		}
+	}
+	return pods, nil
+}
+
+// checkRequiredPods evaluates the pods of the node of the target against the required pods. It
+// returns the reason when the node does not have a ready pod for one of them for longer than its
+// timeout or, when it can happen once a timeout expires, the duration after which the target has
+// to be checked again.
+func (t *target) checkRequiredPods(requiredPods []healthcheckingv1alpha1.RequiredPod, now time.Time) (*unhealthyReason, time.Duration) {
+	var nextCheck time.Duration
+	for _, required := range requiredPods {
+		selector, err := metav1.LabelSelectorAsSelector(&required.Selector)
+		if err != nil {
+			// the selectors are validated with the spec
+			continue
+		}
+
+		var notReadySince time.Time
+		notReady := ""
+		ready := false
+		for _, pod := range t.pods {
+			if pod.Namespace != required.Namespace || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			since, isReady := podReadiness(pod)
+			if isReady {
+				ready = true
+				break
+			}
+			// a replaced pod is measured from the latest pod
+			if notReady == "" || since.After(notReadySince) {
+				notReadySince = since
+				notReady = fmt.Sprintf("pod %s/%s of %s is not ready", pod.Namespace, pod.Name, required.Name)
+			}
+		}
+		if ready {
+			continue
+		}
+		// the node does not have the required pods until they are scheduled on it
+		if notReady == "" {
+			notReadySince = t.Node.CreationTimestamp.Time
+			notReady = fmt.Sprintf("no pod of %s is running on the node", required.Name)
+		}
+
+		elapsed := now.Sub(notReadySince)
+		if elapsed >= required.Timeout.Duration {
+			return &unhealthyReason{
+				Reason:  ReasonRequiredPodNotReady,
+				Message: fmt.Sprintf("%s for more than %s", notReady, required.Timeout.Duration),
+			}, 0
+		}
+		if remaining := required.Timeout.Duration - elapsed; nextCheck == 0 || remaining < nextCheck {
+			nextCheck = remaining
+		}
+	}
+	return nil, nextCheck
+}
+
+// podReadiness returns whether the pod is ready and, when it is not, the time it became not ready
+func podReadiness(pod *corev1.Pod) (time.Time, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			if condition.Status == corev1.ConditionTrue {
+				return time.Time{}, true
+			}
+			if !condition.LastTransitionTime.IsZero() {
+				return condition.LastTransitionTime.Time, false
+			}
+		}
+	}
+	return pod.CreationTimestamp.Time, false
+}
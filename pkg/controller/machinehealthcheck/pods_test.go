@@ -0,0 +1,258 @@
+package machinehealthcheck
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// newRequiredPod returns a running daemon set pod of the sdn with the Ready condition
+func newRequiredPod(name, nodeName string, ready corev1.ConditionStatus, lastTransition time.Time) *corev1.Pod {
+	pod := newPod(name, nodeName, "DaemonSet")
+	pod.Namespace = "openshift-sdn"
+	pod.Labels = map[string]string{"app": "sdn"}
+	pod.CreationTimestamp = metav1.NewTime(lastTransition)
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type:               corev1.PodReady,
+		Status:             ready,
+		LastTransitionTime: metav1.NewTime(lastTransition),
+	}}
+	return pod
+}
+
+// newRequiredPods returns the sdn pods required on every node with a 5m timeout
+func newRequiredPods() []healthcheckingv1alpha1.RequiredPod {
+	return []healthcheckingv1alpha1.RequiredPod{{
+		Name:      "sdn",
+		Namespace: "openshift-sdn",
+		Selector:  metav1.LabelSelector{MatchLabels: map[string]string{"app": "sdn"}},
+		Timeout:   metav1.Duration{Duration: 5 * time.Minute},
+	}}
+}
+
+func TestRequiredPods(t *testing.T) {
+	now := time.Now()
+	spec := &healthcheckingv1alpha1.MachineHealthCheckSpec{
+		RequiredPods: newRequiredPods(),
+	}
+	node := func(created time.Time) *corev1.Node {
+		node := newNode("node", corev1.ConditionTrue, created)
+		node.CreationTimestamp = metav1.NewTime(created)
+		return node
+	}
+	otherNamespace := newRequiredPod("sdn-a", "node", corev1.ConditionTrue, now.Add(-time.Hour))
+	otherNamespace.Namespace = "default"
+	terminating := newRequiredPod("sdn-a", "node", corev1.ConditionTrue, now.Add(-time.Hour))
+	terminating.DeletionTimestamp = &metav1.Time{Time: now}
+
+	tests := []struct {
+		name              string
+		node              *corev1.Node
+		pods              []*corev1.Pod
+		expectedReason    string
+		expectedNextCheck time.Duration
+	}{{
+		name: "ready pod",
+		node: node(now.Add(-time.Hour)),
+		pods: []*corev1.Pod{newRequiredPod("sdn-a", "node", corev1.ConditionTrue, now.Add(-time.Hour))},
+	}, {
+		name:              "pod not ready within the timeout",
+		node:              node(now.Add(-time.Hour)),
+		pods:              []*corev1.Pod{newRequiredPod("sdn-a", "node", corev1.ConditionFalse, now.Add(-time.Minute))},
+		expectedNextCheck: 4 * time.Minute,
+	}, {
+		name:           "pod not ready after the timeout",
+		node:           node(now.Add(-time.Hour)),
+		pods:           []*corev1.Pod{newRequiredPod("sdn-a", "node", corev1.ConditionFalse, now.Add(-10*time.Minute))},
+		expectedReason: ReasonRequiredPodNotReady,
+	}, {
+		name:              "replaced pod not ready within the timeout",
+		node:              node(now.Add(-time.Hour)),
+		pods:              []*corev1.Pod{newRequiredPod("sdn-a", "node", corev1.ConditionFalse, now.Add(-10*time.Minute)), newRequiredPod("sdn-b", "node", corev1.ConditionFalse, now.Add(-2*time.Minute))},
+		expectedNextCheck: 3 * time.Minute,
+	}, {
+		name: "one of the pods ready",
+		node: node(now.Add(-time.Hour)),
+		pods: []*corev1.Pod{newRequiredPod("sdn-a", "node", corev1.ConditionFalse, now.Add(-10*time.Minute)), newRequiredPod("sdn-b", "node", corev1.ConditionTrue, now.Add(-2*time.Minute))},
+	}, {
+		name:              "pod missing on a new node",
+		node:              node(now.Add(-2 * time.Minute)),
+		expectedNextCheck: 3 * time.Minute,
+	}, {
+		name:           "pod missing after the timeout",
+		node:           node(now.Add(-time.Hour)),
+		pods:           []*corev1.Pod{otherNamespace, terminating},
+		expectedReason: ReasonRequiredPodNotReady,
+	}}
+
+	for _, tc := range tests {
+		target := &target{Machine: newMachine("machine", "node", true), Node: tc.node, pods: tc.pods}
+		reason, nextCheck := target.needsRemediation(spec, now)
+		got := ""
+		if reason != nil {
+			got = reason.Reason
+		}
+		if got != tc.expectedReason {
+			t.Errorf("%s: expected reason %q, got %q", tc.name, tc.expectedReason, got)
+		}
+		if nextCheck != tc.expectedNextCheck {
+			t.Errorf("%s: expected next check in %s, got %s", tc.name, tc.expectedNextCheck, nextCheck)
+		}
+	}
+}
+
+func TestRequiredPodsSync(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	now := time.Now()
+	mhc := newMachineHealthCheck(nil)
+	mhc.Spec.RequiredPods = newRequiredPods()
+	objects := []runtime.Object{
+		newNode("a", corev1.ConditionTrue, now.Add(-time.Hour)),
+		newNode("b", corev1.ConditionTrue, now.Add(-time.Hour)),
+		newRequiredPod("sdn-a", "a", corev1.ConditionTrue, now.Add(-time.Hour)),
+		newRequiredPod("sdn-b", "b", corev1.ConditionFalse, now.Add(-10*time.Minute)),
+	}
+	c, recorder := newFakeController(t, objects, []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true)}, []runtime.Object{mhc}, stopCh)
+	c.now = func() time.Time { return now }
+
+	if err := c.sync(namespace + "/" + mhcName); err != nil {
+		t.Errorf("failed to sync: %v", err)
+	}
+	expectedEvents := []string{ReasonRequiredPodNotReady, EventReasonNodeDrainStarted, EventReasonMachineDeleted}
+	if reasons := events(recorder); strings.Join(reasons, ",") != strings.Join(expectedEvents, ",") {
+		t.Errorf("expected events %v, got %v", expectedEvents, reasons)
+	}
+}
+
+func TestPodInformersStartedOnDemand(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	node := newNode("a", corev1.ConditionTrue, time.Now())
+	c, _ := newFakeController(t, []runtime.Object{node}, []runtime.Object{newMachine("a", "a", true)}, []runtime.Object{newMachineHealthCheck(nil)}, stopCh)
+
+	if err := c.sync(namespace + "/" + mhcName); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if len(c.pods.informers) != 0 {
+		t.Errorf("expected no pod informers without required pods, got %d", len(c.pods.informers))
+	}
+	if _, err := c.podsOnNode(newRequiredPods(), "a"); err == nil {
+		t.Errorf("expected an error listing the pods of a namespace not required")
+	}
+
+	c.pods.require(namespace+"/"+mhcName, newRequiredPods())
+	if _, err := c.podsOnNode(newRequiredPods(), "a"); err != nil {
+		t.Fatalf("failed to list the pods of the node: %v", err)
+	}
+	if len(c.pods.informers) != 1 || c.pods.informers["openshift-sdn"] == nil {
+		t.Errorf("expected a pod informer of namespace openshift-sdn, got %v", c.pods.informers)
+	}
+}
+
+func TestPodInformersStopped(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	c, _ := newFakeController(t, nil, nil, nil, stopCh)
+	other := []healthcheckingv1alpha1.RequiredPod{{Name: "dns", Namespace: "openshift-dns"}}
+
+	c.pods.require("a", newRequiredPods())
+	c.pods.require("b", append(newRequiredPods(), other...))
+	sdn := c.pods.informers["openshift-sdn"]
+	dns := c.pods.informers["openshift-dns"]
+	if len(c.pods.informers) != 2 || sdn == nil || dns == nil {
+		t.Fatalf("expected the pod informers of namespaces openshift-sdn and openshift-dns, got %v", c.pods.informers)
+	}
+
+	stopped := func(pinf *podInformer) bool {
+		select {
+		case <-pinf.stopCh:
+			return true
+		default:
+			return false
+		}
+	}
+	tests := []struct {
+		name         string
+		key          string
+		requiredPods []healthcheckingv1alpha1.RequiredPod
+		expectedSDN  bool
+		expectedDNS  bool
+	}{{
+		name:         "namespace no longer required by one of the machine health checks",
+		key:          "b",
+		requiredPods: other,
+		expectedSDN:  true,
+		expectedDNS:  true,
+	}, {
+		name:        "last machine health check requiring the namespace deleted",
+		key:         "a",
+		expectedDNS: true,
+	}, {
+		name: "no namespace required",
+		key:  "b",
+	}}
+
+	for _, tc := range tests {
+		c.pods.require(tc.key, tc.requiredPods)
+		if running := !stopped(sdn) && c.pods.informers["openshift-sdn"] == sdn; running != tc.expectedSDN {
+			t.Errorf("%s: expected the pod informer of namespace openshift-sdn running %t, got %t", tc.name, tc.expectedSDN, running)
+		}
+		if running := !stopped(dns) && c.pods.informers["openshift-dns"] == dns; running != tc.expectedDNS {
+			t.Errorf("%s: expected the pod informer of namespace openshift-dns running %t, got %t", tc.name, tc.expectedDNS, running)
+		}
+	}
+	if len(c.pods.namespaces) != 0 {
+		t.Errorf("expected no required namespaces, got %v", c.pods.namespaces)
+	}
+}
+
+func TestPodEventEnqueuesMachineHealthCheck(t *testing.T) {
+	otherNamespace := newRequiredPod("sdn-a", "a", corev1.ConditionFalse, time.Now())
+	otherNamespace.Namespace = "default"
+	otherLabels := newRequiredPod("web-a", "a", corev1.ConditionFalse, time.Now())
+	otherLabels.Labels = map[string]string{"app": "web"}
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected int
+	}{{
+		name:     "required pod",
+		pod:      newRequiredPod("sdn-a", "a", corev1.ConditionFalse, time.Now()),
+		expected: 1,
+	}, {
+		name: "pod in another namespace",
+		pod:  otherNamespace,
+	}, {
+		name: "pod not matching the selector",
+		pod:  otherLabels,
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.RequiredPods = newRequiredPods()
+		node := newNode("a", corev1.ConditionTrue, time.Now())
+		c, _ := newFakeController(t, []runtime.Object{node}, []runtime.Object{newMachine("a", "a", true)}, []runtime.Object{mhc}, stopCh)
+
+		// drain the keys added by the informers
+		for c.queue.Len() > 0 {
+			key, _ := c.queue.Get()
+			c.queue.Done(key)
+			c.queue.Forget(key)
+		}
+
+		c.podEvent(tc.pod)
+		if c.queue.Len() != tc.expected {
+			t.Errorf("%s: expected %d enqueued machine health checks, got %d", tc.name, tc.expected, c.queue.Len())
+		}
+		close(stopCh)
+	}
+}
@@ -0,0 +1,248 @@
+package machinehealthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// EventReasonMachineRebootRequested is the reason of the event reporting an unhealthy machine annotated to be rebooted
+	EventReasonMachineRebootRequested = "MachineRebootRequested"
+	// EventReasonExternalRemediationCreated is the reason of the event reporting a remediation object created for an unhealthy machine
+	EventReasonExternalRemediationCreated = "ExternalRemediationCreated"
+	// EventReasonExternalRemediationSucceeded is the reason of the event reporting a completed external remediation
+	EventReasonExternalRemediationSucceeded = "ExternalRemediationSucceeded"
+	// EventReasonExternalRemediationFailed is the reason of the event reporting a failed external remediation
+	EventReasonExternalRemediationFailed = "ExternalRemediationFailed"
+)
+
+const (
+	// externalRemediationCheckInterval is the interval the remediation objects are checked for completion,
+	// their changes do not trigger a sync of the machine health check
+	externalRemediationCheckInterval = 30 * time.Second
+)
+
+// remediationStrategy remediates the unhealthy machines selected by a machine health check
+type remediationStrategy interface {
+	// inProgress returns whether a remediation of the target is in progress and, when its
+	// completion is not reported by a machine event, the duration after which it has to be
+	// checked again. It finishes the completed remediations, so it is called for all the targets.
+	inProgress(t *target) (bool, time.Duration, error)
+	// remediate starts the remediation of the unhealthy target.
+	remediate(t *target, reason *unhealthyReason) error
+}
+
+// newRemediationStrategy returns the remediation strategy of the defaulted machine health check
+func (c *Controller) newRemediationStrategy(mhc *healthcheckingv1alpha1.MachineHealthCheck) (remediationStrategy, error) {
+	switch mhc.Spec.RemediationStrategy {
+	case healthcheckingv1alpha1.RemediationStrategyDelete:
+		return &deleteStrategy{mhc: mhc, machineClient: c.machineClient, eventRecorder: c.eventRecorder}, nil
+	case healthcheckingv1alpha1.RemediationStrategyReboot:
+		return &rebootStrategy{mhc: mhc, machineClient: c.machineClient, eventRecorder: c.eventRecorder}, nil
+	case healthcheckingv1alpha1.RemediationStrategyExternal:
+		return &externalStrategy{mhc: mhc, machineClient: c.machineClient, eventRecorder: c.eventRecorder, now: c.now}, nil
+	}
+	return nil, fmt.Errorf("unknown remediation strategy %q", mhc.Spec.RemediationStrategy)
+}
+
+// deleteStrategy deletes the unhealthy machine, so it is replaced by its machine set
+type deleteStrategy struct {
+	mhc           *healthcheckingv1alpha1.MachineHealthCheck
+	machineClient dynamic.Interface
+	eventRecorder record.EventRecorder
+}
+
+func (s *deleteStrategy) inProgress(t *target) (bool, time.Duration, error) {
+	return t.Machine.GetDeletionTimestamp() != nil, 0, nil
+}
+
+func (s *deleteStrategy) remediate(t *target, reason *unhealthyReason) error {
+	glog.Infof("Deleting unhealthy machine %s: %s", t, reason)
+	err := s.machineClient.Resource(machine.Resource).Namespace(t.Machine.GetNamespace()).Delete(t.Machine.GetName(), &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error deleting machine %s: %v", t, err)
+	}
+	s.eventRecorder.Eventf(s.mhc, corev1.EventTypeNormal, EventReasonMachineDeleted, "Deleted unhealthy machine %s: %s", t, reason)
+	return nil
+}
+
+// rebootStrategy annotates the unhealthy machine to be rebooted by the machine provider,
+// the provider removes the annotation once the reboot completes
+type rebootStrategy struct {
+	mhc           *healthcheckingv1alpha1.MachineHealthCheck
+	machineClient dynamic.Interface
+	eventRecorder record.EventRecorder
+}
+
+func (s *rebootStrategy) inProgress(t *target) (bool, time.Duration, error) {
+	_, ok := t.Machine.GetAnnotations()[healthcheckingv1alpha1.RebootAnnotation]
+	return ok || t.Machine.GetDeletionTimestamp() != nil, 0, nil
+}
+
+func (s *rebootStrategy) remediate(t *target, reason *unhealthyReason) error {
+	glog.Infof("Requesting reboot of unhealthy machine %s: %s", t, reason)
+	if err := setMachineAnnotation(s.machineClient, t.Machine, healthcheckingv1alpha1.RebootAnnotation, ""); err != nil {
+		return fmt.Errorf("error requesting reboot of machine %s: %v", t, err)
+	}
+	s.eventRecorder.Eventf(s.mhc, corev1.EventTypeNormal, EventReasonMachineRebootRequested, "Requested reboot of unhealthy machine %s: %s", t, reason)
+	return nil
+}
+
+// externalStrategy creates a remediation object from the remediation template for the unhealthy
+// machine and waits until the remediation object reports the remediation completed
+type externalStrategy struct {
+	mhc           *healthcheckingv1alpha1.MachineHealthCheck
+	machineClient dynamic.Interface
+	eventRecorder record.EventRecorder
+	now           func() time.Time
+}
+
+// resources returns the resources of the remediation template and of the remediation objects
+func (s *externalStrategy) resources() (schema.GroupVersionResource, schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(s.mhc.Spec.RemediationTemplate.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, schema.GroupVersionResource{}, fmt.Errorf("invalid remediation template apiVersion: %v", err)
+	}
+	templateKind := s.mhc.Spec.RemediationTemplate.Kind
+	template, _ := meta.UnsafeGuessKindToResource(gv.WithKind(templateKind))
+	remediation, _ := meta.UnsafeGuessKindToResource(gv.WithKind(strings.TrimSuffix(templateKind, "Template")))
+	return template, remediation, nil
+}
+
+func (s *externalStrategy) inProgress(t *target) (bool, time.Duration, error) {
+	if _, ok := t.Machine.GetAnnotations()[healthcheckingv1alpha1.ExternalRemediationAnnotation]; !ok {
+		return t.Machine.GetDeletionTimestamp() != nil, 0, nil
+	}
+
+	_, resource, err := s.resources()
+	if err != nil {
+		return false, 0, err
+	}
+	client := s.machineClient.Resource(resource).Namespace(t.Machine.GetNamespace())
+	remediation, err := client.Get(t.Machine.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		glog.Warningf("Remediation %s of machine %s does not exist anymore", resource.Resource, t)
+		return false, 0, removeMachineAnnotation(s.machineClient, t.Machine, healthcheckingv1alpha1.ExternalRemediationAnnotation)
+	}
+	if err != nil {
+		return false, 0, err
+	}
+
+	succeeded, message, found := remediationSucceeded(remediation)
+	if !found {
+		return true, externalRemediationCheckInterval, nil
+	}
+	if succeeded {
+		glog.Infof("Remediation of machine %s succeeded", t)
+		s.eventRecorder.Eventf(s.mhc, corev1.EventTypeNormal, EventReasonExternalRemediationSucceeded, "Remediation of machine %s succeeded: %s", t, message)
+	} else {
+		glog.Warningf("Remediation of machine %s failed: %s", t, message)
+		s.eventRecorder.Eventf(s.mhc, corev1.EventTypeWarning, EventReasonExternalRemediationFailed, "Remediation of machine %s failed: %s", t, message)
+	}
+
+	// the completed remediation object is removed, so the machine is remediated again when it is still unhealthy
+	if err := client.Delete(remediation.GetName(), &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return false, 0, fmt.Errorf("error deleting remediation %s of machine %s: %v", resource.Resource, t, err)
+	}
+	return false, 0, removeMachineAnnotation(s.machineClient, t.Machine, healthcheckingv1alpha1.ExternalRemediationAnnotation)
+}
+
+func (s *externalStrategy) remediate(t *target, reason *unhealthyReason) error {
+	templateResource, resource, err := s.resources()
+	if err != nil {
+		return err
+	}
+	templateName := s.mhc.Spec.RemediationTemplate.Name
+	template, err := s.machineClient.Resource(templateResource).Namespace(s.mhc.Namespace).Get(templateName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting remediation template %s/%s: %v", s.mhc.Namespace, templateName, err)
+	}
+
+	remediation := &unstructured.Unstructured{}
+	remediation.SetAPIVersion(s.mhc.Spec.RemediationTemplate.APIVersion)
+	remediation.SetKind(strings.TrimSuffix(s.mhc.Spec.RemediationTemplate.Kind, "Template"))
+	remediation.SetNamespace(t.Machine.GetNamespace())
+	remediation.SetName(t.Machine.GetName())
+	// the remediation object is garbage collected together with the machine
+	remediation.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: t.Machine.GetAPIVersion(),
+		Kind:       t.Machine.GetKind(),
+		Name:       t.Machine.GetName(),
+		UID:        t.Machine.GetUID(),
+	}})
+	if spec, found, err := unstructured.NestedMap(template.Object, "spec", "template", "spec"); err != nil {
+		return fmt.Errorf("invalid remediation template %s/%s: %v", s.mhc.Namespace, templateName, err)
+	} else if found {
+		unstructured.SetNestedMap(remediation.Object, spec, "spec")
+	}
+
+	glog.Infof("Creating remediation %s of unhealthy machine %s: %s", resource.Resource, t, reason)
+	_, err = s.machineClient.Resource(resource).Namespace(remediation.GetNamespace()).Create(remediation, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating remediation %s of machine %s: %v", resource.Resource, t, err)
+	}
+	if err := setMachineAnnotation(s.machineClient, t.Machine, healthcheckingv1alpha1.ExternalRemediationAnnotation, s.now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("error annotating machine %s: %v", t, err)
+	}
+	s.eventRecorder.Eventf(s.mhc, corev1.EventTypeNormal, EventReasonExternalRemediationCreated, "Created %s %s for unhealthy machine %s: %s", remediation.GetKind(), remediation.GetName(), t, reason)
+	return nil
+}
+
+// remediationSucceeded returns the status and the message of the Succeeded condition of the
+// remediation object, found is false while the remediation is in progress
+func remediationSucceeded(remediation *unstructured.Unstructured) (succeeded bool, message string, found bool) {
+	conditions, _, _ := unstructured.NestedSlice(remediation.Object, "status", "conditions")
+	for _, obj := range conditions {
+		condition, ok := obj.(map[string]interface{})
+		if !ok || condition["type"] != healthcheckingv1alpha1.ExternalRemediationSucceeded {
+			continue
+		}
+		message, _ := condition["message"].(string)
+		switch condition["status"] {
+		case string(corev1.ConditionTrue):
+			return true, message, true
+		case string(corev1.ConditionFalse):
+			return false, message, true
+		}
+	}
+	return false, "", false
+}
+
+// setMachineAnnotation sets the annotation of the machine with a merge patch
+func setMachineAnnotation(client dynamic.Interface, m *unstructured.Unstructured, key, value string) error {
+	return patchMachineAnnotation(client, m, key, &value)
+}
+
+// removeMachineAnnotation removes the annotation of the machine with a merge patch
+func removeMachineAnnotation(client dynamic.Interface, m *unstructured.Unstructured, key string) error {
+	return patchMachineAnnotation(client, m, key, nil)
+}
+
+func patchMachineAnnotation(client dynamic.Interface, m *unstructured.Unstructured, key string, value *string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{key: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.Resource(machine.Resource).Namespace(m.GetNamespace()).Patch(m.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,195 @@
+package machinehealthcheck
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var remediationResource = schema.GroupVersionResource{Group: "remediation.example.com", Version: "v1", Resource: "powercycles"}
+
+func newRemediationTemplate() *unstructured.Unstructured {
+	template := &unstructured.Unstructured{}
+	template.SetAPIVersion("remediation.example.com/v1")
+	template.SetKind("PowerCycleTemplate")
+	template.SetNamespace(namespace)
+	template.SetName("power-cycle")
+	unstructured.SetNestedField(template.Object, "hard", "spec", "template", "spec", "mode")
+	return template
+}
+
+// newRemediation returns a remediation object of the machine with the Succeeded condition status, if any
+func newRemediation(name string, succeeded corev1.ConditionStatus) *unstructured.Unstructured {
+	remediation := &unstructured.Unstructured{}
+	remediation.SetAPIVersion("remediation.example.com/v1")
+	remediation.SetKind("PowerCycle")
+	remediation.SetNamespace(namespace)
+	remediation.SetName(name)
+	remediation.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "machine.openshift.io/v1beta1", Kind: "Machine", Name: name}})
+	unstructured.SetNestedField(remediation.Object, "hard", "spec", "mode")
+	if succeeded != "" {
+		unstructured.SetNestedSlice(remediation.Object, []interface{}{map[string]interface{}{
+			"type":   healthcheckingv1alpha1.ExternalRemediationSucceeded,
+			"status": string(succeeded),
+		}}, "status", "conditions")
+	}
+	return remediation
+}
+
+func withAnnotation(m *unstructured.Unstructured, key string) *unstructured.Unstructured {
+	m.SetAnnotations(map[string]string{key: ""})
+	return m
+}
+
+func TestRemediationStrategies(t *testing.T) {
+	now := time.Now()
+	unhealthyNode := newNode("b", corev1.ConditionUnknown, now.Add(-10*time.Minute))
+	healthyNode := newNode("b", corev1.ConditionTrue, now.Add(-10*time.Minute))
+
+	tests := []struct {
+		name     string
+		strategy healthcheckingv1alpha1.RemediationStrategyType
+		node     *corev1.Node
+		machine  *unstructured.Unstructured
+		objects  []runtime.Object
+		// expectedRemediation is whether the remediation object of the machine exists after the sync
+		expectedRemediation bool
+		expectedAnnotation  string
+		expectedEvents      []string
+	}{{
+		name:               "reboot requested",
+		strategy:           healthcheckingv1alpha1.RemediationStrategyReboot,
+		node:               unhealthyNode,
+		machine:            newMachine("b", "b", false),
+		expectedAnnotation: healthcheckingv1alpha1.RebootAnnotation,
+		expectedEvents:     []string{ReasonUnhealthyNodeCondition, EventReasonNodeDrainStarted, EventReasonMachineRebootRequested},
+	}, {
+		name:               "reboot in progress",
+		strategy:           healthcheckingv1alpha1.RemediationStrategyReboot,
+		node:               unhealthyNode,
+		machine:            withAnnotation(newMachine("b", "b", true), healthcheckingv1alpha1.RebootAnnotation),
+		expectedAnnotation: healthcheckingv1alpha1.RebootAnnotation,
+		expectedEvents:     []string{ReasonUnhealthyNodeCondition},
+	}, {
+		name:                "external remediation created",
+		strategy:            healthcheckingv1alpha1.RemediationStrategyExternal,
+		node:                unhealthyNode,
+		machine:             newMachine("b", "b", true),
+		objects:             []runtime.Object{newRemediationTemplate()},
+		expectedRemediation: true,
+		expectedAnnotation:  healthcheckingv1alpha1.ExternalRemediationAnnotation,
+		expectedEvents:      []string{ReasonUnhealthyNodeCondition, EventReasonExternalRemediationCreated},
+	}, {
+		name:                "external remediation in progress",
+		strategy:            healthcheckingv1alpha1.RemediationStrategyExternal,
+		node:                unhealthyNode,
+		machine:             withAnnotation(newMachine("b", "b", true), healthcheckingv1alpha1.ExternalRemediationAnnotation),
+		objects:             []runtime.Object{newRemediationTemplate(), newRemediation("b", "")},
+		expectedRemediation: true,
+		expectedAnnotation:  healthcheckingv1alpha1.ExternalRemediationAnnotation,
+		expectedEvents:      []string{ReasonUnhealthyNodeCondition},
+	}, {
+		name:           "external remediation succeeded",
+		strategy:       healthcheckingv1alpha1.RemediationStrategyExternal,
+		node:           healthyNode,
+		machine:        withAnnotation(newMachine("b", "b", true), healthcheckingv1alpha1.ExternalRemediationAnnotation),
+		objects:        []runtime.Object{newRemediationTemplate(), newRemediation("b", corev1.ConditionTrue)},
+		expectedEvents: []string{EventReasonExternalRemediationSucceeded},
+	}, {
+		name:           "external remediation failed",
+		strategy:       healthcheckingv1alpha1.RemediationStrategyExternal,
+		node:           healthyNode,
+		machine:        withAnnotation(newMachine("b", "b", true), healthcheckingv1alpha1.ExternalRemediationAnnotation),
+		objects:        []runtime.Object{newRemediationTemplate(), newRemediation("b", corev1.ConditionFalse)},
+		expectedEvents: []string{EventReasonExternalRemediationFailed},
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.RemediationStrategy = tc.strategy
+		if tc.strategy == healthcheckingv1alpha1.RemediationStrategyExternal {
+			mhc.Spec.RemediationTemplate = &corev1.ObjectReference{APIVersion: "remediation.example.com/v1", Kind: "PowerCycleTemplate", Name: "power-cycle"}
+		}
+		machines := append([]runtime.Object{tc.machine}, tc.objects...)
+		c, recorder := newFakeController(t, []runtime.Object{tc.node}, machines, []runtime.Object{mhc}, stopCh)
+		c.now = func() time.Time { return now }
+
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		updated, err := c.machineClient.Resource(machine.Resource).Namespace(namespace).Get(tc.machine.GetName(), metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get machine: %v", tc.name, err)
+		}
+		for _, annotation := range []string{healthcheckingv1alpha1.RebootAnnotation, healthcheckingv1alpha1.ExternalRemediationAnnotation} {
+			if _, ok := updated.GetAnnotations()[annotation]; ok != (annotation == tc.expectedAnnotation) {
+				t.Errorf("%s: expected annotation %q, got annotations %v", tc.name, tc.expectedAnnotation, updated.GetAnnotations())
+			}
+		}
+
+		remediation, err := c.machineClient.Resource(remediationResource).Namespace(namespace).Get(tc.machine.GetName(), metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatalf("%s: failed to get remediation: %v", tc.name, err)
+		}
+		if exists := err == nil; exists != tc.expectedRemediation {
+			t.Errorf("%s: expected remediation %t, got %t", tc.name, tc.expectedRemediation, exists)
+		}
+		if err == nil {
+			if mode, _, _ := unstructured.NestedString(remediation.Object, "spec", "mode"); mode != "hard" {
+				t.Errorf("%s: expected remediation spec from the template, got %v", tc.name, remediation.Object["spec"])
+			}
+			if refs := remediation.GetOwnerReferences(); len(refs) != 1 || refs[0].Name != tc.machine.GetName() {
+				t.Errorf("%s: expected remediation owned by the machine, got %v", tc.name, refs)
+			}
+		}
+
+		if reasons := events(recorder); strings.Join(reasons, ",") != strings.Join(tc.expectedEvents, ",") {
+			t.Errorf("%s: expected events %v, got %v", tc.name, tc.expectedEvents, reasons)
+		}
+		close(stopCh)
+	}
+}
+
+func TestExternalStrategyResources(t *testing.T) {
+	tests := []struct {
+		name                string
+		apiVersion          string
+		expectedTemplate    schema.GroupVersionResource
+		expectedRemediation schema.GroupVersionResource
+		expectedError       bool
+	}{{
+		name:                "valid api version",
+		apiVersion:          "remediation.example.com/v1",
+		expectedTemplate:    schema.GroupVersionResource{Group: "remediation.example.com", Version: "v1", Resource: "powercycletemplates"},
+		expectedRemediation: remediationResource,
+	}, {
+		name:          "invalid api version",
+		apiVersion:    "remediation.example.com/v1/beta",
+		expectedError: true,
+	}}
+
+	for _, tc := range tests {
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.RemediationTemplate = &corev1.ObjectReference{APIVersion: tc.apiVersion, Kind: "PowerCycleTemplate", Name: "power-cycle"}
+		s := &externalStrategy{mhc: mhc}
+		template, remediation, err := s.resources()
+		if (err != nil) != tc.expectedError {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.expectedError, err)
+		}
+		if template != tc.expectedTemplate || remediation != tc.expectedRemediation {
+			t.Errorf("%s: expected resources %v and %v, got %v and %v", tc.name, tc.expectedTemplate, tc.expectedRemediation, template, remediation)
+		}
+	}
+}
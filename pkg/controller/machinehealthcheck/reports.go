@@ -0,0 +1,44 @@
+package machinehealthcheck
+
+import (
+	"sync"
+)
+
+// unhealthyReports records the unhealthy machines reported by an event for every machine health
+// check, so the event is emitted once when a machine becomes unhealthy rather than on every sync.
+// The reports are kept in memory and the unhealthy machines are reported again once the controller
+// restarts.
+type unhealthyReports struct {
+	lock sync.Mutex
+	// reasons contains the reason of the unhealthy machines by machine health check key and machine
+	reasons map[string]map[string]string
+}
+
+func newUnhealthyReports() *unhealthyReports {
+	return &unhealthyReports{reasons: map[string]map[string]string{}}
+}
+
+// update replaces the unhealthy machines of the machine health check and returns whether the
+// machines of the given reasons were not reported yet with the same reason
+func (r *unhealthyReports) update(key string, reasons map[string]string) map[string]bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	reported := r.reasons[key]
+	unreported := map[string]bool{}
+	for machine, reason := range reasons {
+		unreported[machine] = reported[machine] != reason
+	}
+	if len(reasons) == 0 {
+		delete(r.reasons, key)
+	} else {
+		r.reasons[key] = reasons
+	}
+	return unreported
+}
+
+// forget removes the unhealthy machines of the machine health check
+func (r *unhealthyReports) forget(key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.reasons, key)
+}
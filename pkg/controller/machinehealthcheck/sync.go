@@ -0,0 +1,450 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+	"github.com/openshift/machine-health-check-operator/pkg/metrics"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// conditionReasonTooManyUnhealthy is the reason of the RemediationAllowed condition set to false
+	conditionReasonTooManyUnhealthy = "TooManyUnhealthy"
+	// conditionReasonUnhealthyWithinLimit is the reason of the RemediationAllowed condition set to true
+	conditionReasonUnhealthyWithinLimit = "UnhealthyWithinLimit"
+	// conditionReasonInvalidSpec is the reason of the SpecValid condition set to false
+	conditionReasonInvalidSpec = "InvalidSpec"
+	// conditionReasonValidSpec is the reason of the SpecValid condition set to true
+	conditionReasonValidSpec = "ValidSpec"
+)
+
+const (
+	// EventReasonInvalidSpec is the reason of the event reporting an invalid machine health check spec
+	EventReasonInvalidSpec = "InvalidSpec"
+	// EventReasonMachineDeleted is the reason of the event reporting a deleted unhealthy machine
+	EventReasonMachineDeleted = "MachineDeleted"
+	// EventReasonRemediationSkipped is the reason of the event reporting an unhealthy machine that can not be remediated
+	EventReasonRemediationSkipped = "RemediationSkipped"
+	// EventReasonRemediationRestricted is the reason of the event reporting that more machines than allowed are unhealthy
+	EventReasonRemediationRestricted = "RemediationRestricted"
+	// EventReasonRemediationResumed is the reason of the event reporting that the restricted remediation resumed
+	EventReasonRemediationResumed = "RemediationResumed"
+	// EventReasonDisruptionBudgetViolated is the reason of the event reporting an unhealthy machine whose deletion would violate a machine disruption budget
+	EventReasonDisruptionBudgetViolated = "DisruptionBudgetViolated"
+	// EventReasonControlPlaneProtected is the reason of the event reporting an unhealthy control plane machine whose remediation is refused to protect the etcd quorum
+	EventReasonControlPlaneProtected = "ControlPlaneProtected"
+	// EventReasonRemediationDryRun is the reason of the event reporting an unhealthy machine that would have been remediated in the dry-run mode
+	EventReasonRemediationDryRun = "RemediationDryRun"
+)
+
+func (c *Controller) sync(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	mhc, err := c.mhcLister.MachineHealthChecks(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		glog.V(4).Infof("Machine health check %s was deleted", key)
+		for _, reason := range unhealthyReasons {
+			metrics.UnhealthyMachines.DeleteLabelValues(namespace, name, reason)
+		}
+		metrics.DryRunRemediations.DeleteLabelValues(namespace, name)
+		c.unhealthyReports.forget(key)
+		c.pods.require(key, nil)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	mhc = mhc.DeepCopy()
+	healthcheckingv1alpha1.SetDefaultsMachineHealthCheckSpec(&mhc.Spec)
+	if errs := healthcheckingv1alpha1.ValidateMachineHealthCheckSpec(&mhc.Spec, field.NewPath("spec")); len(errs) > 0 {
+		// the spec is not retried until it is updated
+		glog.Errorf("Invalid machine health check %s: %v", key, errs.ToAggregate())
+		return c.reportInvalidSpec(mhc, errs)
+	}
+
+	c.pods.require(key, mhc.Spec.RequiredPods)
+	strategy, err := c.newRemediationStrategy(mhc)
+	if err != nil {
+		return err
+	}
+	targets, err := c.getTargets(mhc)
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+	var nextCheck time.Duration
+	unhealthy := []*target{}
+	reasons := map[*target]*unhealthyReason{}
+	unhealthyByReason := map[string]int{}
+	remediating := map[*target]bool{}
+	for _, t := range targets {
+		inProgress, recheck, err := strategy.inProgress(t)
+		if err != nil {
+			return err
+		}
+		remediating[t] = inProgress
+		if recheck > 0 && (nextCheck == 0 || recheck < nextCheck) {
+			nextCheck = recheck
+		}
+
+		reason, next := t.needsRemediation(&mhc.Spec, now)
+		if next > 0 && (nextCheck == 0 || next < nextCheck) {
+			nextCheck = next
+		}
+		if reason != nil {
+			glog.V(3).Infof("Machine %s is unhealthy: %s", t, reason)
+			unhealthy = append(unhealthy, t)
+			reasons[t] = reason
+			unhealthyByReason[reason.Reason]++
+			continue
+		}
+		// the node drained before the remediation is uncordoned once the machine recovers
+		if !inProgress {
+			if err := c.uncordon(mhc, t); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the unhealthy machines are reported once, their reason is kept in the status and the metrics
+	reported := map[string]string{}
+	for _, t := range unhealthy {
+		reported[t.String()] = reasons[t].Reason
+	}
+	unreported := c.unhealthyReports.update(key, reported)
+	for _, t := range unhealthy {
+		if unreported[t.String()] {
+			c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, reasons[t].Reason, "Machine %s is unhealthy: %s", t, reasons[t])
+		}
+	}
+
+	for _, reason := range unhealthyReasons {
+		metrics.UnhealthyMachines.WithLabelValues(mhc.Namespace, mhc.Name, reason).Set(float64(unhealthyByReason[reason]))
+	}
+
+	// the replacements of the machines of an unhealthy zone would be created in the same zone,
+	// so the remediation is suspended there until enough of its machines recover
+	zones, err := newZones(mhc, targets, reasons, remediating)
+	if err != nil {
+		return err
+	}
+	suspendedZones := zones.suspended()
+	c.reportSuspendedZones(mhc, zones, suspendedZones)
+
+	maxUnhealthy, err := intstr.GetValueFromIntOrPercent(mhc.Spec.MaxUnhealthy, len(targets), false)
+	if err != nil {
+		return err
+	}
+	// the remediation is paused while too many machines are unhealthy, for example because of
+	// a network partition, and it resumes once enough of them recover
+	restricted := len(unhealthy) > maxUnhealthy
+	wasRestricted := false
+	if condition := findCondition(mhc.Status.Conditions, healthcheckingv1alpha1.RemediationAllowed); condition != nil {
+		wasRestricted = condition.Status == corev1.ConditionFalse
+	}
+	remediationAllowed := healthcheckingv1alpha1.MachineHealthCheckCondition{
+		Type:               healthcheckingv1alpha1.RemediationAllowed,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             conditionReasonUnhealthyWithinLimit,
+		Message:            fmt.Sprintf("%d of %d machines are unhealthy, the maximum is %s", len(unhealthy), len(targets), mhc.Spec.MaxUnhealthy.String()),
+	}
+	if restricted {
+		remediationAllowed.Status = corev1.ConditionFalse
+		remediationAllowed.Reason = conditionReasonTooManyUnhealthy
+		remediationAllowed.Message = fmt.Sprintf("Remediation is restricted, %d of %d machines are unhealthy, more than the allowed %s", len(unhealthy), len(targets), mhc.Spec.MaxUnhealthy.String())
+	}
+
+	// the node condition events do not happen once the condition times out, so the
+	// machine health check is checked again when the earliest timeout expires
+	if nextCheck > 0 {
+		glog.V(4).Infof("Checking machine health check %s again in %s", key, nextCheck)
+		c.queue.AddAfter(key, nextCheck)
+	}
+
+	// in the dry-run mode, the machines that would have been remediated are reported in the status
+	dryRun := c.dryRun || mhc.Spec.DryRun
+	history := newRemediationHistory(mhc, now)
+	var dryRunRemediations []healthcheckingv1alpha1.DryRunRemediation
+	errs := []error{}
+	if restricted {
+		glog.Warningf("Machine health check %s: %d of %d machines are unhealthy, more than the allowed %d, skipping remediation", key, len(unhealthy), len(targets), maxUnhealthy)
+		if !wasRestricted {
+			c.eventRecorder.Event(mhc, corev1.EventTypeWarning, EventReasonRemediationRestricted, remediationAllowed.Message)
+		}
+	} else {
+		if wasRestricted {
+			glog.Infof("Machine health check %s: %d of %d machines are unhealthy, resuming remediation", key, len(unhealthy), len(targets))
+			c.eventRecorder.Eventf(mhc, corev1.EventTypeNormal, EventReasonRemediationResumed,
+				"Remediation resumed, %d of %d machines are unhealthy, the maximum is %s", len(unhealthy), len(targets), mhc.Spec.MaxUnhealthy.String())
+		}
+
+		budgets, err := c.getDisruptionBudgets(mhc.Namespace)
+		if err != nil {
+			return err
+		}
+		cp, err := c.getControlPlane(mhc.Namespace)
+		if err != nil {
+			return err
+		}
+		guards := &remediationGuards{budgets: budgets, controlPlane: cp, history: history, zones: zones}
+		for _, t := range unhealthy {
+			if remediating[t] {
+				glog.V(3).Infof("Remediation of machine %s is in progress", t)
+				continue
+			}
+			remediated, err := c.remediate(mhc, strategy, t, reasons[t], guards, dryRun)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if remediated && dryRun {
+				dryRunRemediations = append(dryRunRemediations, healthcheckingv1alpha1.DryRunRemediation{
+					MachineName: t.Machine.GetName(),
+					Reason:      reasons[t].Reason,
+					Message:     reasons[t].Message,
+				})
+			}
+		}
+	}
+	if dryRun {
+		metrics.DryRunRemediations.WithLabelValues(mhc.Namespace, mhc.Name).Set(float64(len(dryRunRemediations)))
+	} else {
+		metrics.DryRunRemediations.DeleteLabelValues(mhc.Namespace, mhc.Name)
+	}
+
+	specValid := healthcheckingv1alpha1.MachineHealthCheckCondition{
+		Type:               healthcheckingv1alpha1.SpecValid,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             conditionReasonValidSpec,
+	}
+	conditions := []healthcheckingv1alpha1.MachineHealthCheckCondition{remediationAllowed, specValid}
+	if mhc.Spec.RemediationRetry != nil {
+		conditions = append(conditions, history.condition())
+	}
+	if err := c.updateStatus(mhc, len(targets), len(targets)-len(unhealthy), dryRunRemediations, history.records, suspendedZones, conditions...); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// getTargets returns the machines selected by the machine health check with their nodes
+func (c *Controller) getTargets(mhc *healthcheckingv1alpha1.MachineHealthCheck) ([]*target, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := c.machineLister.ByNamespace(mhc.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []*target{}
+	for _, obj := range objs {
+		m, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected machine type %T", obj)
+		}
+		t := &target{Machine: m}
+		if name := machine.NodeName(m); name != "" {
+			node, err := c.nodeLister.Get(name)
+			switch {
+			case apierrors.IsNotFound(err):
+				t.nodeMissing = true
+			case err != nil:
+				return nil, err
+			default:
+				t.Node = node
+				c.transitions.observe(node)
+				t.transitions = c.transitions.get(node.Name)
+				if len(mhc.Spec.RequiredPods) > 0 {
+					if t.pods, err = c.podsOnNode(mhc.Spec.RequiredPods, node.Name); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// remediationGuards contains the limits of the remediations tracked during a single sync
+type remediationGuards struct {
+	budgets      *disruptionBudgets
+	controlPlane *controlPlane
+	history      *remediationHistory
+	zones        *zones
+}
+
+// remediate remediates the unhealthy machine with the remediation strategy of the machine health
+// check, unless the remediation is suspended or limited in the zone of the machine, is not allowed
+// by the retry policy, violates one of the machine disruption budgets selecting the machine or the
+// etcd quorum of the control plane. In the dry-run mode, the machine is only reported. It returns
+// whether the machine was remediated.
+func (c *Controller) remediate(mhc *healthcheckingv1alpha1.MachineHealthCheck, strategy remediationStrategy, t *target, reason *unhealthyReason, guards *remediationGuards, dryRun bool) (bool, error) {
+	budgets, cp := guards.budgets, guards.controlPlane
+	controlPlane := machine.IsControlPlane(t.Machine, t.Node)
+	if controlPlane && !mhc.Spec.RemediateControlPlane {
+		glog.Warningf("Machine %s is unhealthy, but it is a control plane machine, skipping remediation", t)
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonRemediationSkipped, "Machine %s is unhealthy (%s), but the remediation of control plane machines is not enabled", t, reason)
+		return false, nil
+	}
+	if controlPlane {
+		if violation := cp.violation(t); violation != "" {
+			glog.Warningf("Machine %s is unhealthy, but remediating it would risk the etcd quorum: %s", t, violation)
+			c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonControlPlaneProtected, "Control plane machine %s is unhealthy (%s), but it is not remediated: %s", t, reason, violation)
+			return false, nil
+		}
+	}
+
+	// the deleted machine is replaced only when it is owned by a machine set
+	if mhc.Spec.RemediationStrategy == healthcheckingv1alpha1.RemediationStrategyDelete && !hasMachineSetOwner(t.Machine) {
+		glog.Warningf("Machine %s is unhealthy, but it is not owned by a machine set, skipping remediation", t)
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonRemediationSkipped, "Machine %s is unhealthy (%s), but it is not owned by a machine set", t, reason)
+		return false, nil
+	}
+
+	if guards.zones.isSuspended(t) {
+		glog.V(3).Infof("Machine %s is unhealthy, but the remediation is suspended in its zone: %s", t, guards.zones.describe(guards.zones.zoneOf(t)))
+		return false, nil
+	}
+	if violation := guards.zones.violation(t); violation != "" {
+		glog.Warningf("Machine %s is unhealthy, but remediating it would exceed the concurrent remediations of its zone: %s", t, violation)
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonZoneRemediationLimited, "Machine %s is unhealthy (%s), but it is not remediated: %s", t, reason, violation)
+		return false, nil
+	}
+
+	if refused, backoff := guards.history.allowed(t); refused != "" {
+		if backoff == 0 {
+			glog.Warningf("Machine %s is unhealthy, but it is not remediated: %s", t, refused)
+			c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonRemediationAttemptsExceeded, "Machine %s is unhealthy (%s), but it is not remediated: %s", t, reason, refused)
+			return false, nil
+		}
+		glog.V(3).Infof("Machine %s is unhealthy, but it is not remediated yet: %s", t, refused)
+		if key, err := cache.MetaNamespaceKeyFunc(mhc); err == nil {
+			c.queue.AddAfter(key, backoff)
+		}
+		return false, nil
+	}
+
+	if violation := budgets.violation(t); violation != "" {
+		glog.Warningf("Machine %s is unhealthy, but remediating it would violate the disruption budget: %s", t, violation)
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonDisruptionBudgetViolated, "Machine %s is unhealthy (%s), but it is not remediated: %s", t, reason, violation)
+		return false, nil
+	}
+
+	// the machine is disrupted once its drain starts, so the other machines are refused by the
+	// limits during the drain rather than being drained at the same time
+	budgets.disrupt(t)
+	guards.zones.disrupt(t)
+	if controlPlane {
+		cp.disrupt(t)
+	}
+
+	if dryRun {
+		glog.Infof("Dry run: machine %s would have been remediated with the %s strategy: %s", t, mhc.Spec.RemediationStrategy, reason)
+		c.eventRecorder.Eventf(mhc, corev1.EventTypeNormal, EventReasonRemediationDryRun, "Machine %s would have been remediated with the %s strategy: %s", t, mhc.Spec.RemediationStrategy, reason)
+		return true, nil
+	}
+	drained, err := c.drain(mhc, t)
+	if err != nil || !drained {
+		return false, err
+	}
+	// the attempt is persisted before the remediation, otherwise it would be lost when the status
+	// update at the end of the sync fails, and the retry policy would not count it
+	if records := guards.history.withRecord(t, reason); records != nil {
+		if err := c.updateRemediationHistory(mhc, records); err != nil {
+			return false, fmt.Errorf("error recording the remediation of machine %s: %v", t, err)
+		}
+		guards.history.records = records
+	}
+	if err := strategy.remediate(t, reason); err != nil {
+		return false, err
+	}
+	metrics.MachineRemediations.WithLabelValues(mhc.Namespace, mhc.Name, reason.Reason).Inc()
+	return true, nil
+}
+
+// reportInvalidSpec sets the SpecValid condition of the machine health check to false with the
+// validation errors of its spec. The spec is admitted without the validation while the webhook is
+// not available, so the condition and the event report the errors, the event is emitted once the
+// errors change.
+func (c *Controller) reportInvalidSpec(mhc *healthcheckingv1alpha1.MachineHealthCheck, errs field.ErrorList) error {
+	status := mhc.Status.DeepCopy()
+	status.ObservedGeneration = mhc.Generation
+	setCondition(&status.Conditions, healthcheckingv1alpha1.MachineHealthCheckCondition{
+		Type:               healthcheckingv1alpha1.SpecValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.NewTime(c.now()),
+		Reason:             conditionReasonInvalidSpec,
+		Message:            errs.ToAggregate().Error(),
+	})
+	if equality.Semantic.DeepEqual(&mhc.Status, status) {
+		return nil
+	}
+
+	c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonInvalidSpec, "Invalid spec: %v", errs.ToAggregate())
+	mhc.Status = *status
+	_, err := c.mhcClient.HealthcheckingV1alpha1().MachineHealthChecks(mhc.Namespace).UpdateStatus(mhc)
+	return err
+}
+
+// updateRemediationHistory updates the remediation history of the machine health check status,
+// the machine health check is updated with the written status, so the status can be updated
+// again by the sync
+func (c *Controller) updateRemediationHistory(mhc *healthcheckingv1alpha1.MachineHealthCheck, history []healthcheckingv1alpha1.RemediationRecord) error {
+	required := mhc.DeepCopy()
+	required.Status.RemediationHistory = history
+	updated, err := c.mhcClient.HealthcheckingV1alpha1().MachineHealthChecks(mhc.Namespace).UpdateStatus(required)
+	if err != nil {
+		return err
+	}
+	mhc.ResourceVersion = updated.ResourceVersion
+	mhc.Status = updated.Status
+	return nil
+}
+
+// updateStatus updates the machine health check status when the observed machines or the conditions changed
+func (c *Controller) updateStatus(mhc *healthcheckingv1alpha1.MachineHealthCheck, expected, healthy int, dryRunRemediations []healthcheckingv1alpha1.DryRunRemediation, history []healthcheckingv1alpha1.RemediationRecord, suspendedZones []string, conditions ...healthcheckingv1alpha1.MachineHealthCheckCondition) error {
+	status := mhc.Status.DeepCopy()
+	status.ObservedGeneration = mhc.Generation
+	status.ExpectedMachines = pointer.Int32Ptr(int32(expected))
+	status.CurrentHealthy = pointer.Int32Ptr(int32(healthy))
+	// the machines are listed in a random order
+	sort.Slice(dryRunRemediations, func(i, j int) bool {
+		return dryRunRemediations[i].MachineName < dryRunRemediations[j].MachineName
+	})
+	status.DryRunRemediations = dryRunRemediations
+	status.RemediationHistory = history
+	status.SuspendedZones = suspendedZones
+	for _, condition := range conditions {
+		setCondition(&status.Conditions, condition)
+	}
+	if equality.Semantic.DeepEqual(&mhc.Status, status) {
+		return nil
+	}
+
+	mhc.Status = *status
+	_, err := c.mhcClient.HealthcheckingV1alpha1().MachineHealthChecks(mhc.Namespace).UpdateStatus(mhc)
+	return err
+}
@@ -0,0 +1,198 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+	"github.com/openshift/machine-health-check-operator/pkg/expression"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// machineSetKind contains the kind of the machine owner that replaces the deleted machines
+	machineSetKind = "MachineSet"
+)
+
+const (
+	// ReasonUnhealthyNodeCondition is the reason of a machine whose node has an unhealthy condition for longer than its timeout
+	ReasonUnhealthyNodeCondition = "UnhealthyNodeCondition"
+	// ReasonNodeNotFound is the reason of a machine referencing a node that does not exist
+	ReasonNodeNotFound = "NodeNotFound"
+	// ReasonNodeStartupTimeout is the reason of a machine without a node for longer than the node startup timeout
+	ReasonNodeStartupTimeout = "NodeStartupTimeout"
+	// ReasonNodeFlapping is the reason of a machine whose node conditions change too often
+	ReasonNodeFlapping = "NodeFlapping"
+	// ReasonUnhealthyExpression is the reason of a machine whose node matches an unhealthy expression
+	ReasonUnhealthyExpression = "UnhealthyExpression"
+	// ReasonRequiredPodNotReady is the reason of a machine whose node does not have a ready required pod for longer than its timeout
+	ReasonRequiredPodNotReady = "RequiredPodNotReady"
+)
+
+// unhealthyReasons contains all the reasons a machine can be unhealthy for
+var unhealthyReasons = []string{ReasonUnhealthyNodeCondition, ReasonNodeNotFound, ReasonNodeStartupTimeout, ReasonNodeFlapping, ReasonUnhealthyExpression, ReasonRequiredPodNotReady}
+
+// unhealthyReason describes why a machine is unhealthy, the reason is used in the events and metrics
+type unhealthyReason struct {
+	Reason  string
+	Message string
+}
+
+func (r *unhealthyReason) String() string {
+	return r.Message
+}
+
+// target is a machine selected by a machine health check together with its node
+type target struct {
+	Machine *unstructured.Unstructured
+	// Node is nil when the machine does not have a node yet or when its node was deleted
+	Node *corev1.Node
+	// nodeMissing is set when the machine references a node that does not exist
+	nodeMissing bool
+	// transitions contains the transitions of the node conditions observed by the controller
+	transitions map[corev1.NodeConditionType][]time.Time
+	// pods contains the pods scheduled on the node, they are only set with required pods
+	pods []*corev1.Pod
+}
+
+func (t *target) String() string {
+	return fmt.Sprintf("%s/%s", t.Machine.GetNamespace(), t.Machine.GetName())
+}
+
+// hasMachineSetOwner returns true when the machine is controlled by a machine set,
+// so it is replaced once it is deleted
+func hasMachineSetOwner(machine *unstructured.Unstructured) bool {
+	return machineSetName(machine) != ""
+}
+
+// machineSetName returns the name of the machine set controlling the machine, if any
+func machineSetName(machine *unstructured.Unstructured) string {
+	for _, ref := range machine.GetOwnerReferences() {
+		if ref.Kind == machineSetKind && ref.Controller != nil && *ref.Controller {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// needsRemediation evaluates the target against the machine health check spec. It returns the
+// reason when the target has to be remediated or nil when it is healthy and, when the target
+// can become unhealthy once a timeout expires or a flapping node can recover, the duration after
+// which it has to be checked again.
+func (t *target) needsRemediation(spec *healthcheckingv1alpha1.MachineHealthCheckSpec, now time.Time) (*unhealthyReason, time.Duration) {
+	if t.nodeMissing {
+		return &unhealthyReason{
+			Reason:  ReasonNodeNotFound,
+			Message: fmt.Sprintf("node %q does not exist", machine.NodeName(t.Machine)),
+		}, 0
+	}
+	if t.Node == nil {
+		return t.needsNode(spec.NodeStartupTimeout, now)
+	}
+
+	var nextCheck time.Duration
+	for _, c := range spec.UnhealthyConditions {
+		nodeCondition := getNodeCondition(t.Node, c.Type)
+		if nodeCondition == nil || nodeCondition.Status != c.Status {
+			continue
+		}
+		elapsed := now.Sub(nodeCondition.LastTransitionTime.Time)
+		if elapsed >= c.Timeout.Duration {
+			return &unhealthyReason{
+				Reason:  ReasonUnhealthyNodeCondition,
+				Message: fmt.Sprintf("condition %s=%s for more than %s", c.Type, c.Status, c.Timeout.Duration),
+			}, 0
+		}
+		if remaining := c.Timeout.Duration - elapsed; nextCheck == 0 || remaining < nextCheck {
+			nextCheck = remaining
+		}
+	}
+	reason, next := t.evaluateExpressions(spec.UnhealthyExpressions, now)
+	if reason != nil {
+		return reason, 0
+	}
+	if next > 0 && (nextCheck == 0 || next < nextCheck) {
+		nextCheck = next
+	}
+	reason, next = t.checkRequiredPods(spec.RequiredPods, now)
+	if reason != nil {
+		return reason, 0
+	}
+	if next > 0 && (nextCheck == 0 || next < nextCheck) {
+		nextCheck = next
+	}
+	// the node transitions do not trigger a sync once they stop, so the flapping node is checked
+	// again when it may recover
+	if reason, next := t.isFlapping(spec.Flapping, spec.UnhealthyConditions, now); reason != nil {
+		return reason, next
+	}
+	return nil, nextCheck
+}
+
+// evaluateExpressions evaluates the unhealthy expressions against the node and the machine of the
+// target. It returns the reason when one of them is true or, when one of them can become true once
+// a condition age grows, the duration after which the target has to be checked again.
+func (t *target) evaluateExpressions(expressions []healthcheckingv1alpha1.UnhealthyExpression, now time.Time) (*unhealthyReason, time.Duration) {
+	if len(expressions) == 0 {
+		return nil, 0
+	}
+	env := &expression.Environment{
+		Conditions:   map[string]expression.Condition{},
+		Labels:       t.Node.Labels,
+		Annotations:  t.Node.Annotations,
+		MachinePhase: machine.Phase(t.Machine),
+		Now:          now,
+	}
+	for _, c := range t.Node.Status.Conditions {
+		env.Conditions[string(c.Type)] = expression.Condition{Status: string(c.Status), LastTransitionTime: c.LastTransitionTime.Time}
+	}
+
+	var nextCheck time.Duration
+	for _, e := range expressions {
+		// the expressions are validated with the spec
+		parsed, err := expression.Parse(e.Expression)
+		if err != nil {
+			continue
+		}
+		unhealthy, recheck := parsed.Evaluate(env)
+		if unhealthy {
+			return &unhealthyReason{
+				Reason:  ReasonUnhealthyExpression,
+				Message: fmt.Sprintf("expression %s is true: %s", e.Name, e.Expression),
+			}, 0
+		}
+		if recheck > 0 && (nextCheck == 0 || recheck < nextCheck) {
+			nextCheck = recheck
+		}
+	}
+	return nil, nextCheck
+}
+
+// needsNode evaluates the target without a node against the node startup timeout. The machine
+// without a node is still being provisioned, unless it exists for longer than the timeout.
+func (t *target) needsNode(nodeStartupTimeout *metav1.Duration, now time.Time) (*unhealthyReason, time.Duration) {
+	if nodeStartupTimeout == nil || nodeStartupTimeout.Duration == 0 || t.Machine.GetDeletionTimestamp() != nil {
+		return nil, 0
+	}
+	elapsed := now.Sub(t.Machine.GetCreationTimestamp().Time)
+	if elapsed >= nodeStartupTimeout.Duration {
+		return &unhealthyReason{
+			Reason:  ReasonNodeStartupTimeout,
+			Message: fmt.Sprintf("node did not join the cluster in %s", nodeStartupTimeout.Duration),
+		}, 0
+	}
+	return nil, nodeStartupTimeout.Duration - elapsed
+}
+
+func getNodeCondition(node *corev1.Node, conditionType corev1.NodeConditionType) *corev1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == conditionType {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
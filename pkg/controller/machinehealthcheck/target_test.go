@@ -0,0 +1,137 @@
+package machinehealthcheck
+
+import (
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNeedsRemediation(t *testing.T) {
+	// the timestamps of the unstructured machines have a precision of a second
+	now := time.Now().Truncate(time.Second)
+	spec := &healthcheckingv1alpha1.MachineHealthCheckSpec{
+		UnhealthyConditions: []healthcheckingv1alpha1.UnhealthyCondition{{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: 5 * time.Minute},
+		}, {
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionFalse,
+			Timeout: metav1.Duration{Duration: 10 * time.Minute},
+		}},
+		NodeStartupTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+
+	tests := []struct {
+		name              string
+		target            *target
+		expectedReason    string
+		expectedNextCheck time.Duration
+	}{{
+		name:              "machine without a node within the node startup timeout",
+		target:            &target{Machine: newMachineCreatedAt("machine", now.Add(-time.Minute))},
+		expectedNextCheck: 9 * time.Minute,
+	}, {
+		name:           "machine without a node after the node startup timeout",
+		target:         &target{Machine: newMachineCreatedAt("machine", now.Add(-11*time.Minute))},
+		expectedReason: ReasonNodeStartupTimeout,
+	}, {
+		name:           "machine with a deleted node",
+		target:         &target{Machine: newMachine("machine", "node", true), nodeMissing: true},
+		expectedReason: ReasonNodeNotFound,
+	}, {
+		name:   "healthy node",
+		target: &target{Machine: newMachine("machine", "node", true), Node: newNode("node", corev1.ConditionTrue, now.Add(-time.Hour))},
+	}, {
+		name:              "unhealthy node within the timeout",
+		target:            &target{Machine: newMachine("machine", "node", true), Node: newNode("node", corev1.ConditionFalse, now.Add(-time.Minute))},
+		expectedNextCheck: 9 * time.Minute,
+	}, {
+		name:           "unhealthy node after the timeout",
+		target:         &target{Machine: newMachine("machine", "node", true), Node: newNode("node", corev1.ConditionUnknown, now.Add(-6*time.Minute))},
+		expectedReason: ReasonUnhealthyNodeCondition,
+	}}
+
+	for _, tc := range tests {
+		reason, nextCheck := tc.target.needsRemediation(spec, now)
+		got := ""
+		if reason != nil {
+			got = reason.Reason
+		}
+		if got != tc.expectedReason {
+			t.Errorf("%s: expected reason %q, got %q", tc.name, tc.expectedReason, got)
+		}
+		if nextCheck != tc.expectedNextCheck {
+			t.Errorf("%s: expected next check in %s, got %s", tc.name, tc.expectedNextCheck, nextCheck)
+		}
+	}
+
+	// the machines without a node are not checked without a node startup timeout
+	spec.NodeStartupTimeout = nil
+	if reason, _ := (&target{Machine: newMachineCreatedAt("machine", now.Add(-time.Hour))}).needsRemediation(spec, now); reason != nil {
+		t.Errorf("expected machine without a node to be healthy without a node startup timeout, got %s", reason)
+	}
+}
+
+func TestUnhealthyExpressions(t *testing.T) {
+	now := time.Now()
+	spec := &healthcheckingv1alpha1.MachineHealthCheckSpec{
+		UnhealthyExpressions: []healthcheckingv1alpha1.UnhealthyExpression{{
+			Name:       "not ready",
+			Expression: `condition("Ready") != "True" && conditionAge("Ready") >= 5m && !hasLabel("example.com/maintenance")`,
+		}, {
+			Name:       "failed",
+			Expression: `machinePhase() == "Failed"`,
+		}},
+	}
+	withLabel := func(node *corev1.Node) *corev1.Node {
+		node.Labels = map[string]string{"example.com/maintenance": ""}
+		return node
+	}
+	failed := newMachine("machine", "node", true)
+	unstructured.SetNestedField(failed.Object, "Failed", "status", "phase")
+
+	tests := []struct {
+		name              string
+		target            *target
+		expectedReason    string
+		expectedNextCheck time.Duration
+	}{{
+		name:   "healthy node",
+		target: &target{Machine: newMachine("machine", "node", true), Node: newNode("node", corev1.ConditionTrue, now.Add(-time.Hour))},
+	}, {
+		name:              "unhealthy node within the condition age",
+		target:            &target{Machine: newMachine("machine", "node", true), Node: newNode("node", corev1.ConditionFalse, now.Add(-time.Minute))},
+		expectedNextCheck: 4 * time.Minute,
+	}, {
+		name:           "unhealthy node after the condition age",
+		target:         &target{Machine: newMachine("machine", "node", true), Node: newNode("node", corev1.ConditionFalse, now.Add(-10*time.Minute))},
+		expectedReason: ReasonUnhealthyExpression,
+	}, {
+		name:   "unhealthy node with the excluding label",
+		target: &target{Machine: newMachine("machine", "node", true), Node: withLabel(newNode("node", corev1.ConditionFalse, now.Add(-10*time.Minute)))},
+	}, {
+		name:           "failed machine",
+		target:         &target{Machine: failed, Node: newNode("node", corev1.ConditionTrue, now.Add(-time.Hour))},
+		expectedReason: ReasonUnhealthyExpression,
+	}}
+
+	for _, tc := range tests {
+		reason, nextCheck := tc.target.needsRemediation(spec, now)
+		got := ""
+		if reason != nil {
+			got = reason.Reason
+		}
+		if got != tc.expectedReason {
+			t.Errorf("%s: expected reason %q, got %q", tc.name, tc.expectedReason, got)
+		}
+		if nextCheck != tc.expectedNextCheck {
+			t.Errorf("%s: expected next check in %s, got %s", tc.name, tc.expectedNextCheck, nextCheck)
+		}
+	}
+}
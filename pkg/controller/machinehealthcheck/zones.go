@@ -0,0 +1,169 @@
+package machinehealthcheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// EventReasonZoneOutageDetected is the reason of the event reporting a zone whose machines are mostly unhealthy
+	EventReasonZoneOutageDetected = "ZoneOutageDetected"
+	// EventReasonZoneRemediationResumed is the reason of the event reporting a recovered zone
+	EventReasonZoneRemediationResumed = "ZoneRemediationResumed"
+	// EventReasonZoneRemediationLimited is the reason of the event reporting an unhealthy machine whose remediation would exceed the concurrent remediations of its zone
+	EventReasonZoneRemediationLimited = "ZoneRemediationLimited"
+)
+
+// zone tracks the machines of a zone
+type zone struct {
+	// members is the number of the machines in the zone
+	members int
+	// unhealthy is the number of the unhealthy machines in the zone
+	unhealthy int
+	// remediating contains the names of the machines of the zone being remediated
+	remediating []string
+	// suspended is true when the zone is considered unhealthy as a whole
+	suspended bool
+}
+
+// zones tracks the targets of a machine health check by zone during a single sync
+type zones struct {
+	policy *healthcheckingv1alpha1.ZoneRemediationPolicy
+	byName map[string]*zone
+}
+
+// newZones groups the targets of the defaulted machine health check by zone, the targets being
+// remediated and the unhealthy ones are counted for every zone
+func newZones(mhc *healthcheckingv1alpha1.MachineHealthCheck, targets []*target, reasons map[*target]*unhealthyReason, remediating map[*target]bool) (*zones, error) {
+	z := &zones{policy: mhc.Spec.ZoneRemediation, byName: map[string]*zone{}}
+	if z.policy == nil {
+		return z, nil
+	}
+	for _, t := range targets {
+		name := z.zoneOf(t)
+		if name == "" {
+			continue
+		}
+		if z.byName[name] == nil {
+			z.byName[name] = &zone{}
+		}
+		zone := z.byName[name]
+		zone.members++
+		if reasons[t] != nil {
+			zone.unhealthy++
+		}
+		if remediating[t] || isBeingRemediated(t.Machine) {
+			zone.remediating = append(zone.remediating, t.Machine.GetName())
+		}
+	}
+	for _, zone := range z.byName {
+		threshold, err := intstr.GetValueFromIntOrPercent(z.policy.OutageThreshold, zone.members, true)
+		if err != nil {
+			return nil, err
+		}
+		if threshold < 1 {
+			threshold = 1
+		}
+		zone.suspended = zone.members > 1 && zone.unhealthy >= threshold
+	}
+	return z, nil
+}
+
+// reportSuspendedZones reports the zones in which the remediation was suspended or resumed since the last sync
+func (c *Controller) reportSuspendedZones(mhc *healthcheckingv1alpha1.MachineHealthCheck, zones *zones, suspended []string) {
+	wasSuspended := map[string]bool{}
+	for _, name := range mhc.Status.SuspendedZones {
+		wasSuspended[name] = true
+	}
+	isSuspended := map[string]bool{}
+	for _, name := range suspended {
+		isSuspended[name] = true
+		if !wasSuspended[name] {
+			glog.Warningf("Machine health check %s/%s: %s, suspending remediation in the zone", mhc.Namespace, mhc.Name, zones.describe(name))
+			c.eventRecorder.Eventf(mhc, corev1.EventTypeWarning, EventReasonZoneOutageDetected, "Remediation suspended in zone %s, %s", name, zones.describe(name))
+		}
+	}
+	for _, name := range mhc.Status.SuspendedZones {
+		if !isSuspended[name] {
+			glog.Infof("Machine health check %s/%s: %s, resuming remediation in the zone", mhc.Namespace, mhc.Name, zones.describe(name))
+			c.eventRecorder.Eventf(mhc, corev1.EventTypeNormal, EventReasonZoneRemediationResumed, "Remediation resumed in zone %s, %s", name, zones.describe(name))
+		}
+	}
+}
+
+// zoneOf returns the zone of the target, or an empty string when it is not known
+func (z *zones) zoneOf(t *target) string {
+	if z.policy == nil || t.Node == nil {
+		return ""
+	}
+	return t.Node.Labels[z.policy.TopologyKey]
+}
+
+// suspended returns the sorted names of the zones considered unhealthy as a whole
+func (z *zones) suspended() []string {
+	names := []string{}
+	for name, zone := range z.byName {
+		if zone.suspended {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// describe returns a human readable description of the health of the zone
+func (z *zones) describe(name string) string {
+	zone := z.byName[name]
+	if zone == nil {
+		return fmt.Sprintf("zone %s has no machines", name)
+	}
+	return fmt.Sprintf("%d of %d machines in zone %s are unhealthy", zone.unhealthy, zone.members, name)
+}
+
+// isSuspended returns true when the zone of the target is considered unhealthy as a whole
+func (z *zones) isSuspended(t *target) bool {
+	zone := z.byName[z.zoneOf(t)]
+	return zone != nil && zone.suspended
+}
+
+// violation returns the reason why remediating the target would exceed the concurrent remediations
+// of its zone, or an empty string when the machine can be remediated.
+func (z *zones) violation(t *target) string {
+	name := z.zoneOf(t)
+	zone := z.byName[name]
+	if zone == nil {
+		return ""
+	}
+	others := []string{}
+	for _, m := range zone.remediating {
+		if m != t.Machine.GetName() {
+			others = append(others, m)
+		}
+	}
+	if int32(len(others)) >= z.policy.MaxConcurrent {
+		return fmt.Sprintf("machines %s of zone %s are being remediated, the maximum is %d", strings.Join(others, ", "), name, z.policy.MaxConcurrent)
+	}
+	return ""
+}
+
+// disrupt records the remediated target, so it is counted by the remaining remediations of the sync
+func (z *zones) disrupt(t *target) {
+	zone := z.byName[z.zoneOf(t)]
+	if zone == nil {
+		return
+	}
+	// the target being drained is already counted
+	for _, name := range zone.remediating {
+		if name == t.Machine.GetName() {
+			return
+		}
+	}
+	zone.remediating = append(zone.remediating, t.Machine.GetName())
+}
@@ -0,0 +1,202 @@
+package machinehealthcheck
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/controller/machine"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestZoneRemediation(t *testing.T) {
+	now := time.Now()
+	zonedNode := func(name, zone string, ready corev1.ConditionStatus) runtime.Object {
+		node := newNode(name, ready, now.Add(-10*time.Minute))
+		node.Labels = map[string]string{corev1.LabelZoneFailureDomain: zone}
+		return node
+	}
+	machines := func(names ...string) []runtime.Object {
+		objs := []runtime.Object{}
+		for _, name := range names {
+			objs = append(objs, newMachine(name, name, true))
+		}
+		return objs
+	}
+
+	tests := []struct {
+		name           string
+		nodes          []runtime.Object
+		machines       []runtime.Object
+		suspendedZones []string
+		// expectedRebooted is the number of the machines with the reboot annotation after the sync
+		expectedRebooted       int
+		expectedSuspendedZones []string
+		expectedEvents         []string
+	}{{
+		name:             "concurrent remediations limited per zone",
+		nodes:            []runtime.Object{zonedNode("a", "a", corev1.ConditionTrue), zonedNode("b", "a", corev1.ConditionUnknown), zonedNode("c", "a", corev1.ConditionUnknown)},
+		machines:         machines("a", "b", "c"),
+		expectedRebooted: 1,
+		expectedEvents:   []string{EventReasonMachineRebootRequested, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition, EventReasonZoneRemediationLimited},
+	}, {
+		name:             "zones remediated independently",
+		nodes:            []runtime.Object{zonedNode("a", "a", corev1.ConditionTrue), zonedNode("b", "a", corev1.ConditionUnknown), zonedNode("c", "b", corev1.ConditionTrue), zonedNode("d", "b", corev1.ConditionUnknown)},
+		machines:         machines("a", "b", "c", "d"),
+		expectedRebooted: 2,
+		expectedEvents:   []string{EventReasonMachineRebootRequested, EventReasonMachineRebootRequested, EventReasonNodeDrainStarted, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition},
+	}, {
+		name:                   "zone outage detected",
+		nodes:                  []runtime.Object{zonedNode("a", "a", corev1.ConditionUnknown), zonedNode("b", "a", corev1.ConditionUnknown), zonedNode("c", "b", corev1.ConditionTrue)},
+		machines:               machines("a", "b", "c"),
+		expectedSuspendedZones: []string{"a"},
+		expectedEvents:         []string{ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition, EventReasonZoneOutageDetected},
+	}, {
+		name:                   "zone outage in progress",
+		nodes:                  []runtime.Object{zonedNode("a", "a", corev1.ConditionUnknown), zonedNode("b", "a", corev1.ConditionUnknown)},
+		machines:               machines("a", "b"),
+		suspendedZones:         []string{"a"},
+		expectedSuspendedZones: []string{"a"},
+		expectedEvents:         []string{ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition},
+	}, {
+		name:             "zone recovered",
+		nodes:            []runtime.Object{zonedNode("a", "a", corev1.ConditionTrue), zonedNode("b", "a", corev1.ConditionUnknown)},
+		machines:         machines("a", "b"),
+		suspendedZones:   []string{"a"},
+		expectedRebooted: 1,
+		expectedEvents:   []string{EventReasonMachineRebootRequested, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition, EventReasonZoneRemediationResumed},
+	}, {
+		name:             "single machine zone remediated",
+		nodes:            []runtime.Object{zonedNode("a", "a", corev1.ConditionUnknown), zonedNode("b", "b", corev1.ConditionTrue)},
+		machines:         machines("a", "b"),
+		expectedRebooted: 1,
+		expectedEvents:   []string{EventReasonMachineRebootRequested, EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition},
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.RemediationStrategy = healthcheckingv1alpha1.RemediationStrategyReboot
+		mhc.Spec.ZoneRemediation = &healthcheckingv1alpha1.ZoneRemediationPolicy{}
+		mhc.Status.SuspendedZones = tc.suspendedZones
+		c, recorder := newFakeController(t, tc.nodes, tc.machines, []runtime.Object{mhc}, stopCh)
+		c.now = func() time.Time { return now }
+
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		rebooted := 0
+		for _, obj := range tc.machines {
+			m, err := c.machineClient.Resource(machine.Resource).Namespace(namespace).Get(obj.(*unstructured.Unstructured).GetName(), metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("%s: failed to get machine: %v", tc.name, err)
+			}
+			if _, ok := m.GetAnnotations()[healthcheckingv1alpha1.RebootAnnotation]; ok {
+				rebooted++
+			}
+		}
+		if rebooted != tc.expectedRebooted {
+			t.Errorf("%s: expected %d rebooted machines, got %d", tc.name, tc.expectedRebooted, rebooted)
+		}
+
+		updated, err := c.mhcClient.HealthcheckingV1alpha1().MachineHealthChecks(namespace).Get(mhcName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: failed to get machine health check: %v", tc.name, err)
+		}
+		if strings.Join(updated.Status.SuspendedZones, ",") != strings.Join(tc.expectedSuspendedZones, ",") {
+			t.Errorf("%s: expected suspended zones %v, got %v", tc.name, tc.expectedSuspendedZones, updated.Status.SuspendedZones)
+		}
+
+		// the machines are checked in a random order
+		reasons := events(recorder)
+		sort.Strings(reasons)
+		if strings.Join(reasons, ",") != strings.Join(tc.expectedEvents, ",") {
+			t.Errorf("%s: expected events %v, got %v", tc.name, tc.expectedEvents, reasons)
+		}
+		close(stopCh)
+	}
+}
+
+func TestZoneRemediationDuringDrain(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	zonedNode := func(name string, ready corev1.ConditionStatus) runtime.Object {
+		node := newNode(name, ready, now.Add(-10*time.Minute))
+		node.Labels = map[string]string{corev1.LabelZoneFailureDomain: "a"}
+		return node
+	}
+	draining := func(m *unstructured.Unstructured) *unstructured.Unstructured {
+		m.SetAnnotations(map[string]string{healthcheckingv1alpha1.DrainStartedAnnotation: now.Add(-time.Minute).UTC().Format(time.RFC3339)})
+		return m
+	}
+	objects := []runtime.Object{
+		zonedNode("a", corev1.ConditionTrue), zonedNode("b", corev1.ConditionUnknown), zonedNode("c", corev1.ConditionUnknown),
+		newPod("web-b", "b", "ReplicaSet"), newPod("web-c", "c", "ReplicaSet"),
+	}
+
+	tests := []struct {
+		name     string
+		machines []runtime.Object
+		// expectedDraining is the number of the machines with the drain started annotation after the sync
+		expectedDraining int
+		expectedEvents   []string
+	}{{
+		name:             "single drain started in the zone",
+		machines:         []runtime.Object{newMachine("a", "a", true), newMachine("b", "b", true), newMachine("c", "c", true)},
+		expectedDraining: 1,
+		expectedEvents:   []string{EventReasonNodeDrainStarted, ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition, EventReasonZoneRemediationLimited},
+	}, {
+		name:             "drain continued in the zone",
+		machines:         []runtime.Object{newMachine("a", "a", true), draining(newMachine("b", "b", true)), newMachine("c", "c", true)},
+		expectedDraining: 1,
+		expectedEvents:   []string{ReasonUnhealthyNodeCondition, ReasonUnhealthyNodeCondition, EventReasonZoneRemediationLimited},
+	}}
+
+	for _, tc := range tests {
+		stopCh := make(chan struct{})
+		mhc := newMachineHealthCheck(nil)
+		mhc.Spec.RemediationStrategy = healthcheckingv1alpha1.RemediationStrategyReboot
+		mhc.Spec.DrainTimeout = &metav1.Duration{Duration: 5 * time.Minute}
+		mhc.Spec.ZoneRemediation = &healthcheckingv1alpha1.ZoneRemediationPolicy{MaxConcurrent: 1}
+		c, recorder := newFakeController(t, objects, tc.machines, []runtime.Object{mhc}, stopCh)
+		c.now = func() time.Time { return now }
+		// the evicted pods are terminated by their kubelet, so the drain does not complete during the sync
+		c.kubeClient.(*fakekube.Clientset).PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			return action.GetSubresource() == "eviction", nil, nil
+		})
+
+		if err := c.sync(namespace + "/" + mhcName); err != nil {
+			t.Errorf("%s: failed to sync: %v", tc.name, err)
+		}
+
+		draining := 0
+		for _, obj := range tc.machines {
+			m, err := c.machineClient.Resource(machine.Resource).Namespace(namespace).Get(obj.(*unstructured.Unstructured).GetName(), metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("%s: failed to get machine: %v", tc.name, err)
+			}
+			if _, ok := m.GetAnnotations()[healthcheckingv1alpha1.DrainStartedAnnotation]; ok {
+				draining++
+			}
+		}
+		if draining != tc.expectedDraining {
+			t.Errorf("%s: expected %d draining machines, got %d", tc.name, tc.expectedDraining, draining)
+		}
+
+		// the machines are checked in a random order
+		reasons := events(recorder)
+		sort.Strings(reasons)
+		if strings.Join(reasons, ",") != strings.Join(tc.expectedEvents, ",") {
+			t.Errorf("%s: expected events %v, got %v", tc.name, tc.expectedEvents, reasons)
+		}
+		close(stopCh)
+	}
+}
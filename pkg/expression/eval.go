@@ -0,0 +1,100 @@
+package expression
+
+import (
+	"time"
+)
+
+// evaluator evaluates the nodes of a type checked expression against an environment
+type evaluator struct {
+	env *Environment
+	// recheck is the earliest duration after which a comparison of a condition age changes
+	recheck time.Duration
+}
+
+func (ev *evaluator) eval(n node) value {
+	switch n := n.(type) {
+	case *literal:
+		return n.value
+	case *call:
+		return ev.call(n)
+	case *not:
+		return value{t: typeBool, b: !ev.eval(n.x).b}
+	case *binary:
+		// the operands are evaluated without short-circuit, so the recheck covers all the comparisons
+		x, y := ev.eval(n.x), ev.eval(n.y)
+		switch n.op {
+		case tokenAnd:
+			return value{t: typeBool, b: x.b && y.b}
+		case tokenOr:
+			return value{t: typeBool, b: x.b || y.b}
+		case tokenEqual:
+			return value{t: typeBool, b: x == y}
+		case tokenNotEqual:
+			return value{t: typeBool, b: x != y}
+		}
+		ev.watch(n.op, x, y)
+		return value{t: typeBool, b: compare(n.op, x.d, y.d)}
+	}
+	return value{}
+}
+
+func (ev *evaluator) call(n *call) value {
+	switch n.name {
+	case "condition":
+		return value{t: typeString, s: ev.env.Conditions[n.arg].Status}
+	case "conditionAge":
+		condition, ok := ev.env.Conditions[n.arg]
+		if !ok || condition.LastTransitionTime.IsZero() {
+			return value{t: typeDuration}
+		}
+		return value{t: typeDuration, d: ev.env.Now.Sub(condition.LastTransitionTime), aging: true}
+	case "label":
+		return value{t: typeString, s: ev.env.Labels[n.arg]}
+	case "hasLabel":
+		_, ok := ev.env.Labels[n.arg]
+		return value{t: typeBool, b: ok}
+	case "annotation":
+		return value{t: typeString, s: ev.env.Annotations[n.arg]}
+	case "hasAnnotation":
+		_, ok := ev.env.Annotations[n.arg]
+		return value{t: typeBool, b: ok}
+	case "machinePhase":
+		return value{t: typeString, s: ev.env.MachinePhase}
+	}
+	return value{}
+}
+
+// watch records when the comparison of a growing condition age with a fixed duration changes, the
+// comparison of two ages does not change since both of them grow
+func (ev *evaluator) watch(op tokenKind, x, y value) {
+	if x.aging == y.aging {
+		return
+	}
+	age, limit := x.d, y.d
+	if y.aging {
+		age, limit = y.d, x.d
+	}
+	remaining := limit - age
+	// the strict comparisons of the age with the limit change once the age exceeds the limit
+	if (op == tokenGreater && !y.aging) || (op == tokenLess && y.aging) ||
+		(op == tokenLessEqual && !y.aging) || (op == tokenGreaterEqual && y.aging) {
+		remaining += time.Nanosecond
+	}
+	if remaining > 0 && (ev.recheck == 0 || remaining < ev.recheck) {
+		ev.recheck = remaining
+	}
+}
+
+func compare(op tokenKind, x, y time.Duration) bool {
+	switch op {
+	case tokenLess:
+		return x < y
+	case tokenLessEqual:
+		return x <= y
+	case tokenGreater:
+		return x > y
+	case tokenGreaterEqual:
+		return x >= y
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+// Package expression implements the boolean expressions evaluated against a node and its machine
+// to decide whether the machine is unhealthy.
+//
+// An expression combines comparisons with the && (and), || (or) and ! (not) operators and the
+// parentheses. The operands are string literals quoted with '"', durations such as 5m or 1h30m,
+// the true and false literals and the calls of the functions:
+//
+//	condition("Ready")      the status of the node condition, empty when the node does not have it
+//	conditionAge("Ready")   the duration since the last transition of the node condition
+//	label("key")            the value of the node label, empty when the node does not have it
+//	hasLabel("key")         whether the node has the label
+//	annotation("key")       the value of the node annotation, empty when the node does not have it
+//	hasAnnotation("key")    whether the node has the annotation
+//	machinePhase()          the phase of the machine
+//
+// The strings and the booleans are compared with == and !=, the durations with <, <=, > and >=.
+// For example:
+//
+//	condition("DiskPressure") == "True" && condition("MemoryPressure") == "True"
+//	condition("Ready") == "Unknown" && conditionAge("Ready") >= 5m && !hasLabel("example.com/maintenance")
+//
+// The expressions are sandboxed: they are type checked once parsed, they can not loop and they
+// only read the evaluated node and machine.
+package expression
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxLength is the maximum length of an expression
+	MaxLength = 1024
+	// maxDepth is the maximum nesting of the operators and the parentheses of an expression
+	maxDepth = 32
+)
+
+// SyntaxError describes why an expression can not be parsed
+type SyntaxError struct {
+	// Pos is the position of the error in the expression, starting at 1
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos, e.Msg)
+}
+
+// Condition is a node condition the expressions are evaluated against
+type Condition struct {
+	Status             string
+	LastTransitionTime time.Time
+}
+
+// Environment contains the node and the machine the expressions are evaluated against
+type Environment struct {
+	Conditions   map[string]Condition
+	Labels       map[string]string
+	Annotations  map[string]string
+	MachinePhase string
+	// Now is the time the condition ages are measured at
+	Now time.Time
+}
+
+// Expression is a parsed and type checked boolean expression
+type Expression struct {
+	src  string
+	root node
+}
+
+// Parse parses the boolean expression, the returned error is a *SyntaxError
+func Parse(src string) (*Expression, error) {
+	if len(src) > MaxLength {
+		return nil, &SyntaxError{Pos: MaxLength + 1, Msg: fmt.Sprintf("expression is longer than %d characters", MaxLength)}
+	}
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s, expected an operator or the end of expression", tok)}
+	}
+	if root.valueType() != typeBool {
+		return nil, &SyntaxError{Pos: 1, Msg: fmt.Sprintf("expression is a %s, expected a boolean, e.g. a comparison", root.valueType())}
+	}
+	return &Expression{src: src, root: root}, nil
+}
+
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Evaluate evaluates the expression against the environment. When the result depends on the
+// condition ages, the recheck is the duration after which the result can change, or zero.
+func (e *Expression) Evaluate(env *Environment) (bool, time.Duration) {
+	ev := &evaluator{env: env}
+	return ev.eval(e.root).b, ev.recheck
+}
@@ -0,0 +1,175 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// expectedError is a substring of the expected error, the expression is valid when it is empty
+		expectedError string
+	}{{
+		name: "condition comparisons",
+		src:  `condition("DiskPressure") == "True" && condition("MemoryPressure") == "True"`,
+	}, {
+		name: "condition age and label",
+		src:  `condition("Ready") == "Unknown" && conditionAge("Ready") >= 5m && !hasLabel("example.com/maintenance")`,
+	}, {
+		name: "parentheses and escaped string",
+		src:  `(annotation("example.com/note") != "say \"hi\"" || machinePhase() == "Failed") && true`,
+	}, {
+		name:          "empty expression",
+		src:           "",
+		expectedError: "column 1: unexpected end of expression, expected an operand",
+	}, {
+		name:          "single ampersand",
+		src:           `hasLabel("a") & hasLabel("b")`,
+		expectedError: `column 15: unexpected character '&', did you mean "&&"?`,
+	}, {
+		name:          "unterminated string",
+		src:           `condition("Ready) == "True"`,
+		expectedError: "column 27: unterminated string",
+	}, {
+		name:          "duration without unit",
+		src:           `conditionAge("Ready") > 5`,
+		expectedError: "column 25: invalid duration 5",
+	}, {
+		name:          "unknown function",
+		src:           `status("Ready") == "True"`,
+		expectedError: "column 1: unknown function status, expected one of annotation, condition, conditionAge",
+	}, {
+		name:          "missing argument",
+		src:           `condition() == "True"`,
+		expectedError: `column 11: unexpected ")", function condition requires a string argument`,
+	}, {
+		name:          "argument of a function without arguments",
+		src:           `machinePhase("Failed") == "Failed"`,
+		expectedError: "column 14: function machinePhase takes no arguments",
+	}, {
+		name:          "unclosed parenthesis",
+		src:           `(hasLabel("a") || hasLabel("b")`,
+		expectedError: "column 32: unexpected end of expression, expected \")\" to close the parenthesis at column 1",
+	}, {
+		name:          "mismatched types",
+		src:           `condition("Ready") == 5m`,
+		expectedError: "column 20: operator \"==\" compares a string with a duration",
+	}, {
+		name:          "ordered strings",
+		src:           `label("a") < "b"`,
+		expectedError: "column 12: operator \"<\" requires durations",
+	}, {
+		name:          "equal durations",
+		src:           `conditionAge("Ready") == 5m`,
+		expectedError: "column 23: operator \"==\" can not compare durations",
+	}, {
+		name:          "chained comparisons",
+		src:           `hasLabel("a") == true == false`,
+		expectedError: "column 23: comparisons can not be chained",
+	}, {
+		name:          "string expression",
+		src:           `condition("Ready")`,
+		expectedError: "column 1: expression is a string, expected a boolean",
+	}, {
+		name:          "logical operator on a string",
+		src:           `hasLabel("a") && label("b")`,
+		expectedError: "column 15: operator \"&&\" requires boolean operands, got a string",
+	}, {
+		name:          "trailing tokens",
+		src:           `hasLabel("a") hasLabel("b")`,
+		expectedError: "column 15: unexpected identifier hasLabel, expected an operator or the end of expression",
+	}, {
+		name:          "deeply nested",
+		src:           strings.Repeat("(", maxDepth+1) + "true" + strings.Repeat(")", maxDepth+1),
+		expectedError: "nested more than",
+	}, {
+		name:          "too long",
+		src:           strings.Repeat(" ", MaxLength) + "true",
+		expectedError: "longer than",
+	}}
+
+	for _, tc := range tests {
+		_, err := Parse(tc.src)
+		switch {
+		case tc.expectedError == "" && err != nil:
+			t.Errorf("%s: expected no error, got %v", tc.name, err)
+		case tc.expectedError != "" && err == nil:
+			t.Errorf("%s: expected error %q, got none", tc.name, tc.expectedError)
+		case err != nil && !strings.Contains(err.Error(), tc.expectedError):
+			t.Errorf("%s: expected error %q, got %q", tc.name, tc.expectedError, err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	env := &Environment{
+		Conditions: map[string]Condition{
+			"Ready":          {Status: "Unknown", LastTransitionTime: now.Add(-3 * time.Minute)},
+			"DiskPressure":   {Status: "True", LastTransitionTime: now.Add(-time.Hour)},
+			"MemoryPressure": {Status: "False", LastTransitionTime: now.Add(-time.Hour)},
+		},
+		Labels:       map[string]string{"example.com/pool": "gpu"},
+		Annotations:  map[string]string{"example.com/maintenance": ""},
+		MachinePhase: "Running",
+		Now:          now,
+	}
+
+	tests := []struct {
+		name            string
+		src             string
+		expected        bool
+		expectedRecheck time.Duration
+	}{{
+		name:     "both pressures",
+		src:      `condition("DiskPressure") == "True" && condition("MemoryPressure") == "True"`,
+		expected: false,
+	}, {
+		name:     "any pressure",
+		src:      `condition("DiskPressure") == "True" || condition("MemoryPressure") == "True"`,
+		expected: true,
+	}, {
+		name:     "missing condition",
+		src:      `condition("PIDPressure") == ""`,
+		expected: true,
+	}, {
+		name:            "condition age below the limit",
+		src:             `condition("Ready") == "Unknown" && conditionAge("Ready") >= 5m`,
+		expected:        false,
+		expectedRecheck: 2 * time.Minute,
+	}, {
+		name:            "condition age on the right",
+		src:             `5m < conditionAge("Ready")`,
+		expected:        false,
+		expectedRecheck: 2*time.Minute + time.Nanosecond,
+	}, {
+		name:     "condition age above the limit",
+		src:      `conditionAge("DiskPressure") > 30m`,
+		expected: true,
+	}, {
+		name:     "unless the node has an annotation",
+		src:      `condition("Ready") == "Unknown" && !hasAnnotation("example.com/maintenance")`,
+		expected: false,
+	}, {
+		name:     "label value and machine phase",
+		src:      `label("example.com/pool") == "gpu" && machinePhase() != "Failed"`,
+		expected: true,
+	}}
+
+	for _, tc := range tests {
+		e, err := Parse(tc.src)
+		if err != nil {
+			t.Fatalf("%s: failed to parse: %v", tc.name, err)
+		}
+		result, recheck := e.Evaluate(env)
+		if result != tc.expected {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.expected, result)
+		}
+		if recheck != tc.expectedRecheck {
+			t.Errorf("%s: expected recheck in %s, got %s", tc.name, tc.expectedRecheck, recheck)
+		}
+	}
+}
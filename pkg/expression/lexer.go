@@ -0,0 +1,172 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenDuration
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenNot
+	tokenAnd
+	tokenOr
+	tokenEqual
+	tokenNotEqual
+	tokenLess
+	tokenLessEqual
+	tokenGreater
+	tokenGreaterEqual
+)
+
+var tokenNames = map[tokenKind]string{
+	tokenEOF:          "end of expression",
+	tokenIdent:        "identifier",
+	tokenString:       "string",
+	tokenDuration:     "duration",
+	tokenLParen:       `"("`,
+	tokenRParen:       `")"`,
+	tokenComma:        `","`,
+	tokenNot:          `"!"`,
+	tokenAnd:          `"&&"`,
+	tokenOr:           `"||"`,
+	tokenEqual:        `"=="`,
+	tokenNotEqual:     `"!="`,
+	tokenLess:         `"<"`,
+	tokenLessEqual:    `"<="`,
+	tokenGreater:      `">"`,
+	tokenGreaterEqual: `">="`,
+}
+
+func (k tokenKind) String() string {
+	return tokenNames[k]
+}
+
+// operators contains the operator tokens, the longer ones first
+var operators = []struct {
+	text string
+	kind tokenKind
+}{
+	{"&&", tokenAnd},
+	{"||", tokenOr},
+	{"==", tokenEqual},
+	{"!=", tokenNotEqual},
+	{"<=", tokenLessEqual},
+	{">=", tokenGreaterEqual},
+	{"<", tokenLess},
+	{">", tokenGreater},
+	{"!", tokenNot},
+	{"(", tokenLParen},
+	{")", tokenRParen},
+	{",", tokenComma},
+}
+
+type token struct {
+	kind tokenKind
+	// pos is the position of the token in the expression, starting at 1
+	pos  int
+	text string
+	// str is the value of a string token
+	str string
+	// duration is the value of a duration token
+	duration time.Duration
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return t.kind.String()
+	case tokenIdent, tokenString, tokenDuration:
+		return fmt.Sprintf("%s %s", t.kind, t.text)
+	}
+	return t.kind.String()
+}
+
+// tokenize splits the expression into tokens, the last one is always tokenEOF
+func tokenize(src string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, pos: start + 1, text: string(runes[start:i])})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			d, err := time.ParseDuration(text)
+			if err != nil {
+				return nil, &SyntaxError{Pos: start + 1, Msg: fmt.Sprintf("invalid duration %s, expected a number with a unit, e.g. 5m or 1h30m", text)}
+			}
+			tokens = append(tokens, token{kind: tokenDuration, pos: start + 1, text: text, duration: d})
+		case r == '"':
+			start := i
+			i++
+			var b strings.Builder
+			closed := false
+			for i < len(runes) && !closed {
+				switch runes[i] {
+				case '"':
+					closed = true
+				case '\\':
+					if i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+						i++
+						b.WriteRune(runes[i])
+					} else {
+						return nil, &SyntaxError{Pos: i + 1, Msg: `invalid escape sequence, only \" and \\ are supported`}
+					}
+				default:
+					b.WriteRune(runes[i])
+				}
+				i++
+			}
+			if !closed {
+				return nil, &SyntaxError{Pos: start + 1, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: tokenString, pos: start + 1, text: string(runes[start:i]), str: b.String()})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op.text) {
+					tokens = append(tokens, token{kind: op.kind, pos: i + 1, text: op.text})
+					i += len([]rune(op.text))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				msg := fmt.Sprintf("unexpected character %q", r)
+				switch r {
+				case '&':
+					msg += `, did you mean "&&"?`
+				case '|':
+					msg += `, did you mean "||"?`
+				case '=':
+					msg += `, did you mean "=="?`
+				case '\'':
+					msg += `, strings are quoted with '"'`
+				}
+				return nil, &SyntaxError{Pos: i + 1, Msg: msg}
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
@@ -0,0 +1,287 @@
+package expression
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type valueType int
+
+const (
+	typeBool valueType = iota
+	typeString
+	typeDuration
+)
+
+func (t valueType) String() string {
+	switch t {
+	case typeBool:
+		return "boolean"
+	case typeString:
+		return "string"
+	}
+	return "duration"
+}
+
+// function describes a function of the expressions
+type function struct {
+	// arg is true when the function takes a string literal argument
+	arg    bool
+	result valueType
+}
+
+var functions = map[string]function{
+	"condition":     {arg: true, result: typeString},
+	"conditionAge":  {arg: true, result: typeDuration},
+	"label":         {arg: true, result: typeString},
+	"hasLabel":      {arg: true, result: typeBool},
+	"annotation":    {arg: true, result: typeString},
+	"hasAnnotation": {arg: true, result: typeBool},
+	"machinePhase":  {result: typeString},
+}
+
+// functionNames returns the sorted names of the functions for the error messages
+func functionNames() string {
+	names := []string{}
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// node is a type checked node of the expression tree
+type node interface {
+	valueType() valueType
+}
+
+type literal struct {
+	value value
+}
+
+func (n *literal) valueType() valueType { return n.value.t }
+
+type call struct {
+	name string
+	arg  string
+}
+
+func (n *call) valueType() valueType { return functions[n.name].result }
+
+type not struct {
+	x node
+}
+
+func (n *not) valueType() valueType { return typeBool }
+
+type binary struct {
+	op   tokenKind
+	x, y node
+}
+
+func (n *binary) valueType() valueType { return typeBool }
+
+// parser is a recursive descent parser of the grammar:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | comparison
+//	comparison = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand ]
+//	operand    = "(" or ")" | string | duration | "true" | "false" | ident "(" [ string ] ")"
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, context string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s, expected %s %s", tok, kind, context)}
+	}
+	return tok, nil
+}
+
+func (p *parser) parseOr(depth int) (node, error) {
+	if depth > maxDepth {
+		return nil, &SyntaxError{Pos: p.peek().pos, Msg: fmt.Sprintf("expression is nested more than %d times", maxDepth)}
+	}
+	x, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		op := p.next()
+		y, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkBool(op, x, y); err != nil {
+			return nil, err
+		}
+		x = &binary{op: tokenOr, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseAnd(depth int) (node, error) {
+	x, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		op := p.next()
+		y, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkBool(op, x, y); err != nil {
+			return nil, err
+		}
+		x = &binary{op: tokenAnd, x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *parser) parseUnary(depth int) (node, error) {
+	if p.peek().kind != tokenNot {
+		return p.parseComparison(depth)
+	}
+	op := p.next()
+	if depth+1 > maxDepth {
+		return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("expression is nested more than %d times", maxDepth)}
+	}
+	x, err := p.parseUnary(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	if x.valueType() != typeBool {
+		return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("operator \"!\" requires a boolean operand, got a %s", x.valueType())}
+	}
+	return &not{x: x}, nil
+}
+
+func (p *parser) parseComparison(depth int) (node, error) {
+	x, err := p.parseOperand(depth)
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	if !isComparison(op.kind) {
+		return x, nil
+	}
+	p.next()
+	y, err := p.parseOperand(depth)
+	if err != nil {
+		return nil, err
+	}
+	if x.valueType() != y.valueType() {
+		return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("operator %s compares a %s with a %s", op.kind, x.valueType(), y.valueType())}
+	}
+	ordered := op.kind != tokenEqual && op.kind != tokenNotEqual
+	if ordered != (x.valueType() == typeDuration) {
+		if ordered {
+			return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("operator %s requires durations, the %ss are compared with \"==\" and \"!=\"", op.kind, x.valueType())}
+		}
+		return nil, &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("operator %s can not compare durations, use \"<\", \"<=\", \">\" or \">=\"", op.kind)}
+	}
+	if isComparison(p.peek().kind) {
+		return nil, &SyntaxError{Pos: p.peek().pos, Msg: "comparisons can not be chained, combine them with \"&&\" or \"||\""}
+	}
+	return &binary{op: op.kind, x: x, y: y}, nil
+}
+
+func (p *parser) parseOperand(depth int) (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		x, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, fmt.Sprintf("to close the parenthesis at column %d", tok.pos)); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case tokenString:
+		return &literal{value: value{t: typeString, s: tok.str}}, nil
+	case tokenDuration:
+		return &literal{value: value{t: typeDuration, d: tok.duration}}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return &literal{value: value{t: typeBool, b: tok.text == "true"}}, nil
+		}
+		return p.parseCall(tok)
+	}
+	return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s, expected an operand: a string, a duration, true, false or a function call", tok)}
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("unknown function %s, expected one of %s", name.text, functionNames())}
+	}
+	if _, err := p.expect(tokenLParen, "after the function name "+name.text); err != nil {
+		return nil, err
+	}
+	n := &call{name: name.text}
+	if fn.arg {
+		arg := p.next()
+		if arg.kind != tokenString {
+			return nil, &SyntaxError{Pos: arg.pos, Msg: fmt.Sprintf("unexpected %s, function %s requires a string argument, e.g. %s(\"Ready\")", arg, name.text, name.text)}
+		}
+		if arg.str == "" {
+			return nil, &SyntaxError{Pos: arg.pos, Msg: fmt.Sprintf("function %s requires a non-empty argument", name.text)}
+		}
+		n.arg = arg.str
+	}
+	if _, err := p.expect(tokenRParen, fmt.Sprintf("to close the call of %s", name.text)); err != nil {
+		if !fn.arg {
+			return nil, &SyntaxError{Pos: err.(*SyntaxError).Pos, Msg: fmt.Sprintf("function %s takes no arguments", name.text)}
+		}
+		return nil, err
+	}
+	return n, nil
+}
+
+func isComparison(kind tokenKind) bool {
+	switch kind {
+	case tokenEqual, tokenNotEqual, tokenLess, tokenLessEqual, tokenGreater, tokenGreaterEqual:
+		return true
+	}
+	return false
+}
+
+// checkBool checks that the operands of the logical operator are booleans
+func checkBool(op token, x, y node) error {
+	for _, operand := range []node{x, y} {
+		if operand.valueType() != typeBool {
+			return &SyntaxError{Pos: op.pos, Msg: fmt.Sprintf("operator %s requires boolean operands, got a %s", op.kind, operand.valueType())}
+		}
+	}
+	return nil
+}
+
+// value is the value of an evaluated node
+type value struct {
+	t valueType
+	b bool
+	s string
+	d time.Duration
+	// aging is set for the condition ages, they grow with the time
+	aging bool
+}
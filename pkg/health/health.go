@@ -0,0 +1,55 @@
+package health
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+// Check returns an error when the checked component is not healthy
+type Check func() error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker is an http.Handler that reports success only when all of its checks pass.
+// Checks can be added while the handler is already serving requests.
+type Checker struct {
+	lock   sync.RWMutex
+	checks []namedCheck
+}
+
+// AddCheck adds a named check to the checker
+func (c *Checker) AddCheck(name string, check Check) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// ServeHTTP runs all the checks and responds with 200 when all of them pass and
+// with 500 listing the failed checks otherwise.
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.lock.RLock()
+	checks := c.checks
+	c.lock.RUnlock()
+
+	var failed bytes.Buffer
+	for _, nc := range checks {
+		if err := nc.check(); err != nil {
+			fmt.Fprintf(&failed, "[-]%s failed: %v\n", nc.name, err)
+		}
+	}
+
+	if failed.Len() > 0 {
+		glog.V(2).Infof("%s check failed:\n%s", r.URL.Path, failed.String())
+		http.Error(w, failed.String(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, "ok")
+}
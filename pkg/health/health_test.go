@@ -0,0 +1,36 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChecker(t *testing.T) {
+	checker := &Checker{}
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		checker.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return w
+	}
+
+	if w := get(); w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("Expected checker without checks to pass, got %d: %s", w.Code, w.Body.String())
+	}
+
+	checker.AddCheck("passing", func() error { return nil })
+	if w := get(); w.Code != http.StatusOK {
+		t.Errorf("Expected passing checks to pass, got %d: %s", w.Code, w.Body.String())
+	}
+
+	checker.AddCheck("leader-election", func() error { return fmt.Errorf("not the leader") })
+	w := get()
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected failing check to fail, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "[-]leader-election failed: not the leader") || strings.Contains(w.Body.String(), "passing") {
+		t.Errorf("Expected only the failed check to be reported, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// metricsNamespace contains the prefix of all the metrics published by the operator
+	metricsNamespace = "mhco"
+)
+
+var (
+	// SyncDuration observes the time it takes to sync the operator
+	SyncDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "sync_duration_seconds",
+			Help:      "Time it takes to sync the machine health check operator.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		},
+	)
+
+	// SyncErrors counts the operator sync errors by the reason reported in the ClusterOperator status
+	SyncErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "sync_errors_total",
+			Help:      "Number of the machine health check operator sync errors by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	// OperandRolloutState is set to 1 for the current rollout state of the operand deployment and to 0 for the other states
+	OperandRolloutState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "operand_rollout_state",
+			Help:      "Rollout state of the machine health check operand deployments, 1 for the current state.",
+		},
+		[]string{"deployment", "state"},
+	)
+
+	// LeaderElectionStatus is set to 1 when the operator holds the leader election lock and to 0 otherwise
+	LeaderElectionStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "leader_election_master_status",
+			Help:      "Whether the machine health check operator is the leader, 1 when it holds the lock.",
+		},
+		[]string{"name"},
+	)
+
+	// UnhealthyMachines is set to the number of the unhealthy machines selected by a machine health check by reason
+	UnhealthyMachines = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "machinehealthcheck_unhealthy_machines",
+			Help:      "Number of the unhealthy machines selected by the machine health check by reason.",
+		},
+		[]string{"namespace", "name", "reason"},
+	)
+
+	// MachineRemediations counts the machines remediated by a machine health check by the reason they were unhealthy
+	MachineRemediations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "machinehealthcheck_remediations_total",
+			Help:      "Number of the machines remediated by the machine health check by reason.",
+		},
+		[]string{"namespace", "name", "reason"},
+	)
+
+	// DryRunRemediations is set to the number of the machines a machine health check in the dry-run mode would have remediated
+	DryRunRemediations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "machinehealthcheck_dry_run_remediations",
+			Help:      "Number of the machines the machine health check in the dry-run mode would have remediated during the last check.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	registerOnce sync.Once
+)
+
+// Register registers the operator metrics with the default prometheus registry and
+// sets the workqueue and leader election metrics providers. It has to be called
+// before any workqueue or leader elector is created.
+func Register() {
+	registerOnce.Do(func() {
+		prometheus.MustRegister(
+			SyncDuration,
+			SyncErrors,
+			OperandRolloutState,
+			LeaderElectionStatus,
+			UnhealthyMachines,
+			MachineRemediations,
+			DryRunRemediations,
+			workqueueDepth,
+			workqueueAdds,
+			workqueueLatency,
+			workqueueWorkDuration,
+			workqueueUnfinishedWork,
+			workqueueLongestRunningProcessor,
+			workqueueRetries,
+		)
+		workqueue.SetProvider(workqueueMetricsProvider{})
+		leaderelection.SetProvider(leaderElectionMetricsProvider{})
+	})
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestWorkqueueMetrics(t *testing.T) {
+	Register()
+
+	const queueName = "machinehealthcheckoperator"
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), queueName)
+	defer queue.ShutDown()
+
+	queue.Add("key")
+	if depth := testutil.ToFloat64(workqueueDepth.WithLabelValues(queueName)); depth != 1 {
+		t.Errorf("Expected workqueue depth 1, got %v", depth)
+	}
+	if adds := testutil.ToFloat64(workqueueAdds.WithLabelValues(queueName)); adds != 1 {
+		t.Errorf("Expected 1 workqueue add, got %v", adds)
+	}
+
+	key, _ := queue.Get()
+	queue.AddRateLimited(key)
+	queue.Done(key)
+	if depth := testutil.ToFloat64(workqueueDepth.WithLabelValues(queueName)); depth != 0 {
+		t.Errorf("Expected workqueue depth 0, got %v", depth)
+	}
+	if retries := testutil.ToFloat64(workqueueRetries.WithLabelValues(queueName)); retries != 1 {
+		t.Errorf("Expected 1 workqueue retry, got %v", retries)
+	}
+}
+
+func TestLeaderElectionMetrics(t *testing.T) {
+	metric := leaderElectionMetricsProvider{}.NewLeaderMetric()
+
+	metric.On("machine-health-check-operator")
+	if status := testutil.ToFloat64(LeaderElectionStatus.WithLabelValues("machine-health-check-operator")); status != 1 {
+		t.Errorf("Expected leader election status 1, got %v", status)
+	}
+	metric.Off("machine-health-check-operator")
+	if status := testutil.ToFloat64(LeaderElectionStatus.WithLabelValues("machine-health-check-operator")); status != 0 {
+		t.Errorf("Expected leader election status 0, got %v", status)
+	}
+}
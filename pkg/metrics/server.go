@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// DefaultMetricsAddress contains the default address the metrics server listens on
+	DefaultMetricsAddress = ":8080"
+
+	serverShutdownTimeout = 5 * time.Second
+)
+
+// StartMetricsServer starts serving the metrics registered with the default
+// prometheus registry under /metrics until the stop channel is closed. The
+// additional handlers, like the health probes, are served next to the metrics.
+func StartMetricsServer(addr string, handlers map[string]http.Handler, stopCh <-chan struct{}) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	for pattern, handler := range handlers {
+		mux.Handle(pattern, handler)
+	}
+	server := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	go func() {
+		glog.Infof("Starting metrics server at %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			glog.Errorf("Metrics server failed: %v", err)
+		}
+	}()
+
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			glog.Errorf("Failed to shut down the metrics server: %v", err)
+		}
+	}()
+}
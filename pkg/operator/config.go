@@ -3,6 +3,9 @@ package operator
 import (
 	"encoding/json"
 	"fmt"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -16,11 +19,16 @@ type Provider string
 type Config struct {
 	TargetNamespace    string
 	TechPreviewEnabled bool
-	Controllers        Controllers
+	// RolloutDeadline contains the time the operand deployment has to make a rollout progress
+	RolloutDeadline time.Duration
+	// Spec contains the defaulted operator configuration spec
+	Spec        healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec
+	Controllers Controllers
 }
 
 // Controllers contains controllers images
 type Controllers struct {
+	// MachineHealthCheck contains the image running the machine health check controller
 	MachineHealthCheck string
 }
 
@@ -32,6 +40,9 @@ type Images struct {
 	ClusterAPIControllerLibvirt   string `json:"clusterAPIControllerLibvirt"`
 	ClusterAPIControllerBareMetal string `json:"clusterAPIControllerBareMetal"`
 	ClusterAPIControllerAzure     string `json:"clusterAPIControllerAzure"`
+	// MachineHealthCheckOperator contains the image of this operator, it runs also the machine
+	// health check controller
+	MachineHealthCheckOperator string `json:"machineHealthCheckOperator"`
 }
 
 func getImagesFromConfigMap(cmImages *corev1.ConfigMap) (*Images, error) {
@@ -39,9 +50,12 @@ func getImagesFromConfigMap(cmImages *corev1.ConfigMap) (*Images, error) {
 	if !ok {
 		return nil, fmt.Errorf("config map %s does not have data with key %s", cmImages.Name, imageJSON)
 	}
+	return getImagesFromJSON([]byte(data))
+}
 
+func getImagesFromJSON(data []byte) (*Images, error) {
 	var i Images
-	if err := json.Unmarshal([]byte(data), &i); err != nil {
+	if err := json.Unmarshal(data, &i); err != nil {
 		return nil, err
 	}
 	return &i, nil
@@ -52,9 +66,27 @@ func getMachineAPIOperatorFromConfigMap(cmImages *corev1.ConfigMap) (string, err
 	if err != nil {
 		return "", err
 	}
+	return getMachineAPIOperatorFromImages(images)
+}
 
+func getMachineAPIOperatorFromImages(images *Images) (string, error) {
 	if images.MachineAPIOperator == "" {
 		return "", fmt.Errorf("failed gettingMachineAPIOperator image. It is empty")
 	}
 	return images.MachineAPIOperator, nil
 }
+
+func getMachineHealthCheckOperatorFromConfigMap(cmImages *corev1.ConfigMap) (string, error) {
+	images, err := getImagesFromConfigMap(cmImages)
+	if err != nil {
+		return "", err
+	}
+	return getMachineHealthCheckOperatorFromImages(images)
+}
+
+func getMachineHealthCheckOperatorFromImages(images *Images) (string, error) {
+	if images.MachineHealthCheckOperator == "" {
+		return "", fmt.Errorf("failed getting MachineHealthCheckOperator image. It is empty")
+	}
+	return images.MachineHealthCheckOperator, nil
+}
@@ -13,7 +13,8 @@ const images = `{
 	"clusterAPIControllerLibvirt": "docker.io/openshift/origin-libvirt-machine-controllers:v4.0.0",
 	"machineAPIOperator": "docker.io/openshift/origin-machine-api-operator:v4.0.0",
 	"clusterAPIControllerBareMetal": "quay.io/openshift/origin-baremetal-machine-controllers:v4.0.0",
-	"clusterAPIControllerAzure": "quay.io/openshift/origin-azure-machine-controllers:v4.0.0"
+	"clusterAPIControllerAzure": "quay.io/openshift/origin-azure-machine-controllers:v4.0.0",
+	"machineHealthCheckOperator": "quay.io/openshift/origin-machine-health-check-operator:v4.0.0"
 }`
 
 func TestGetMachineAPIOperatorFromConfigMap(t *testing.T) {
@@ -33,3 +34,40 @@ func TestGetMachineAPIOperatorFromConfigMap(t *testing.T) {
 		t.Errorf("failed getMachineAPIOperatorFromConfigMap. Expected: %s, got: %s", expectedImage, machineAPIOperatorImage)
 	}
 }
+
+func TestGetMachineHealthCheckOperatorFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          map[string]string
+		expectedImage string
+		expectedError bool
+	}{{
+		name:          "image set",
+		data:          map[string]string{"images.json": images},
+		expectedImage: "quay.io/openshift/origin-machine-health-check-operator:v4.0.0",
+	}, {
+		name:          "image not set",
+		data:          map[string]string{"images.json": `{"machineAPIOperator": "docker.io/openshift/origin-machine-api-operator:v4.0.0"}`},
+		expectedError: true,
+	}, {
+		name:          "images not set",
+		expectedError: true,
+	}}
+
+	for _, tc := range tests {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      machineAPIOperatorImages,
+				Namespace: "openshift-machine-api",
+			},
+			Data: tc.data,
+		}
+		image, err := getMachineHealthCheckOperatorFromConfigMap(cm)
+		if (err != nil) != tc.expectedError {
+			t.Errorf("%s: expected error %t, got %v", tc.name, tc.expectedError, err)
+		}
+		if image != tc.expectedImage {
+			t.Errorf("%s: expected image %q, got %q", tc.name, tc.expectedImage, image)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	osev1 "github.com/openshift/api/config/v1"
+	osclientset "github.com/openshift/client-go/config/clientset/versioned"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	mhcclientset "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// OperandDiff contains the differences between a live operand and the operand the operator would apply
+type OperandDiff struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Missing is set when the operand does not exist in the cluster
+	Missing bool
+	Fields  []FieldDiff
+}
+
+// ConfigFromCluster builds the operator configuration from the images config map, the feature
+// gate and the operator config read directly from the cluster, the same way the operator
+// builds it from its informers.
+func ConfigFromCluster(
+	kubeClient kubernetes.Interface,
+	osClient osclientset.Interface,
+	mhcClient mhcclientset.Interface,
+	targetNamespace string,
+	configName string,
+	rolloutDeadline time.Duration,
+) (*Config, error) {
+	cmImages, err := kubeClient.CoreV1().ConfigMaps(targetNamespace).Get(machineAPIOperatorImages, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	imagesJSON, ok := cmImages.Data[imageJSON]
+	if !ok {
+		return nil, fmt.Errorf("config map %s does not have data with key %s", cmImages.Name, imageJSON)
+	}
+
+	featureSet := osev1.Default
+	featureGate, err := osClient.ConfigV1().FeatureGates().Get(MachineAPIFeatureGateName, metav1.GetOptions{})
+	if err == nil {
+		featureSet = featureGate.Spec.FeatureSet
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	spec := healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{}
+	operatorConfig, err := mhcClient.HealthcheckingV1alpha1().MachineHealthCheckOperatorConfigs().Get(configName, metav1.GetOptions{})
+	if err == nil {
+		spec = operatorConfig.Spec
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	return RenderConfig(targetNamespace, []byte(imagesJSON), featureSet, rolloutDeadline, spec)
+}
+
+// DiffOperands fetches the live operands and compares them to the operands the operator would
+// apply for the given configuration. Only the fields managed by the operator are compared.
+func DiffOperands(kubeClient kubernetes.Interface, config *Config) ([]OperandDiff, error) {
+	objects, err := RenderOperands(config)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []OperandDiff{}
+	for _, obj := range objects {
+		desired, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		diff := OperandDiff{
+			Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+			Namespace: desired.GetNamespace(),
+			Name:      desired.GetName(),
+		}
+		fields, err := diffOperand(kubeClient, obj)
+		switch {
+		case apierrors.IsNotFound(err):
+			diff.Missing = true
+		case err != nil:
+			return nil, err
+		default:
+			diff.Fields = fields
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// diffOperand fetches the live counterpart of the desired operand and returns the fields that differ
+func diffOperand(kubeClient kubernetes.Interface, obj runtime.Object) ([]FieldDiff, error) {
+	switch desired := obj.(type) {
+	case *corev1.ServiceAccount:
+		live, err := kubeClient.CoreV1().ServiceAccounts(desired.Namespace).Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DiffServiceAccount(live, desired), nil
+	case *rbacv1.ClusterRole:
+		live, err := kubeClient.RbacV1().ClusterRoles().Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DiffClusterRole(live, desired), nil
+	case *rbacv1.ClusterRoleBinding:
+		live, err := kubeClient.RbacV1().ClusterRoleBindings().Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DiffClusterRoleBinding(live, desired), nil
+	case *rbacv1.Role:
+		live, err := kubeClient.RbacV1().Roles(desired.Namespace).Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DiffRole(live, desired), nil
+	case *rbacv1.RoleBinding:
+		live, err := kubeClient.RbacV1().RoleBindings(desired.Namespace).Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DiffRoleBinding(live, desired), nil
+	case *corev1.Service:
+		live, err := kubeClient.CoreV1().Services(desired.Namespace).Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DiffService(live, desired), nil
+	case *admissionregistrationv1beta1.ValidatingWebhookConfiguration:
+		live, err := kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DiffValidatingWebhookConfiguration(live, desired), nil
+	case *appsv1.Deployment:
+		live, err := kubeClient.AppsV1().Deployments(desired.Namespace).Get(desired.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return DiffDeployment(live, desired), nil
+	default:
+		return nil, fmt.Errorf("unexpected operand type %T", obj)
+	}
+}
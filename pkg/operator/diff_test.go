@@ -0,0 +1,169 @@
+package operator
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/openshift/api/config/v1"
+	fakeos "github.com/openshift/client-go/config/clientset/versioned/fake"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	fakemhc "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned/fake"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func TestConfigFromCluster(t *testing.T) {
+	operatorConfig := newMachineHealthCheckOperatorConfig(healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{
+		Replicas: pointer.Int32Ptr(3),
+	})
+	config, err := ConfigFromCluster(
+		fakekube.NewSimpleClientset(newImagesConfigMap()),
+		fakeos.NewSimpleClientset(newFeatureGate(v1.TechPreviewNoUpgrade)),
+		fakemhc.NewSimpleClientset(operatorConfig),
+		targetNamespace,
+		DefaultOperatorConfigName,
+		DefaultRolloutDeadline,
+	)
+	if err != nil {
+		t.Fatalf("Failed to build config: %v", err)
+	}
+
+	expected, err := RenderConfig(targetNamespace, []byte(images), v1.TechPreviewNoUpgrade, DefaultRolloutDeadline, operatorConfig.Spec)
+	if err != nil {
+		t.Fatalf("Failed to render config: %v", err)
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, config)
+	}
+}
+
+func newAppliedOperands(t *testing.T, mutate func(d *appsv1.Deployment)) []runtime.Object {
+	config := newOperatorConfig(false)
+	return []runtime.Object{
+		newServiceAccount(config),
+		newClusterRole(),
+		newClusterRoleBinding(config),
+		newRemediationClusterRole(),
+		newRemediationClusterRoleBinding(config),
+		newRole(config),
+		newRoleBinding(config),
+		newAppliedService(config),
+		newAppliedValidatingWebhookConfiguration(config),
+		newAppliedDeployment(t, mutate),
+	}
+}
+
+func TestDiffOperands(t *testing.T) {
+	tests := []struct {
+		name            string
+		existing        []runtime.Object
+		expectedMissing []string
+		expectedPaths   map[string][]string
+	}{{
+		name:            "operands do not exist",
+		expectedMissing: []string{"ServiceAccount", "ClusterRole", "ClusterRoleBinding", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding", "Service", "ValidatingWebhookConfiguration", "Deployment"},
+	}, {
+		name:     "operands with defaulted fields only",
+		existing: newAppliedOperands(t, nil),
+	}, {
+		name: "hand edited deployment",
+		existing: newAppliedOperands(t, func(d *appsv1.Deployment) {
+			delete(d.Labels, ManagedByLabel)
+			d.Spec.Replicas = pointer.Int32Ptr(0)
+			d.Spec.Template.Spec.Containers[0].Image = "quay.io/example/machine-api-operator:debug"
+		}),
+		expectedPaths: map[string][]string{
+			"Deployment/" + deploymentName: {
+				"metadata.labels[" + ManagedByLabel + "]",
+				"spec.replicas",
+				"spec.template.spec.containers[0].image",
+			},
+		},
+	}, {
+		name: "hand edited roles",
+		existing: func() []runtime.Object {
+			objects := newAppliedOperands(t, nil)
+			objects[1].(*rbacv1.ClusterRole).Rules = nil
+			objects[3].(*rbacv1.ClusterRole).AggregationRule = nil
+			objects[6].(*rbacv1.RoleBinding).Subjects = append(objects[6].(*rbacv1.RoleBinding).Subjects, rbacv1.Subject{
+				Kind: rbacv1.UserKind,
+				Name: "admin",
+			})
+			return objects
+		}(),
+		expectedPaths: map[string][]string{
+			"ClusterRole/" + deploymentName:                    {"rules"},
+			"ClusterRole/" + machineHealthCheckRemediationRole: {"aggregationRule"},
+			"RoleBinding/" + deploymentName:                    {"subjects"},
+		},
+	}, {
+		name: "hand edited webhook",
+		existing: func() []runtime.Object {
+			objects := newAppliedOperands(t, nil)
+			objects[7].(*corev1.Service).Spec.Ports[0].TargetPort = intstr.FromInt(8443)
+			objects[8].(*admissionregistrationv1beta1.ValidatingWebhookConfiguration).Webhooks[0].Rules = nil
+			return objects
+		}(),
+		expectedPaths: map[string][]string{
+			"Service/" + deploymentName:                        {"spec.ports"},
+			"ValidatingWebhookConfiguration/" + deploymentName: {"webhooks"},
+		},
+	}}
+
+	for _, tc := range tests {
+		diffs, err := DiffOperands(fakekube.NewSimpleClientset(tc.existing...), newOperatorConfig(false))
+		if err != nil {
+			t.Errorf("%s: failed to diff operands: %v", tc.name, err)
+			continue
+		}
+		if len(diffs) != 10 {
+			t.Errorf("%s: expected 10 operand diffs, got %d", tc.name, len(diffs))
+			continue
+		}
+
+		missing := []string{}
+		for _, d := range diffs {
+			if d.Name != deploymentName && d.Name != machineHealthCheckRemediationRole {
+				t.Errorf("%s: unexpected operand %s %s", tc.name, d.Kind, d.Name)
+			}
+			if d.Missing {
+				missing = append(missing, d.Kind)
+				continue
+			}
+			paths := []string{}
+			for _, f := range d.Fields {
+				paths = append(paths, f.Path)
+			}
+			expectedPaths := tc.expectedPaths[d.Kind+"/"+d.Name]
+			if expectedPaths == nil {
+				expectedPaths = []string{}
+			}
+			if !reflect.DeepEqual(paths, expectedPaths) {
+				t.Errorf("%s: expected %s differing fields %v, got %v", tc.name, d.Kind, expectedPaths, paths)
+			}
+		}
+		if len(missing) != len(tc.expectedMissing) || (len(missing) > 0 && !reflect.DeepEqual(missing, tc.expectedMissing)) {
+			t.Errorf("%s: expected missing operands %v, got %v", tc.name, tc.expectedMissing, missing)
+		}
+	}
+}
+
+func TestDiffOperandsNotManaged(t *testing.T) {
+	config := newOperatorConfig(false)
+	config.Spec.ManagementState = healthcheckingv1alpha1.Unmanaged
+
+	diffs, err := DiffOperands(fakekube.NewSimpleClientset(newAppliedOperands(t, nil)...), config)
+	if err != nil {
+		t.Fatalf("Failed to diff operands: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no operand diffs, got %v", diffs)
+	}
+}
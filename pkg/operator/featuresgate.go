@@ -38,6 +38,20 @@ var MachineAPIOperatorFeatureSets = map[osev1.FeatureSet]*osev1.FeatureGateEnabl
 	},
 }
 
+// isTechPreviewEnabledForFeatureSet returns true when the feature set enables the MachineHealthCheck feature
+func isTechPreviewEnabledForFeatureSet(featureSet osev1.FeatureSet) (bool, error) {
+	features, err := generateFeatureMap(featureSet)
+	if err != nil {
+		return false, err
+	}
+
+	if enabled, ok := features[FeatureGateMachineHealthCheck]; ok && enabled {
+		return true, nil
+	}
+
+	return false, nil
+}
+
 func generateFeatureMap(featureSet osev1.FeatureSet) (map[string]bool, error) {
 	rv := map[string]bool{}
 	set, ok := MachineAPIOperatorFeatureSets[featureSet]
@@ -0,0 +1,49 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultWorkerStuckThreshold contains the default time a single sync can take
+	// before the worker processing it is reported as stuck
+	DefaultWorkerStuckThreshold = 5 * time.Minute
+)
+
+// InformersSynced returns an error when any of the operator informer caches is not synced yet.
+func (optr *Operator) InformersSynced() error {
+	for name, synced := range map[string]func() bool{
+		"deployments":                       optr.deployListerSynced,
+		"featuregates":                      optr.featureGateCacheSynced,
+		"configmaps":                        optr.configMapCacheSynced,
+		"machinehealthcheckoperatorconfigs": optr.operatorConfigCacheSynced,
+	} {
+		if !synced() {
+			return fmt.Errorf("%s informer cache is not synced", name)
+		}
+	}
+	return nil
+}
+
+// WorkerStuck returns an error when the sync currently processed by a worker
+// has been running for longer than the given threshold.
+func (optr *Operator) WorkerStuck(threshold time.Duration) error {
+	optr.workerLock.Lock()
+	syncStarted := optr.syncStarted
+	optr.workerLock.Unlock()
+
+	if syncStarted.IsZero() {
+		return nil
+	}
+	if running := time.Since(syncStarted); running > threshold {
+		return fmt.Errorf("sync has been running for %v, longer than %v", running.Round(time.Second), threshold)
+	}
+	return nil
+}
+
+func (optr *Operator) setSyncStarted(t time.Time) {
+	optr.workerLock.Lock()
+	defer optr.workerLock.Unlock()
+	optr.syncStarted = t
+}
@@ -0,0 +1,46 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestWorkerStuck(t *testing.T) {
+	optr := &Operator{}
+	if err := optr.WorkerStuck(time.Minute); err != nil {
+		t.Errorf("Expected idle worker not to be stuck, got %v", err)
+	}
+
+	optr.setSyncStarted(time.Now().Add(-30 * time.Second))
+	if err := optr.WorkerStuck(time.Minute); err != nil {
+		t.Errorf("Expected running sync within the threshold not to be stuck, got %v", err)
+	}
+
+	optr.setSyncStarted(time.Now().Add(-2 * time.Minute))
+	if err := optr.WorkerStuck(time.Minute); err == nil {
+		t.Error("Expected sync running past the threshold to be reported as stuck")
+	}
+}
+
+func TestInformersSynced(t *testing.T) {
+	stopCh := make(chan struct{})
+	optr := newFakeOperator([]runtime.Object{newImagesConfigMap()}, nil, nil, stopCh)
+	close(stopCh)
+
+	optr.configMapCacheSynced = func() bool { return false }
+	if err := optr.InformersSynced(); err == nil {
+		t.Error("Expected not synced configmaps informer to be reported")
+	}
+
+	stopCh = make(chan struct{})
+	defer close(stopCh)
+	optr = newFakeOperator([]runtime.Object{newImagesConfigMap()}, nil, nil, stopCh)
+	if err := wait.PollImmediate(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return optr.InformersSynced() == nil, nil
+	}); err != nil {
+		t.Errorf("Expected informers to be synced: %v", optr.InformersSynced())
+	}
+}
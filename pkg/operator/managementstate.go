@@ -0,0 +1,191 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// managedResourcesSelector selects all the resources created by the operator
+var managedResourcesSelector = labels.SelectorFromSet(labels.Set{ManagedByLabel: ManagedByLabelOperatorValue})
+
+// syncUnmanaged reports the state of the machine health check controller
+// deployment without reconciling it.
+func (optr *Operator) syncUnmanaged() error {
+	glog.V(3).Info("Management state is Unmanaged, skipping the operands reconciliation")
+
+	available := false
+	d, err := optr.deployLister.Deployments(optr.namespace).Get(machineHealthCheckControllerName)
+	switch {
+	case apierrors.IsNotFound(err):
+		err = optr.statusUnmanaged(available, fmt.Sprintf("deployment %q does not exist", machineHealthCheckControllerName))
+	case err != nil:
+		return err
+	default:
+		state, message := deploymentRolloutState(d)
+		reportRolloutState(d.Name, state)
+		available = state == RolloutStateComplete
+		err = optr.statusUnmanaged(available, message)
+	}
+	if err != nil {
+		glog.Errorf("Error syncing ClusterOperator status: %v", err)
+		return fmt.Errorf("error syncing ClusterOperator status: %v", err)
+	}
+	return nil
+}
+
+// syncRemoved deletes all the resources created by the operator and reports
+// the operator as available once none of them is left.
+func (optr *Operator) syncRemoved() error {
+	remaining, err := optr.removeManagedResources()
+	if err != nil {
+		if errStatus := optr.statusDegraded(ReasonSyncFailed, err.Error()); errStatus != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", errStatus)
+		}
+		glog.Errorf("Error removing managed resources: %v", err)
+		return err
+	}
+
+	// the removal progress is tracked by the informer delete events
+	if len(remaining) > 0 {
+		message := fmt.Sprintf("waiting for managed resources to be removed: %v", remaining)
+		glog.V(3).Infof("Removing machine health check components: %s", message)
+		if err := optr.statusRemoving(message); err != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", err)
+			return fmt.Errorf("error syncing ClusterOperator status: %v", err)
+		}
+		return nil
+	}
+	glog.V(3).Info("Removed all machine health check components")
+	// the removed deployment is not in any of the rollout states
+	reportRolloutState(machineHealthCheckControllerName, "")
+
+	if err := optr.statusRemoved(); err != nil {
+		glog.Errorf("Error syncing ClusterOperator status: %v", err)
+		return fmt.Errorf("error syncing ClusterOperator status: %v", err)
+	}
+	return nil
+}
+
+// removeManagedResources deletes the resources labelled as managed by the operator
+// and returns the ones that still exist in the cluster.
+func (optr *Operator) removeManagedResources() ([]string, error) {
+	remaining := []string{}
+	listOptions := metav1.ListOptions{LabelSelector: managedResourcesSelector.String()}
+	deleteOptions := &metav1.DeleteOptions{PropagationPolicy: propagationPolicy(metav1.DeletePropagationBackground)}
+
+	deployments, err := optr.kubeClient.AppsV1().Deployments(optr.namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		remaining = append(remaining, fmt.Sprintf("deployments/%s", d.Name))
+		if d.DeletionTimestamp != nil {
+			continue
+		}
+		glog.V(2).Infof("Deleting deployment %s/%s", d.Namespace, d.Name)
+		if err := optr.kubeClient.AppsV1().Deployments(d.Namespace).Delete(d.Name, deleteOptions); err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	// the controller keeps its permissions until it is stopped, the deployment delete
+	// event triggers the sync removing the RBAC resources
+	if len(remaining) > 0 {
+		return remaining, nil
+	}
+
+	// the webhook and the RBAC resources do not have any dependents and are not tracked by the informers,
+	// so they are reported as remaining only while their deletion is blocked by a finalizer
+	remove := func(resource string, meta metav1.ObjectMeta, deleteFunc func(string, *metav1.DeleteOptions) error) error {
+		if meta.DeletionTimestamp != nil {
+			remaining = append(remaining, fmt.Sprintf("%s/%s", resource, meta.Name))
+			return nil
+		}
+		glog.V(2).Infof("Deleting %s %s", resource, meta.Name)
+		if err := deleteFunc(meta.Name, deleteOptions); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	webhookConfigurations, err := optr.kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, wc := range webhookConfigurations.Items {
+		if err := remove("validatingwebhookconfigurations", wc.ObjectMeta, optr.kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Delete); err != nil {
+			return nil, err
+		}
+	}
+
+	services, err := optr.kubeClient.CoreV1().Services(optr.namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range services.Items {
+		if err := remove("services", svc.ObjectMeta, optr.kubeClient.CoreV1().Services(optr.namespace).Delete); err != nil {
+			return nil, err
+		}
+	}
+
+	roleBindings, err := optr.kubeClient.RbacV1().RoleBindings(optr.namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, rb := range roleBindings.Items {
+		if err := remove("rolebindings", rb.ObjectMeta, optr.kubeClient.RbacV1().RoleBindings(optr.namespace).Delete); err != nil {
+			return nil, err
+		}
+	}
+
+	roles, err := optr.kubeClient.RbacV1().Roles(optr.namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles.Items {
+		if err := remove("roles", r.ObjectMeta, optr.kubeClient.RbacV1().Roles(optr.namespace).Delete); err != nil {
+			return nil, err
+		}
+	}
+
+	clusterRoleBindings, err := optr.kubeClient.RbacV1().ClusterRoleBindings().List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, crb := range clusterRoleBindings.Items {
+		if err := remove("clusterrolebindings", crb.ObjectMeta, optr.kubeClient.RbacV1().ClusterRoleBindings().Delete); err != nil {
+			return nil, err
+		}
+	}
+
+	clusterRoles, err := optr.kubeClient.RbacV1().ClusterRoles().List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, cr := range clusterRoles.Items {
+		if err := remove("clusterroles", cr.ObjectMeta, optr.kubeClient.RbacV1().ClusterRoles().Delete); err != nil {
+			return nil, err
+		}
+	}
+
+	serviceAccounts, err := optr.kubeClient.CoreV1().ServiceAccounts(optr.namespace).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, sa := range serviceAccounts.Items {
+		if err := remove("serviceaccounts", sa.ObjectMeta, optr.kubeClient.CoreV1().ServiceAccounts(optr.namespace).Delete); err != nil {
+			return nil, err
+		}
+	}
+
+	return remaining, nil
+}
+
+func propagationPolicy(policy metav1.DeletionPropagation) *metav1.DeletionPropagation {
+	return &policy
+}
@@ -0,0 +1,143 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestManagementStateUnmanaged(t *testing.T) {
+	const handTunedImage = "quay.io/openshift/origin-machine-api-operator:hotfix"
+	existing := newAppliedDeployment(t, func(d *appsv1.Deployment) {
+		d.Spec.Template.Spec.Containers[0].Image = handTunedImage
+	})
+	operatorConfig := newMachineHealthCheckOperatorConfig(healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{
+		ManagementState: healthcheckingv1alpha1.Unmanaged,
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	optr := newFakeOperator([]runtime.Object{newImagesConfigMap(), existing}, []runtime.Object{newFeatureGate("")}, []runtime.Object{operatorConfig}, stopCh)
+	go optr.Run(2, stopCh)
+
+	if err := waitForOperatorConfigCondition(optr, healthcheckingv1alpha1.OperatorConfigProgressing, corev1.ConditionFalse); err != nil {
+		t.Fatalf("Failed to wait for the operator config status: %v", err)
+	}
+
+	co, err := optr.osClient.ConfigV1().ClusterOperators().Get(clusterOperatorName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get ClusterOperator: %v", err)
+	}
+	if c := findClusterOperatorStatusCondition(co.Status.Conditions, osconfigv1.OperatorProgressing); c == nil || c.Reason != string(ReasonUnmanaged) {
+		t.Errorf("Expected Progressing condition with reason %q, got %v", ReasonUnmanaged, c)
+	}
+	// the fake deployment is never rolled out
+	if c := findClusterOperatorStatusCondition(co.Status.Conditions, osconfigv1.OperatorAvailable); c == nil || c.Status != osconfigv1.ConditionFalse {
+		t.Errorf("Expected Available condition %q, got %v", osconfigv1.ConditionFalse, c)
+	}
+
+	d, err := optr.kubeClient.AppsV1().Deployments(targetNamespace).Get(deploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if image := d.Spec.Template.Spec.Containers[0].Image; image != handTunedImage {
+		t.Errorf("Expected the unmanaged deployment image to stay %q, got %q", handTunedImage, image)
+	}
+}
+
+func TestManagementStateRemoved(t *testing.T) {
+	foreign := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foreign",
+			Namespace: targetNamespace,
+		},
+	}
+	foreignServiceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine-api-controllers",
+			Namespace: targetNamespace,
+		},
+	}
+	operatorConfig := newMachineHealthCheckOperatorConfig(healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{
+		ManagementState: healthcheckingv1alpha1.Removed,
+	})
+
+	config := newOperatorConfig(false)
+	kubeObjects := []runtime.Object{
+		newImagesConfigMap(),
+		newAppliedDeployment(t, nil),
+		foreign,
+		newServiceAccount(config),
+		newClusterRole(),
+		newClusterRoleBinding(config),
+		newRemediationClusterRole(),
+		newRemediationClusterRoleBinding(config),
+		newRole(config),
+		newRoleBinding(config),
+		newAppliedService(config),
+		newAppliedValidatingWebhookConfiguration(config),
+		foreignServiceAccount,
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	optr := newFakeOperator(kubeObjects, []runtime.Object{newFeatureGate("")}, []runtime.Object{operatorConfig}, stopCh)
+	go optr.Run(2, stopCh)
+
+	if err := wait.PollImmediate(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+		co, err := optr.osClient.ConfigV1().ClusterOperators().Get(clusterOperatorName, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		c := findClusterOperatorStatusCondition(co.Status.Conditions, osconfigv1.OperatorAvailable)
+		return c != nil && c.Status == osconfigv1.ConditionTrue && c.Reason == string(ReasonRemoved), nil
+	}); err != nil {
+		t.Fatalf("Failed to wait for the operator to report the operands as removed: %v", err)
+	}
+
+	if _, err := optr.kubeClient.AppsV1().Deployments(targetNamespace).Get(deploymentName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q deployment to be removed, got %v", deploymentName, err)
+	}
+	if _, err := optr.kubeClient.AppsV1().Deployments(targetNamespace).Get(foreign.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected %q deployment not managed by the operator to be kept, got %v", foreign.Name, err)
+	}
+	if _, err := optr.kubeClient.CoreV1().ServiceAccounts(targetNamespace).Get(machineHealthCheckControllerServiceAccount, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q service account to be removed, got %v", machineHealthCheckControllerServiceAccount, err)
+	}
+	if _, err := optr.kubeClient.RbacV1().ClusterRoles().Get(machineHealthCheckControllerName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q cluster role to be removed, got %v", machineHealthCheckControllerName, err)
+	}
+	if _, err := optr.kubeClient.RbacV1().ClusterRoleBindings().Get(machineHealthCheckControllerName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q cluster role binding to be removed, got %v", machineHealthCheckControllerName, err)
+	}
+	if _, err := optr.kubeClient.RbacV1().ClusterRoles().Get(machineHealthCheckRemediationRole, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q cluster role to be removed, got %v", machineHealthCheckRemediationRole, err)
+	}
+	if _, err := optr.kubeClient.RbacV1().ClusterRoleBindings().Get(machineHealthCheckRemediationRole, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q cluster role binding to be removed, got %v", machineHealthCheckRemediationRole, err)
+	}
+	if _, err := optr.kubeClient.RbacV1().Roles(targetNamespace).Get(machineHealthCheckControllerName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q role to be removed, got %v", machineHealthCheckControllerName, err)
+	}
+	if _, err := optr.kubeClient.RbacV1().RoleBindings(targetNamespace).Get(machineHealthCheckControllerName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q role binding to be removed, got %v", machineHealthCheckControllerName, err)
+	}
+	if _, err := optr.kubeClient.CoreV1().Services(targetNamespace).Get(machineHealthCheckControllerName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q service to be removed, got %v", machineHealthCheckControllerName, err)
+	}
+	if _, err := optr.kubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(machineHealthCheckControllerName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected %q validating webhook configuration to be removed, got %v", machineHealthCheckControllerName, err)
+	}
+	if _, err := optr.kubeClient.CoreV1().ServiceAccounts(targetNamespace).Get(foreignServiceAccount.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected %q service account not managed by the operator to be kept, got %v", foreignServiceAccount.Name, err)
+	}
+}
@@ -2,6 +2,8 @@ package operator
 
 import (
 	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -9,9 +11,15 @@ import (
 	osclientset "github.com/openshift/client-go/config/clientset/versioned"
 	configinformersv1 "github.com/openshift/client-go/config/informers/externalversions/config/v1"
 	configlistersv1 "github.com/openshift/client-go/config/listers/config/v1"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	mhcclientset "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned"
+	healthcheckinginformersv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions/healthchecking/v1alpha1"
+	healthcheckinglistersv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/client/listers/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/metrics"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/wait"
 	appsinformersv1 "k8s.io/client-go/informers/apps/v1"
 	coreinformersv1 "k8s.io/client-go/informers/core/v1"
@@ -36,15 +44,20 @@ const (
 	ManagedByLabel = "app.kubernetes.io/managed-by"
 	// ManagedByLabelOperatorValue contains machine-health-check-operator label value
 	ManagedByLabelOperatorValue = "machine-health-check-operator"
+	// DefaultOperatorConfigName contains the default name of the MachineHealthCheckOperatorConfig object
+	DefaultOperatorConfigName = "cluster"
 )
 
 // Operator defines machine api operator.
 type Operator struct {
 	namespace, name string
+	// config contains the name of the MachineHealthCheckOperatorConfig object
 	config          string
+	rolloutDeadline time.Duration
 
 	kubeClient    kubernetes.Interface
 	osClient      osclientset.Interface
+	mhcClient     mhcclientset.Interface
 	eventRecorder record.EventRecorder
 
 	syncHandler func(ic string) error
@@ -58,21 +71,35 @@ type Operator struct {
 	configMapLister      corelistersv1.ConfigMapLister
 	configMapCacheSynced cache.InformerSynced
 
+	operatorConfigLister      healthcheckinglistersv1alpha1.MachineHealthCheckOperatorConfigLister
+	operatorConfigCacheSynced cache.InformerSynced
+
 	// queue only ever has one item, but it has nice error handling backoff/retry semantics
 	queue workqueue.RateLimitingInterface
+
+	// workerLock protects syncStarted
+	workerLock sync.Mutex
+	// syncStarted contains the time the sync currently processed by a worker started,
+	// it is zero when no sync is in progress
+	syncStarted time.Time
+
+	operandVersions []osev1.OperandVersion
 }
 
 // New returns a new machine config operator.
 func New(
 	namespace, name string,
 	config string,
+	rolloutDeadline time.Duration,
 
 	configMapInformer coreinformersv1.ConfigMapInformer,
 	deployInformer appsinformersv1.DeploymentInformer,
 	featureGateInformer configinformersv1.FeatureGateInformer,
+	operatorConfigInformer healthcheckinginformersv1alpha1.MachineHealthCheckOperatorConfigInformer,
 
 	kubeClient kubernetes.Interface,
 	osClient osclientset.Interface,
+	mhcClient mhcclientset.Interface,
 
 	recorder record.EventRecorder,
 ) *Operator {
@@ -81,15 +108,24 @@ func New(
 		name:          name,
 		kubeClient:    kubeClient,
 		osClient:      osClient,
+		mhcClient:     mhcClient,
 		eventRecorder: recorder,
 		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "machinehealthcheckoperator"),
+		operandVersions: []osev1.OperandVersion{
+			{
+				Name:    operatorVersionName,
+				Version: os.Getenv(releaseVersionEnvVariableName),
+			},
+		},
 	}
 
 	deployInformer.Informer().AddEventHandler(optr.eventHandler())
 	featureGateInformer.Informer().AddEventHandler(optr.eventHandler())
 	configMapInformer.Informer().AddEventHandler(optr.eventHandler())
+	operatorConfigInformer.Informer().AddEventHandler(optr.eventHandler())
 
 	optr.config = config
+	optr.rolloutDeadline = rolloutDeadline
 	optr.syncHandler = optr.sync
 
 	optr.deployLister = deployInformer.Lister()
@@ -101,13 +137,16 @@ func New(
 	optr.configMapLister = configMapInformer.Lister()
 	optr.configMapCacheSynced = configMapInformer.Informer().HasSynced
 
+	optr.operatorConfigLister = operatorConfigInformer.Lister()
+	optr.operatorConfigCacheSynced = operatorConfigInformer.Informer().HasSynced
+
 	return optr
 }
 
-// Run runs the machine config operator.
+// Run runs the machine config operator. Once the stop channel is closed, it shuts
+// down the queue and returns after the workers finished processing the queued keys.
 func (optr *Operator) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer optr.queue.ShutDown()
 
 	glog.Info("Starting Machine Health Check Operator")
 	defer glog.Info("Shutting down Machine Health Check Operator")
@@ -115,16 +154,29 @@ func (optr *Operator) Run(workers int, stopCh <-chan struct{}) {
 	if !cache.WaitForCacheSync(stopCh,
 		optr.deployListerSynced,
 		optr.featureGateCacheSynced,
-		optr.configMapCacheSynced) {
+		optr.configMapCacheSynced,
+		optr.operatorConfigCacheSynced) {
 		glog.Error("Failed to sync caches")
+		optr.queue.ShutDown()
 		return
 	}
 	glog.Info("Synced up caches")
+
+	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
-		go wait.Until(optr.worker, time.Second, stopCh)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(optr.worker, time.Second, stopCh)
+		}()
 	}
 
 	<-stopCh
+
+	// the workers return once the shut down queue is drained
+	glog.Info("Draining the operator queue")
+	optr.queue.ShutDown()
+	wg.Wait()
 }
 
 func (optr *Operator) eventHandler() cache.ResourceEventHandler {
@@ -149,7 +201,9 @@ func (optr *Operator) processNextWorkItem() bool {
 	defer optr.queue.Done(key)
 
 	glog.V(4).Infof("Processing key %s", key)
+	optr.setSyncStarted(time.Now())
 	err := optr.syncHandler(key.(string))
+	optr.setSyncStarted(time.Time{})
 	optr.handleErr(err, key)
 
 	return true
@@ -176,28 +230,64 @@ func (optr *Operator) sync(key string) error {
 	startTime := time.Now()
 	glog.V(4).Infof("Started syncing operator %q (%v)", key, startTime)
 	defer func() {
+		metrics.SyncDuration.Observe(time.Since(startTime).Seconds())
 		glog.V(4).Infof("Finished syncing operator %q (%v)", key, time.Since(startTime))
 	}()
 
-	operatorConfig, err := optr.configFromInfrastructure()
+	operatorConfig, err := optr.getOperatorConfig()
+	if err != nil {
+		glog.Errorf("Failed getting operator config %q: %v", optr.config, err)
+		return err
+	}
+
+	if errs := healthcheckingv1alpha1.ValidateMachineHealthCheckOperatorConfigSpec(&operatorConfig.Spec, field.NewPath("spec")); len(errs) > 0 {
+		// the invalid config will be re-queued by the informer once it is updated
+		validationErr := errs.ToAggregate()
+		glog.Errorf("Invalid operator config %q: %v", optr.config, validationErr)
+		if errStatus := optr.statusDegraded(ReasonInvalidConfiguration, validationErr.Error()); errStatus != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", errStatus)
+		}
+		return optr.syncOperatorConfigStatus(operatorConfig, validationErr)
+	}
+
+	config, err := optr.configFromInfrastructure(operatorConfig)
 	if err != nil {
 		glog.Errorf("Failed getting operator config: %v", err)
+		if errStatus := optr.statusDegraded(ReasonInvalidConfiguration, err.Error()); errStatus != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", errStatus)
+		}
 		return err
 	}
-	return optr.syncAll(operatorConfig)
+
+	var syncErr error
+	switch config.Spec.ManagementState {
+	case healthcheckingv1alpha1.Unmanaged:
+		syncErr = optr.syncUnmanaged()
+	case healthcheckingv1alpha1.Removed:
+		syncErr = optr.syncRemoved()
+	default:
+		syncErr = optr.syncAll(config)
+	}
+	if err := optr.syncOperatorConfigStatus(operatorConfig, nil); err != nil {
+		glog.Errorf("Error syncing operator config %q status: %v", optr.config, err)
+		if syncErr == nil {
+			return err
+		}
+	}
+	return syncErr
 }
 
-func (optr *Operator) configFromInfrastructure() (*Config, error) {
+func (optr *Operator) configFromInfrastructure(operatorConfig *healthcheckingv1alpha1.MachineHealthCheckOperatorConfig) (*Config, error) {
 	cmImages, err := optr.configMapLister.ConfigMaps(optr.namespace).Get(machineAPIOperatorImages)
 	if err != nil {
 		return nil, err
 	}
 
-	machineAPIOperatorImage, err := getMachineAPIOperatorFromConfigMap(cmImages)
+	machineHealthCheckOperatorImage, err := getMachineHealthCheckOperatorFromConfigMap(cmImages)
 	if err != nil {
 		return nil, err
 	}
-	glog.V(4).Infof("machine API operator images %s", machineAPIOperatorImage)
+	glog.V(4).Infof("machine health check operator image %s", machineHealthCheckOperatorImage)
 
 	techPreviewEnabled, err := optr.isTechPreviewEnabled()
 	if err != nil {
@@ -208,8 +298,10 @@ func (optr *Operator) configFromInfrastructure() (*Config, error) {
 	return &Config{
 		TargetNamespace:    optr.namespace,
 		TechPreviewEnabled: techPreviewEnabled,
+		RolloutDeadline:    optr.rolloutDeadline,
+		Spec:               operatorConfig.Spec,
 		Controllers: Controllers{
-			MachineHealthCheck: machineAPIOperatorImage,
+			MachineHealthCheck: machineHealthCheckOperatorImage,
 		},
 	}, nil
 }
@@ -229,14 +321,5 @@ func (optr *Operator) isTechPreviewEnabled() (bool, error) {
 		featureSet = featureGate.Spec.FeatureSet
 	}
 
-	features, err := generateFeatureMap(featureSet)
-	if err != nil {
-		return false, err
-	}
-
-	if enabled, ok := features[FeatureGateMachineHealthCheck]; ok && enabled {
-		return true, nil
-	}
-
-	return false, nil
+	return isTechPreviewEnabledForFeatureSet(featureSet)
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -17,11 +18,16 @@ import (
 	v1 "github.com/openshift/api/config/v1"
 	fakeos "github.com/openshift/client-go/config/clientset/versioned/fake"
 	configinformersv1 "github.com/openshift/client-go/config/informers/externalversions"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	fakemhc "github.com/openshift/machine-health-check-operator/pkg/client/clientset/versioned/fake"
+	mhcinformers "github.com/openshift/machine-health-check-operator/pkg/client/informers/externalversions"
 )
 
 const (
 	deploymentName  = "machine-health-check-controller"
 	targetNamespace = "test-namespace"
+	// controllerImage contains the machine health check operator image of the images config map
+	controllerImage = "quay.io/openshift/origin-machine-health-check-operator:v4.0.0"
 )
 
 func newFeatureGate(featureSet v1.FeatureSet) *v1.FeatureGate {
@@ -36,11 +42,15 @@ func newFeatureGate(featureSet v1.FeatureSet) *v1.FeatureGate {
 }
 
 func newOperatorConfig(techPreviewEnabled bool) *Config {
+	spec := healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{}
+	healthcheckingv1alpha1.SetDefaultsMachineHealthCheckOperatorConfigSpec(&spec)
 	return &Config{
-		targetNamespace,
-		techPreviewEnabled,
-		Controllers{
-			"docker.io/openshift/origin-machine-api-operator:v4.0.0",
+		TargetNamespace:    targetNamespace,
+		TechPreviewEnabled: techPreviewEnabled,
+		RolloutDeadline:    DefaultRolloutDeadline,
+		Spec:               spec,
+		Controllers: Controllers{
+			MachineHealthCheck: controllerImage,
 		},
 	}
 }
@@ -55,9 +65,10 @@ func newImagesConfigMap() *corev1.ConfigMap {
 	}
 }
 
-func newFakeOperator(kubeObjects []runtime.Object, osObjects []runtime.Object, stopCh <-chan struct{}) *Operator {
+func newFakeOperator(kubeObjects []runtime.Object, osObjects []runtime.Object, mhcObjects []runtime.Object, stopCh <-chan struct{}) *Operator {
 	kubeClient := fakekube.NewSimpleClientset(kubeObjects...)
 	osClient := fakeos.NewSimpleClientset(osObjects...)
+	mhcClient := fakemhc.NewSimpleClientset(mhcObjects...)
 
 	configMapInformerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 2*time.Minute, informers.WithNamespace(targetNamespace))
 	tweakListOptions := func(listOptions *metav1.ListOptions) {
@@ -65,40 +76,47 @@ func newFakeOperator(kubeObjects []runtime.Object, osObjects []runtime.Object, s
 	}
 	deploymentInformerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 2*time.Minute, informers.WithTweakListOptions(tweakListOptions), informers.WithNamespace(targetNamespace))
 	configInformerFactory := configinformersv1.NewSharedInformerFactoryWithOptions(osClient, 2*time.Minute, configinformersv1.WithNamespace(targetNamespace))
+	mhcInformerFactory := mhcinformers.NewSharedInformerFactory(mhcClient, 2*time.Minute)
 
 	configMapInformer := configMapInformerFactory.Core().V1().ConfigMaps()
 	featureGateInformer := configInformerFactory.Config().V1().FeatureGates()
 	deploymentInformer := deploymentInformerFactory.Apps().V1().Deployments()
+	operatorConfigInformer := mhcInformerFactory.Healthchecking().V1alpha1().MachineHealthCheckOperatorConfigs()
 
 	optr := &Operator{
-		kubeClient:             kubeClient,
-		osClient:               osClient,
-		configMapLister:        configMapInformer.Lister(),
-		featureGateLister:      featureGateInformer.Lister(),
-		deployLister:           deploymentInformer.Lister(),
-		namespace:              targetNamespace,
-		eventRecorder:          record.NewFakeRecorder(50),
-		queue:                  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "machineapioperator"),
-		configMapCacheSynced:   configMapInformer.Informer().HasSynced,
-		deployListerSynced:     deploymentInformer.Informer().HasSynced,
-		featureGateCacheSynced: featureGateInformer.Informer().HasSynced,
+		kubeClient:                kubeClient,
+		osClient:                  osClient,
+		mhcClient:                 mhcClient,
+		configMapLister:           configMapInformer.Lister(),
+		featureGateLister:         featureGateInformer.Lister(),
+		deployLister:              deploymentInformer.Lister(),
+		operatorConfigLister:      operatorConfigInformer.Lister(),
+		namespace:                 targetNamespace,
+		config:                    DefaultOperatorConfigName,
+		rolloutDeadline:           DefaultRolloutDeadline,
+		eventRecorder:             record.NewFakeRecorder(50),
+		queue:                     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "machineapioperator"),
+		configMapCacheSynced:      configMapInformer.Informer().HasSynced,
+		deployListerSynced:        deploymentInformer.Informer().HasSynced,
+		featureGateCacheSynced:    featureGateInformer.Informer().HasSynced,
+		operatorConfigCacheSynced: operatorConfigInformer.Informer().HasSynced,
 	}
 
 	configMapInformerFactory.Start(stopCh)
 	deploymentInformerFactory.Start(stopCh)
 	configInformerFactory.Start(stopCh)
+	mhcInformerFactory.Start(stopCh)
 
 	optr.syncHandler = optr.sync
 	configMapInformer.Informer().AddEventHandler(optr.eventHandler())
 	deploymentInformer.Informer().AddEventHandler(optr.eventHandler())
 	featureGateInformer.Informer().AddEventHandler(optr.eventHandler())
+	operatorConfigInformer.Informer().AddEventHandler(optr.eventHandler())
 
 	return optr
 }
 
 func TestOperatorSyncClusterAPIControllerHealthCheckController(t *testing.T) {
-	cmImages := newImagesConfigMap()
-
 	tests := []struct {
 		featureGate      *v1.FeatureGate
 		expectedReplicas *int32
@@ -115,7 +133,7 @@ func TestOperatorSyncClusterAPIControllerHealthCheckController(t *testing.T) {
 
 	for _, tc := range tests {
 		stopCh := make(<-chan struct{})
-		optr := newFakeOperator([]runtime.Object{cmImages}, []runtime.Object{tc.featureGate}, stopCh)
+		optr := newFakeOperator([]runtime.Object{newImagesConfigMap()}, []runtime.Object{tc.featureGate}, nil, stopCh)
 		go optr.Run(2, stopCh)
 
 		if err := wait.PollImmediate(1*time.Second, 5*time.Second, func() (bool, error) {
@@ -134,3 +152,64 @@ func TestOperatorSyncClusterAPIControllerHealthCheckController(t *testing.T) {
 		}
 	}
 }
+
+func TestRunReturnsOnceStopped(t *testing.T) {
+	stopCh := make(chan struct{})
+	optr := newFakeOperator([]runtime.Object{newImagesConfigMap()}, []runtime.Object{newFeatureGate(v1.Default)}, nil, stopCh)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		optr.Run(2, stopCh)
+	}()
+
+	if err := wait.PollImmediate(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+		_, err := optr.deployLister.Deployments(targetNamespace).Get(deploymentName)
+		return err == nil, nil
+	}); err != nil {
+		t.Fatalf("Failed to wait for %q deployment: %v", deploymentName, err)
+	}
+
+	close(stopCh)
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the operator to return once the stop channel is closed")
+	}
+	if !optr.queue.ShuttingDown() {
+		t.Error("Expected the operator queue to be shut down")
+	}
+}
+
+func TestOperatorSyncControllerRBAC(t *testing.T) {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	optr := newFakeOperator([]runtime.Object{newImagesConfigMap()}, []runtime.Object{newFeatureGate(v1.Default)}, nil, stopCh)
+	go optr.Run(2, stopCh)
+
+	var d *appsv1.Deployment
+	if err := wait.PollImmediate(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+		var err error
+		d, err = optr.deployLister.Deployments(targetNamespace).Get(deploymentName)
+		return err == nil, nil
+	}); err != nil {
+		t.Fatalf("Failed to wait for %q deployment: %v", deploymentName, err)
+	}
+
+	// the service account and the roles are applied before the deployment
+	if d.Spec.Template.Spec.ServiceAccountName != machineHealthCheckControllerServiceAccount {
+		t.Errorf("Expected deployment service account %q, got %q", machineHealthCheckControllerServiceAccount, d.Spec.Template.Spec.ServiceAccountName)
+	}
+	diffs, err := DiffOperands(optr.kubeClient, newOperatorConfig(false))
+	if err != nil {
+		t.Fatalf("Failed to diff operands: %v", err)
+	}
+	for _, diff := range diffs {
+		if diff.Kind == "Deployment" {
+			continue
+		}
+		if diff.Missing || len(diff.Fields) != 0 {
+			t.Errorf("Expected %s %s to be applied, got missing %t, diff %v", diff.Kind, diff.Name, diff.Missing, diff.Fields)
+		}
+	}
+}
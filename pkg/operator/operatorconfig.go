@@ -0,0 +1,125 @@
+package operator
+
+import (
+	"github.com/golang/glog"
+	osconfigv1 "github.com/openshift/api/config/v1"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getOperatorConfig returns the defaulted operator config. When the operator config
+// object does not exist, the default operator config is returned.
+func (optr *Operator) getOperatorConfig() (*healthcheckingv1alpha1.MachineHealthCheckOperatorConfig, error) {
+	operatorConfig, err := optr.operatorConfigLister.Get(optr.config)
+	if apierrors.IsNotFound(err) {
+		glog.V(2).Infof("Failed to find operator config %q, will use default configuration", optr.config)
+		operatorConfig = &healthcheckingv1alpha1.MachineHealthCheckOperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: optr.config,
+			},
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	operatorConfig = operatorConfig.DeepCopy()
+	healthcheckingv1alpha1.SetDefaultsMachineHealthCheckOperatorConfigSpec(&operatorConfig.Spec)
+	return operatorConfig, nil
+}
+
+// syncOperatorConfigStatus updates the operator config status with the observed generation,
+// the validation result and the conditions reported by the ClusterOperator.
+func (optr *Operator) syncOperatorConfigStatus(operatorConfig *healthcheckingv1alpha1.MachineHealthCheckOperatorConfig, validationErr error) error {
+	// the default operator config does not exist in the cluster
+	if operatorConfig.UID == "" {
+		return nil
+	}
+
+	status := operatorConfig.Status.DeepCopy()
+	status.ObservedGeneration = operatorConfig.Generation
+
+	if validationErr != nil {
+		setOperatorConfigCondition(&status.Conditions, newOperatorConfigCondition(
+			healthcheckingv1alpha1.OperatorConfigValid, corev1.ConditionFalse, string(ReasonInvalidConfiguration), validationErr.Error(),
+		))
+	} else {
+		setOperatorConfigCondition(&status.Conditions, newOperatorConfigCondition(
+			healthcheckingv1alpha1.OperatorConfigValid, corev1.ConditionTrue, string(ReasonAsExpected), "",
+		))
+	}
+
+	co, err := optr.osClient.ConfigV1().ClusterOperators().Get(clusterOperatorName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		for _, conditionType := range []osconfigv1.ClusterStatusConditionType{
+			osconfigv1.OperatorAvailable,
+			osconfigv1.OperatorProgressing,
+			osconfigv1.OperatorDegraded,
+		} {
+			c := findClusterOperatorStatusCondition(co.Status.Conditions, conditionType)
+			if c == nil {
+				continue
+			}
+			setOperatorConfigCondition(&status.Conditions, healthcheckingv1alpha1.MachineHealthCheckOperatorConfigCondition{
+				Type:               healthcheckingv1alpha1.OperatorConfigConditionType(c.Type),
+				Status:             corev1.ConditionStatus(c.Status),
+				LastTransitionTime: c.LastTransitionTime,
+				Reason:             c.Reason,
+				Message:            c.Message,
+			})
+		}
+	}
+
+	if equality.Semantic.DeepEqual(*status, operatorConfig.Status) {
+		return nil
+	}
+
+	existing, err := optr.operatorConfigLister.Get(operatorConfig.Name)
+	if err != nil {
+		return err
+	}
+	toWrite := existing.DeepCopy()
+	toWrite.Status = *status
+	glog.V(4).Infof("Updating operator config %q status", operatorConfig.Name)
+	_, err = optr.mhcClient.HealthcheckingV1alpha1().MachineHealthCheckOperatorConfigs().UpdateStatus(toWrite)
+	return err
+}
+
+// newOperatorConfigCondition returns a new operator config condition with the last
+// transition time set to now.
+func newOperatorConfigCondition(conditionType healthcheckingv1alpha1.OperatorConfigConditionType,
+	conditionStatus corev1.ConditionStatus, reason string,
+	message string) healthcheckingv1alpha1.MachineHealthCheckOperatorConfigCondition {
+	return healthcheckingv1alpha1.MachineHealthCheckOperatorConfigCondition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// setOperatorConfigCondition sets the corresponding condition in conditions to newCondition.
+// The last transition time is only updated when the condition status changes.
+func setOperatorConfigCondition(conditions *[]healthcheckingv1alpha1.MachineHealthCheckOperatorConfigCondition, newCondition healthcheckingv1alpha1.MachineHealthCheckOperatorConfigCondition) {
+	for i := range *conditions {
+		existingCondition := &(*conditions)[i]
+		if existingCondition.Type != newCondition.Type {
+			continue
+		}
+		if existingCondition.Status != newCondition.Status {
+			existingCondition.Status = newCondition.Status
+			existingCondition.LastTransitionTime = newCondition.LastTransitionTime
+		}
+		existingCondition.Reason = newCondition.Reason
+		existingCondition.Message = newCondition.Message
+		return
+	}
+	*conditions = append(*conditions, newCondition)
+}
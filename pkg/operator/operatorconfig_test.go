@@ -0,0 +1,105 @@
+package operator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+)
+
+func newMachineHealthCheckOperatorConfig(spec healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec) *healthcheckingv1alpha1.MachineHealthCheckOperatorConfig {
+	return &healthcheckingv1alpha1.MachineHealthCheckOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       DefaultOperatorConfigName,
+			UID:        "cluster-uid",
+			Generation: 2,
+		},
+		Spec: spec,
+	}
+}
+
+func findOperatorConfigCondition(conditions []healthcheckingv1alpha1.MachineHealthCheckOperatorConfigCondition, conditionType healthcheckingv1alpha1.OperatorConfigConditionType) *healthcheckingv1alpha1.MachineHealthCheckOperatorConfigCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func waitForOperatorConfigCondition(optr *Operator, conditionType healthcheckingv1alpha1.OperatorConfigConditionType, status corev1.ConditionStatus) error {
+	return wait.PollImmediate(100*time.Millisecond, 5*time.Second, func() (bool, error) {
+		operatorConfig, err := optr.mhcClient.HealthcheckingV1alpha1().MachineHealthCheckOperatorConfigs().Get(DefaultOperatorConfigName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if operatorConfig.Status.ObservedGeneration != operatorConfig.Generation {
+			return false, nil
+		}
+		c := findOperatorConfigCondition(operatorConfig.Status.Conditions, conditionType)
+		return c != nil && c.Status == status, nil
+	})
+}
+
+func TestOperatorConfigIsApplied(t *testing.T) {
+	operatorConfig := newMachineHealthCheckOperatorConfig(healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{
+		LogLevel:     pointer.Int32Ptr(5),
+		Replicas:     pointer.Int32Ptr(2),
+		NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+		ExtraArgs:    []string{"--leader-elect=true"},
+		DryRun:       true,
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	optr := newFakeOperator([]runtime.Object{newImagesConfigMap()}, []runtime.Object{newFeatureGate("")}, []runtime.Object{operatorConfig}, stopCh)
+	go optr.Run(2, stopCh)
+
+	if err := waitForOperatorConfigCondition(optr, healthcheckingv1alpha1.OperatorConfigValid, corev1.ConditionTrue); err != nil {
+		t.Fatalf("Failed to wait for the operator config to be valid: %v", err)
+	}
+
+	d, err := optr.kubeClient.AppsV1().Deployments(targetNamespace).Get(deploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get %q deployment: %v", deploymentName, err)
+	}
+	if *d.Spec.Replicas != 2 {
+		t.Errorf("Expected 2 replicas, got %d", *d.Spec.Replicas)
+	}
+	if _, ok := d.Spec.Template.Spec.NodeSelector["node-role.kubernetes.io/infra"]; !ok {
+		t.Errorf("Expected infra node selector, got %v", d.Spec.Template.Spec.NodeSelector)
+	}
+	if len(d.Spec.Template.Spec.Tolerations) == 0 {
+		t.Errorf("Expected default tolerations, got none")
+	}
+	args := fmt.Sprintf("%v", d.Spec.Template.Spec.Containers[0].Args)
+	if args != "[controller --logtostderr=true --v=5 --namespace="+targetNamespace+" --webhook-addr=:9443 --webhook-cert-dir="+machineHealthCheckWebhookCertDir+" --dry-run=true --leader-elect=true]" {
+		t.Errorf("Unexpected container args %s", args)
+	}
+}
+
+func TestOperatorConfigIsInvalid(t *testing.T) {
+	operatorConfig := newMachineHealthCheckOperatorConfig(healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{
+		LogLevel:  pointer.Int32Ptr(20),
+		ExtraArgs: []string{"--v=10"},
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	optr := newFakeOperator([]runtime.Object{newImagesConfigMap()}, []runtime.Object{newFeatureGate("")}, []runtime.Object{operatorConfig}, stopCh)
+	go optr.Run(2, stopCh)
+
+	if err := waitForOperatorConfigCondition(optr, healthcheckingv1alpha1.OperatorConfigValid, corev1.ConditionFalse); err != nil {
+		t.Fatalf("Failed to wait for the operator config to be invalid: %v", err)
+	}
+	if _, err := optr.kubeClient.AppsV1().Deployments(targetNamespace).Get(deploymentName, metav1.GetOptions{}); err == nil {
+		t.Errorf("Expected %q deployment not to be created for the invalid operator config", deploymentName)
+	}
+}
@@ -0,0 +1,164 @@
+package operator
+
+import (
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// machineHealthCheckControllerServiceAccount contains the name of the service account the
+// machine health check controller runs as, it is dedicated to the controller, so removing
+// the operands does not affect the other machine API components
+const machineHealthCheckControllerServiceAccount = machineHealthCheckControllerName
+
+// machineHealthCheckRemediationRole contains the name of the cluster role aggregating the cluster
+// roles that grant the machine health check controller access to the external remediation objects
+const machineHealthCheckRemediationRole = machineHealthCheckControllerName + "-remediation"
+
+func newObjectMeta(name, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: namespace,
+		Labels: map[string]string{
+			ManagedByLabel: ManagedByLabelOperatorValue,
+		},
+	}
+}
+
+func newServiceAccount(config *Config) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: newObjectMeta(machineHealthCheckControllerServiceAccount, config.TargetNamespace),
+	}
+}
+
+// newClusterRole returns the cluster role with the permissions the machine health check
+// controller needs to watch the nodes and to remediate the unhealthy machines
+func newClusterRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: newObjectMeta(machineHealthCheckControllerName, ""),
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{healthcheckingv1alpha1.GroupName},
+				Resources: []string{"machinehealthchecks", "machinedisruptionbudgets"},
+				Verbs:     []string{"get", "list", "watch"},
+			},
+			{
+				APIGroups: []string{healthcheckingv1alpha1.GroupName},
+				Resources: []string{"machinehealthchecks/status", "machinedisruptionbudgets/status"},
+				Verbs:     []string{"update", "patch"},
+			},
+			{
+				APIGroups: []string{"machine.openshift.io"},
+				Resources: []string{"machines"},
+				Verbs:     []string{"get", "list", "watch", "patch", "delete"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"nodes"},
+				Verbs:     []string{"get", "list", "watch", "patch"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"list", "watch", "delete"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods/eviction"},
+				Verbs:     []string{"create"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"events"},
+				Verbs:     []string{"create", "patch"},
+			},
+		},
+	}
+}
+
+func newClusterRoleBinding(config *Config) *rbacv1.ClusterRoleBinding {
+	return newControllerClusterRoleBinding(machineHealthCheckControllerName, config)
+}
+
+// newRemediationClusterRole returns the cluster role granting the machine health check controller
+// access to the remediation templates and to the remediation objects of the External strategy.
+// The kinds of the templates are not known by the operator, so the rules are aggregated by the
+// API server from the cluster roles labeled by the template owners with the aggregation label.
+func newRemediationClusterRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: newObjectMeta(machineHealthCheckRemediationRole, ""),
+		AggregationRule: &rbacv1.AggregationRule{
+			ClusterRoleSelectors: []metav1.LabelSelector{
+				{
+					MatchLabels: map[string]string{
+						healthcheckingv1alpha1.RemediationRoleAggregationLabel: "true",
+					},
+				},
+			},
+		},
+	}
+}
+
+func newRemediationClusterRoleBinding(config *Config) *rbacv1.ClusterRoleBinding {
+	return newControllerClusterRoleBinding(machineHealthCheckRemediationRole, config)
+}
+
+// newControllerClusterRoleBinding returns the binding of the cluster role to the service account
+// of the machine health check controller, the binding has the name of the cluster role
+func newControllerClusterRoleBinding(name string, config *Config) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: newObjectMeta(name, ""),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      machineHealthCheckControllerServiceAccount,
+				Namespace: config.TargetNamespace,
+			},
+		},
+	}
+}
+
+// newRole returns the role with the permissions the machine health check controller
+// needs in the target namespace to run the leader election
+func newRole(config *Config) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: newObjectMeta(machineHealthCheckControllerName, config.TargetNamespace),
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"get", "list", "watch", "create", "update"},
+			},
+			{
+				APIGroups: []string{"coordination.k8s.io"},
+				Resources: []string{"leases"},
+				Verbs:     []string{"get", "create", "update"},
+			},
+		},
+	}
+}
+
+func newRoleBinding(config *Config) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: newObjectMeta(machineHealthCheckControllerName, config.TargetNamespace),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     machineHealthCheckControllerName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      machineHealthCheckControllerServiceAccount,
+				Namespace: config.TargetNamespace,
+			},
+		},
+	}
+}
@@ -0,0 +1,126 @@
+package operator
+
+import (
+	"testing"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// aggregate returns the rules the API server aggregates into the cluster role
+// from the cluster roles matching its aggregation rule
+func aggregate(t *testing.T, clusterRole *rbacv1.ClusterRole, clusterRoles []*rbacv1.ClusterRole) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{}
+	for _, selector := range clusterRole.AggregationRule.ClusterRoleSelectors {
+		s, err := metav1.LabelSelectorAsSelector(&selector)
+		if err != nil {
+			t.Fatalf("Invalid aggregation rule of cluster role %s: %v", clusterRole.Name, err)
+		}
+		for _, cr := range clusterRoles {
+			if s.Matches(labels.Set(cr.Labels)) {
+				rules = append(rules, cr.Rules...)
+			}
+		}
+	}
+	return rules
+}
+
+// allows returns true when one of the rules grants the verb on the resource of the group
+func allows(rules []rbacv1.PolicyRule, group, resource, verb string) bool {
+	contains := func(values []string, value string) bool {
+		for _, v := range values {
+			if v == value || v == rbacv1.ResourceAll {
+				return true
+			}
+		}
+		return false
+	}
+	for _, rule := range rules {
+		if contains(rule.APIGroups, group) && contains(rule.Resources, resource) && contains(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRemediationClusterRole(t *testing.T) {
+	config := newOperatorConfig(false)
+	objects, err := RenderOperands(config)
+	if err != nil {
+		t.Fatalf("Failed to render operands: %v", err)
+	}
+	var remediationRole *rbacv1.ClusterRole
+	var remediationBinding *rbacv1.ClusterRoleBinding
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *rbacv1.ClusterRole:
+			if o.Name == machineHealthCheckRemediationRole {
+				remediationRole = o
+			}
+		case *rbacv1.ClusterRoleBinding:
+			if o.Name == machineHealthCheckRemediationRole {
+				remediationBinding = o
+			}
+		}
+	}
+	if remediationRole == nil || remediationBinding == nil {
+		t.Fatalf("Expected rendered %q cluster role and binding, got %v and %v", machineHealthCheckRemediationRole, remediationRole, remediationBinding)
+	}
+	if remediationBinding.RoleRef.Name != remediationRole.Name || len(remediationBinding.Subjects) != 1 || remediationBinding.Subjects[0].Name != machineHealthCheckControllerServiceAccount {
+		t.Errorf("Expected %q cluster role to be bound to the controller service account, got %+v", remediationRole.Name, remediationBinding)
+	}
+
+	// the cluster role of the template owner grants access to its templates and remediation objects
+	templateOwnerRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "example-remediation",
+			Labels: map[string]string{healthcheckingv1alpha1.RemediationRoleAggregationLabel: "true"},
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"remediation.example.com"},
+				Resources: []string{"examplemachineremediationtemplates"},
+				Verbs:     []string{"get"},
+			},
+			{
+				APIGroups: []string{"remediation.example.com"},
+				Resources: []string{"examplemachineremediations"},
+				Verbs:     []string{"get", "create", "delete"},
+			},
+		},
+	}
+	unlabeledRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "secrets-reader"},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get"},
+			},
+		},
+	}
+	rules := aggregate(t, remediationRole, []*rbacv1.ClusterRole{newClusterRole(), templateOwnerRole, unlabeledRole})
+
+	tests := []struct {
+		resource string
+		verb     string
+		expected bool
+	}{
+		{resource: "examplemachineremediationtemplates", verb: "get", expected: true},
+		{resource: "examplemachineremediations", verb: "get", expected: true},
+		{resource: "examplemachineremediations", verb: "create", expected: true},
+		{resource: "examplemachineremediations", verb: "delete", expected: true},
+		{resource: "examplemachineremediationtemplates", verb: "delete", expected: false},
+	}
+	for _, tc := range tests {
+		if got := allows(rules, "remediation.example.com", tc.resource, tc.verb); got != tc.expected {
+			t.Errorf("Expected %s on %s allowed %t, got %t", tc.verb, tc.resource, tc.expected, got)
+		}
+	}
+	if allows(rules, "", "secrets", "get") {
+		t.Errorf("Expected the rules of the unlabeled cluster role not to be aggregated, got %v", rules)
+	}
+}
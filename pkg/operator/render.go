@@ -0,0 +1,95 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	osev1 "github.com/openshift/api/config/v1"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// RenderConfig builds the operator configuration from the content of the images.json file,
+// the feature set and the operator config spec without accessing the cluster. The spec is
+// defaulted and validated the same way the operator does it.
+func RenderConfig(
+	targetNamespace string,
+	imagesJSON []byte,
+	featureSet osev1.FeatureSet,
+	rolloutDeadline time.Duration,
+	spec healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec,
+) (*Config, error) {
+	images, err := getImagesFromJSON(imagesJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse images: %v", err)
+	}
+	machineHealthCheckOperatorImage, err := getMachineHealthCheckOperatorFromImages(images)
+	if err != nil {
+		return nil, err
+	}
+
+	techPreviewEnabled, err := isTechPreviewEnabledForFeatureSet(featureSet)
+	if err != nil {
+		return nil, err
+	}
+
+	spec = *spec.DeepCopy()
+	healthcheckingv1alpha1.SetDefaultsMachineHealthCheckOperatorConfigSpec(&spec)
+	if errs := healthcheckingv1alpha1.ValidateMachineHealthCheckOperatorConfigSpec(&spec, field.NewPath("spec")); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+
+	return &Config{
+		TargetNamespace:    targetNamespace,
+		TechPreviewEnabled: techPreviewEnabled,
+		RolloutDeadline:    rolloutDeadline,
+		Spec:               spec,
+		Controllers: Controllers{
+			MachineHealthCheck: machineHealthCheckOperatorImage,
+		},
+	}, nil
+}
+
+// RenderOperands returns the operand objects, in the order they are applied by the operator
+// for the given configuration. An empty list is returned when the operands are not managed.
+func RenderOperands(config *Config) ([]runtime.Object, error) {
+	if config.Spec.ManagementState != healthcheckingv1alpha1.Managed {
+		return []runtime.Object{}, nil
+	}
+
+	serviceAccount := newServiceAccount(config)
+	serviceAccount.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"}
+	clusterRole := newClusterRole()
+	clusterRole.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"}
+	clusterRoleBinding := newClusterRoleBinding(config)
+	clusterRoleBinding.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"}
+	remediationClusterRole := newRemediationClusterRole()
+	remediationClusterRole.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"}
+	remediationClusterRoleBinding := newRemediationClusterRoleBinding(config)
+	remediationClusterRoleBinding.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"}
+	role := newRole(config)
+	role.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"}
+	roleBinding := newRoleBinding(config)
+	roleBinding.TypeMeta = metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "RoleBinding"}
+
+	service := newService(config)
+	service.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+	webhookConfiguration := newValidatingWebhookConfiguration(config)
+	webhookConfiguration.TypeMeta = metav1.TypeMeta{APIVersion: admissionregistrationv1beta1.SchemeGroupVersion.String(), Kind: "ValidatingWebhookConfiguration"}
+
+	deployment := newDeployment(config, config.TechPreviewEnabled)
+	if err := setSpecHashAnnotation(&deployment.ObjectMeta, deployment.Spec); err != nil {
+		return nil, err
+	}
+	deployment.TypeMeta = metav1.TypeMeta{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+	}
+
+	return []runtime.Object{serviceAccount, clusterRole, clusterRoleBinding, remediationClusterRole, remediationClusterRoleBinding, role, roleBinding, service, webhookConfiguration, deployment}, nil
+}
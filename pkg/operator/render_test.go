@@ -0,0 +1,111 @@
+package operator
+
+import (
+	"testing"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+const renderImagesJSON = `{"machineHealthCheckOperator": "quay.io/openshift/origin-machine-health-check-operator:v4.0.0"}`
+
+func TestRenderOperands(t *testing.T) {
+	tests := []struct {
+		name             string
+		featureSet       osconfigv1.FeatureSet
+		spec             healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec
+		expectedReplicas *int32
+	}{{
+		name:             "default configuration",
+		featureSet:       osconfigv1.Default,
+		expectedReplicas: pointer.Int32Ptr(1),
+	}, {
+		name:             "tech preview feature set",
+		featureSet:       osconfigv1.TechPreviewNoUpgrade,
+		expectedReplicas: pointer.Int32Ptr(0),
+	}, {
+		name:       "unmanaged operands",
+		featureSet: osconfigv1.Default,
+		spec: healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{
+			ManagementState: healthcheckingv1alpha1.Unmanaged,
+		},
+	}}
+
+	for _, tc := range tests {
+		config, err := RenderConfig(targetNamespace, []byte(renderImagesJSON), tc.featureSet, DefaultRolloutDeadline, tc.spec)
+		if err != nil {
+			t.Errorf("%s: failed to render config: %v", tc.name, err)
+			continue
+		}
+		objects, err := RenderOperands(config)
+		if err != nil {
+			t.Errorf("%s: failed to render operands: %v", tc.name, err)
+			continue
+		}
+		if tc.expectedReplicas == nil {
+			if len(objects) != 0 {
+				t.Errorf("%s: expected no operands, got %d", tc.name, len(objects))
+			}
+			continue
+		}
+		if len(objects) != 10 {
+			t.Errorf("%s: expected 10 operands, got %d", tc.name, len(objects))
+			continue
+		}
+		for _, obj := range objects {
+			if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Kind == "" || gvk.Version == "" {
+				t.Errorf("%s: expected rendered %T type meta, got %v", tc.name, obj, gvk)
+			}
+		}
+
+		// the deployment is applied once the service account, the roles and the webhook service exist
+		rendered := objects[len(objects)-1].(*appsv1.Deployment)
+		if *rendered.Spec.Replicas != *tc.expectedReplicas {
+			t.Errorf("%s: expected %d replicas, got %d", tc.name, *tc.expectedReplicas, *rendered.Spec.Replicas)
+		}
+		if rendered.Kind != "Deployment" || rendered.APIVersion != "apps/v1" {
+			t.Errorf("%s: expected rendered deployment type meta, got %v", tc.name, rendered.TypeMeta)
+		}
+
+		// the operator must not modify the rendered deployment
+		kubeClient := fakekube.NewSimpleClientset(rendered)
+		if _, modified, err := applyDeployment(kubeClient.AppsV1(), newDeployment(config, config.TechPreviewEnabled)); err != nil || modified {
+			t.Errorf("%s: expected the rendered deployment to be applied as is, got modified %t, err %v", tc.name, modified, err)
+		}
+	}
+}
+
+func TestRenderConfigErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		imagesJSON string
+		featureSet osconfigv1.FeatureSet
+		spec       healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec
+	}{{
+		name:       "invalid images",
+		imagesJSON: "{",
+	}, {
+		name:       "missing machine health check operator image",
+		imagesJSON: `{"machineAPIOperator": "docker.io/openshift/origin-machine-api-operator:v4.0.0"}`,
+	}, {
+		name:       "unknown feature set",
+		imagesJSON: renderImagesJSON,
+		featureSet: "Unknown",
+	}, {
+		name:       "invalid operator config",
+		imagesJSON: renderImagesJSON,
+		spec: healthcheckingv1alpha1.MachineHealthCheckOperatorConfigSpec{
+			LogLevel: pointer.Int32Ptr(-1),
+		},
+	}}
+
+	for _, tc := range tests {
+		if _, err := RenderConfig(targetNamespace, []byte(tc.imagesJSON), tc.featureSet, DefaultRolloutDeadline, tc.spec); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
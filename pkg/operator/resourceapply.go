@@ -0,0 +1,512 @@
+package operator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	admissionregistrationclientv1beta1 "k8s.io/client-go/kubernetes/typed/admissionregistration/v1beta1"
+	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	rbacclientv1 "k8s.io/client-go/kubernetes/typed/rbac/v1"
+)
+
+const (
+	// SpecHashAnnotation contains the annotation key with the hash of the desired object spec
+	SpecHashAnnotation = "healthchecking.openshift.io/spec-hash"
+)
+
+// applyDeployment merges the required deployment into the existing one and updates it
+// when the hash of the desired spec changed or when the existing pod template drifted
+// from the desired one. It returns the deployment from the cluster and whether it was modified.
+func applyDeployment(client appsclientv1.DeploymentsGetter, required *appsv1.Deployment) (*appsv1.Deployment, bool, error) {
+	required = required.DeepCopy()
+	if err := setSpecHashAnnotation(&required.ObjectMeta, required.Spec); err != nil {
+		return nil, false, err
+	}
+
+	existing, err := client.Deployments(required.Namespace).Get(required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.Deployments(required.Namespace).Create(required)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	metadataModified := mergeObjectMeta(&existing.ObjectMeta, required.ObjectMeta)
+	drift := deploymentDrift(existing, required)
+	if !metadataModified && len(drift) == 0 {
+		return existing, false, nil
+	}
+	if len(drift) > 0 {
+		glog.V(2).Infof("Deployment %s/%s drifted from the desired state: %v", required.Namespace, required.Name, drift)
+	}
+
+	toWrite := existing.DeepCopy()
+	toWrite.Spec = *required.Spec.DeepCopy()
+	actual, err := client.Deployments(required.Namespace).Update(toWrite)
+	return actual, true, err
+}
+
+// applyServiceAccount merges the required service account metadata into the existing one.
+// It returns the service account from the cluster and whether it was modified.
+func applyServiceAccount(client coreclientv1.ServiceAccountsGetter, required *corev1.ServiceAccount) (*corev1.ServiceAccount, bool, error) {
+	existing, err := client.ServiceAccounts(required.Namespace).Get(required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.ServiceAccounts(required.Namespace).Create(required)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing = existing.DeepCopy()
+	if !mergeObjectMeta(&existing.ObjectMeta, required.ObjectMeta) {
+		return existing, false, nil
+	}
+	actual, err := client.ServiceAccounts(required.Namespace).Update(existing)
+	return actual, true, err
+}
+
+// applyClusterRole merges the required cluster role into the existing one and replaces
+// its rules when they differ. The rules of an aggregated cluster role are set by the API server,
+// so its aggregation rule is replaced instead. It returns the cluster role from the cluster and
+// whether it was modified.
+func applyClusterRole(client rbacclientv1.ClusterRolesGetter, required *rbacv1.ClusterRole) (*rbacv1.ClusterRole, bool, error) {
+	existing, err := client.ClusterRoles().Get(required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.ClusterRoles().Create(required)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing = existing.DeepCopy()
+	metadataModified := mergeObjectMeta(&existing.ObjectMeta, required.ObjectMeta)
+	if required.AggregationRule != nil {
+		if !metadataModified && equality.Semantic.DeepEqual(existing.AggregationRule, required.AggregationRule) {
+			return existing, false, nil
+		}
+		existing.AggregationRule = required.AggregationRule
+	} else {
+		if !metadataModified && equality.Semantic.DeepEqual(existing.Rules, required.Rules) {
+			return existing, false, nil
+		}
+		existing.Rules = required.Rules
+	}
+	actual, err := client.ClusterRoles().Update(existing)
+	return actual, true, err
+}
+
+// applyClusterRoleBinding merges the required cluster role binding into the existing one and
+// replaces its subjects when they differ. The role reference is immutable, so the binding is
+// recreated when it changes. It returns the binding from the cluster and whether it was modified.
+func applyClusterRoleBinding(client rbacclientv1.ClusterRoleBindingsGetter, required *rbacv1.ClusterRoleBinding) (*rbacv1.ClusterRoleBinding, bool, error) {
+	existing, err := client.ClusterRoleBindings().Get(required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.ClusterRoleBindings().Create(required)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !equality.Semantic.DeepEqual(existing.RoleRef, required.RoleRef) {
+		glog.V(2).Infof("Recreating cluster role binding %s with the changed role reference", required.Name)
+		if err := client.ClusterRoleBindings().Delete(required.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return nil, false, err
+		}
+		actual, err := client.ClusterRoleBindings().Create(required)
+		return actual, true, err
+	}
+
+	existing = existing.DeepCopy()
+	metadataModified := mergeObjectMeta(&existing.ObjectMeta, required.ObjectMeta)
+	if !metadataModified && equality.Semantic.DeepEqual(existing.Subjects, required.Subjects) {
+		return existing, false, nil
+	}
+	existing.Subjects = required.Subjects
+	actual, err := client.ClusterRoleBindings().Update(existing)
+	return actual, true, err
+}
+
+// applyRole merges the required role into the existing one and replaces its rules
+// when they differ. It returns the role from the cluster and whether it was modified.
+func applyRole(client rbacclientv1.RolesGetter, required *rbacv1.Role) (*rbacv1.Role, bool, error) {
+	existing, err := client.Roles(required.Namespace).Get(required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.Roles(required.Namespace).Create(required)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing = existing.DeepCopy()
+	metadataModified := mergeObjectMeta(&existing.ObjectMeta, required.ObjectMeta)
+	if !metadataModified && equality.Semantic.DeepEqual(existing.Rules, required.Rules) {
+		return existing, false, nil
+	}
+	existing.Rules = required.Rules
+	actual, err := client.Roles(required.Namespace).Update(existing)
+	return actual, true, err
+}
+
+// applyRoleBinding merges the required role binding into the existing one and replaces its
+// subjects when they differ. The role reference is immutable, so the binding is recreated
+// when it changes. It returns the binding from the cluster and whether it was modified.
+func applyRoleBinding(client rbacclientv1.RoleBindingsGetter, required *rbacv1.RoleBinding) (*rbacv1.RoleBinding, bool, error) {
+	existing, err := client.RoleBindings(required.Namespace).Get(required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.RoleBindings(required.Namespace).Create(required)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !equality.Semantic.DeepEqual(existing.RoleRef, required.RoleRef) {
+		glog.V(2).Infof("Recreating role binding %s/%s with the changed role reference", required.Namespace, required.Name)
+		if err := client.RoleBindings(required.Namespace).Delete(required.Name, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return nil, false, err
+		}
+		actual, err := client.RoleBindings(required.Namespace).Create(required)
+		return actual, true, err
+	}
+
+	existing = existing.DeepCopy()
+	metadataModified := mergeObjectMeta(&existing.ObjectMeta, required.ObjectMeta)
+	if !metadataModified && equality.Semantic.DeepEqual(existing.Subjects, required.Subjects) {
+		return existing, false, nil
+	}
+	existing.Subjects = required.Subjects
+	actual, err := client.RoleBindings(required.Namespace).Update(existing)
+	return actual, true, err
+}
+
+// applyService merges the required service into the existing one and replaces its selector and
+// ports when they differ, the other fields of the spec, like the cluster IP, are set by the API
+// server. It returns the service from the cluster and whether it was modified.
+func applyService(client coreclientv1.ServicesGetter, required *corev1.Service) (*corev1.Service, bool, error) {
+	existing, err := client.Services(required.Namespace).Get(required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.Services(required.Namespace).Create(required)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing = existing.DeepCopy()
+	metadataModified := mergeObjectMeta(&existing.ObjectMeta, required.ObjectMeta)
+	if !metadataModified && len(serviceSpecDiff(existing, required)) == 0 {
+		return existing, false, nil
+	}
+	existing.Spec.Selector = required.Spec.Selector
+	existing.Spec.Ports = required.Spec.Ports
+	actual, err := client.Services(required.Namespace).Update(existing)
+	return actual, true, err
+}
+
+// applyValidatingWebhookConfiguration merges the required webhook configuration into the existing
+// one and replaces its webhooks when they differ. The CA bundles of the webhooks are injected by
+// the service CA operator, so the existing ones are kept. It returns the webhook configuration
+// from the cluster and whether it was modified.
+func applyValidatingWebhookConfiguration(client admissionregistrationclientv1beta1.ValidatingWebhookConfigurationsGetter, required *admissionregistrationv1beta1.ValidatingWebhookConfiguration) (*admissionregistrationv1beta1.ValidatingWebhookConfiguration, bool, error) {
+	existing, err := client.ValidatingWebhookConfigurations().Get(required.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		actual, err := client.ValidatingWebhookConfigurations().Create(required)
+		return actual, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing = existing.DeepCopy()
+	metadataModified := mergeObjectMeta(&existing.ObjectMeta, required.ObjectMeta)
+	webhooks := withCABundles(required.Webhooks, existing.Webhooks)
+	if !metadataModified && equality.Semantic.DeepEqual(existing.Webhooks, webhooks) {
+		return existing, false, nil
+	}
+	existing.Webhooks = webhooks
+	actual, err := client.ValidatingWebhookConfigurations().Update(existing)
+	return actual, true, err
+}
+
+// withCABundles returns a copy of the required webhooks with the CA bundles of the existing webhooks having the same name
+func withCABundles(required, existing []admissionregistrationv1beta1.Webhook) []admissionregistrationv1beta1.Webhook {
+	caBundles := map[string][]byte{}
+	for _, webhook := range existing {
+		caBundles[webhook.Name] = webhook.ClientConfig.CABundle
+	}
+	webhooks := []admissionregistrationv1beta1.Webhook{}
+	for _, webhook := range required {
+		webhook = *webhook.DeepCopy()
+		webhook.ClientConfig.CABundle = caBundles[webhook.Name]
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks
+}
+
+// setSpecHashAnnotation computes the hash of the provided spec and sets an annotation
+// with its value on the provided object meta.
+func setSpecHashAnnotation(objMeta *metav1.ObjectMeta, spec interface{}) error {
+	jsonBytes, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	specHash := sha256.Sum256(jsonBytes)
+	if objMeta.Annotations == nil {
+		objMeta.Annotations = map[string]string{}
+	}
+	objMeta.Annotations[SpecHashAnnotation] = hex.EncodeToString(specHash[:])
+	return nil
+}
+
+// mergeObjectMeta sets the required labels and annotations on the existing object meta
+// and returns true when something was changed.
+func mergeObjectMeta(existing *metav1.ObjectMeta, required metav1.ObjectMeta) bool {
+	modified := false
+	if existing.Labels == nil && len(required.Labels) > 0 {
+		existing.Labels = map[string]string{}
+	}
+	for k, v := range required.Labels {
+		if existing.Labels[k] != v {
+			existing.Labels[k] = v
+			modified = true
+		}
+	}
+	if existing.Annotations == nil && len(required.Annotations) > 0 {
+		existing.Annotations = map[string]string{}
+	}
+	for k, v := range required.Annotations {
+		if existing.Annotations[k] != v {
+			existing.Annotations[k] = v
+			modified = true
+		}
+	}
+	return modified
+}
+
+// FieldDiff describes a field managed by the operator whose live value differs from the desired one
+type FieldDiff struct {
+	Path    string
+	Live    interface{}
+	Desired interface{}
+}
+
+// DiffDeployment returns the fields managed by the operator, including the labels and
+// annotations set by the operator, that differ between the live and the desired deployment.
+func DiffDeployment(live, desired *appsv1.Deployment) []FieldDiff {
+	return append(diffObjectMeta(live.ObjectMeta, desired.ObjectMeta), deploymentSpecDiff(live, desired)...)
+}
+
+// DiffServiceAccount returns the labels and annotations set by the operator
+// that differ between the live and the desired service account.
+func DiffServiceAccount(live, desired *corev1.ServiceAccount) []FieldDiff {
+	return diffObjectMeta(live.ObjectMeta, desired.ObjectMeta)
+}
+
+// DiffService returns the fields managed by the operator that differ between the live and the desired service.
+func DiffService(live, desired *corev1.Service) []FieldDiff {
+	return append(diffObjectMeta(live.ObjectMeta, desired.ObjectMeta), serviceSpecDiff(live, desired)...)
+}
+
+// DiffValidatingWebhookConfiguration returns the fields managed by the operator that differ between the live and
+// the desired webhook configuration. The CA bundles of the webhooks are not managed by the operator.
+func DiffValidatingWebhookConfiguration(live, desired *admissionregistrationv1beta1.ValidatingWebhookConfiguration) []FieldDiff {
+	diffs := diffObjectMeta(live.ObjectMeta, desired.ObjectMeta)
+	if webhooks := withCABundles(desired.Webhooks, live.Webhooks); !equality.Semantic.DeepEqual(live.Webhooks, webhooks) {
+		diffs = append(diffs, FieldDiff{Path: "webhooks", Live: live.Webhooks, Desired: desired.Webhooks})
+	}
+	return diffs
+}
+
+// DiffClusterRole returns the fields managed by the operator that differ between the live and the desired cluster role.
+// The rules of an aggregated cluster role are not managed by the operator, its aggregation rule is compared instead.
+func DiffClusterRole(live, desired *rbacv1.ClusterRole) []FieldDiff {
+	diffs := diffObjectMeta(live.ObjectMeta, desired.ObjectMeta)
+	if desired.AggregationRule != nil {
+		if !equality.Semantic.DeepEqual(live.AggregationRule, desired.AggregationRule) {
+			diffs = append(diffs, FieldDiff{Path: "aggregationRule", Live: live.AggregationRule, Desired: desired.AggregationRule})
+		}
+		return diffs
+	}
+	return append(diffs, diffRules(live.Rules, desired.Rules)...)
+}
+
+// DiffClusterRoleBinding returns the fields managed by the operator that differ between the live and the desired cluster role binding.
+func DiffClusterRoleBinding(live, desired *rbacv1.ClusterRoleBinding) []FieldDiff {
+	diffs := diffObjectMeta(live.ObjectMeta, desired.ObjectMeta)
+	return append(diffs, diffBinding(live.RoleRef, desired.RoleRef, live.Subjects, desired.Subjects)...)
+}
+
+// DiffRole returns the fields managed by the operator that differ between the live and the desired role.
+func DiffRole(live, desired *rbacv1.Role) []FieldDiff {
+	return append(diffObjectMeta(live.ObjectMeta, desired.ObjectMeta), diffRules(live.Rules, desired.Rules)...)
+}
+
+// DiffRoleBinding returns the fields managed by the operator that differ between the live and the desired role binding.
+func DiffRoleBinding(live, desired *rbacv1.RoleBinding) []FieldDiff {
+	diffs := diffObjectMeta(live.ObjectMeta, desired.ObjectMeta)
+	return append(diffs, diffBinding(live.RoleRef, desired.RoleRef, live.Subjects, desired.Subjects)...)
+}
+
+func serviceSpecDiff(existing, required *corev1.Service) []FieldDiff {
+	diffs := []FieldDiff{}
+	if !equality.Semantic.DeepEqual(existing.Spec.Selector, required.Spec.Selector) {
+		diffs = append(diffs, FieldDiff{Path: "spec.selector", Live: existing.Spec.Selector, Desired: required.Spec.Selector})
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Ports, required.Spec.Ports) {
+		diffs = append(diffs, FieldDiff{Path: "spec.ports", Live: existing.Spec.Ports, Desired: required.Spec.Ports})
+	}
+	return diffs
+}
+
+func diffRules(live, desired []rbacv1.PolicyRule) []FieldDiff {
+	if equality.Semantic.DeepEqual(live, desired) {
+		return nil
+	}
+	return []FieldDiff{{Path: "rules", Live: live, Desired: desired}}
+}
+
+func diffBinding(liveRef, desiredRef rbacv1.RoleRef, liveSubjects, desiredSubjects []rbacv1.Subject) []FieldDiff {
+	diffs := []FieldDiff{}
+	if !equality.Semantic.DeepEqual(liveRef, desiredRef) {
+		diffs = append(diffs, FieldDiff{Path: "roleRef", Live: liveRef, Desired: desiredRef})
+	}
+	if !equality.Semantic.DeepEqual(liveSubjects, desiredSubjects) {
+		diffs = append(diffs, FieldDiff{Path: "subjects", Live: liveSubjects, Desired: desiredSubjects})
+	}
+	return diffs
+}
+
+// diffObjectMeta returns the labels and annotations of the desired object meta
+// missing or having a different value in the live one.
+func diffObjectMeta(live, desired metav1.ObjectMeta) []FieldDiff {
+	diffs := []FieldDiff{}
+	for k, v := range desired.Labels {
+		if live.Labels[k] != v {
+			diffs = append(diffs, FieldDiff{Path: fmt.Sprintf("metadata.labels[%s]", k), Live: live.Labels[k], Desired: v})
+		}
+	}
+	for k, v := range desired.Annotations {
+		if live.Annotations[k] != v {
+			diffs = append(diffs, FieldDiff{Path: fmt.Sprintf("metadata.annotations[%s]", k), Live: live.Annotations[k], Desired: v})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// deploymentDrift returns the list of the deployment fields managed by the operator
+// that differ between the existing and the required deployment. Fields that are
+// not set by the operator, like the ones defaulted by the API server, are ignored.
+func deploymentDrift(existing, required *appsv1.Deployment) []string {
+	drift := []string{}
+	for _, d := range deploymentSpecDiff(existing, required) {
+		drift = append(drift, d.Path)
+	}
+	return drift
+}
+
+func deploymentSpecDiff(existing, required *appsv1.Deployment) []FieldDiff {
+	diffs := []FieldDiff{}
+	add := func(path string, existing, required interface{}) {
+		diffs = append(diffs, FieldDiff{Path: path, Live: existing, Desired: required})
+	}
+
+	if !equality.Semantic.DeepEqual(existing.Spec.Replicas, required.Spec.Replicas) {
+		add("spec.replicas", existing.Spec.Replicas, required.Spec.Replicas)
+	}
+	if required.Spec.ProgressDeadlineSeconds != nil && !equality.Semantic.DeepEqual(existing.Spec.ProgressDeadlineSeconds, required.Spec.ProgressDeadlineSeconds) {
+		add("spec.progressDeadlineSeconds", existing.Spec.ProgressDeadlineSeconds, required.Spec.ProgressDeadlineSeconds)
+	}
+
+	existingPod := existing.Spec.Template.Spec
+	requiredPod := required.Spec.Template.Spec
+	for k, v := range required.Spec.Template.Labels {
+		if existing.Spec.Template.Labels[k] != v {
+			add("spec.template.metadata.labels", existing.Spec.Template.Labels, required.Spec.Template.Labels)
+			break
+		}
+	}
+	if !equality.Semantic.DeepEqual(existingPod.NodeSelector, requiredPod.NodeSelector) {
+		add("spec.template.spec.nodeSelector", existingPod.NodeSelector, requiredPod.NodeSelector)
+	}
+	if !equality.Semantic.DeepEqual(existingPod.Tolerations, requiredPod.Tolerations) {
+		add("spec.template.spec.tolerations", existingPod.Tolerations, requiredPod.Tolerations)
+	}
+	if !equality.Semantic.DeepEqual(existingPod.SecurityContext, requiredPod.SecurityContext) {
+		add("spec.template.spec.securityContext", existingPod.SecurityContext, requiredPod.SecurityContext)
+	}
+	if existingPod.ServiceAccountName != requiredPod.ServiceAccountName {
+		add("spec.template.spec.serviceAccountName", existingPod.ServiceAccountName, requiredPod.ServiceAccountName)
+	}
+	if existingPod.PriorityClassName != requiredPod.PriorityClassName {
+		add("spec.template.spec.priorityClassName", existingPod.PriorityClassName, requiredPod.PriorityClassName)
+	}
+	if !equality.Semantic.DeepEqual(existingPod.Volumes, requiredPod.Volumes) {
+		add("spec.template.spec.volumes", existingPod.Volumes, requiredPod.Volumes)
+	}
+	if len(existingPod.Containers) != len(requiredPod.Containers) {
+		add("spec.template.spec.containers", existingPod.Containers, requiredPod.Containers)
+		return diffs
+	}
+	for i := range requiredPod.Containers {
+		diffs = append(diffs, containerDiff(fmt.Sprintf("spec.template.spec.containers[%d]", i), &existingPod.Containers[i], &requiredPod.Containers[i])...)
+	}
+	return diffs
+}
+
+func containerDiff(path string, existing, required *corev1.Container) []FieldDiff {
+	diffs := []FieldDiff{}
+	add := func(field string, existing, required interface{}) {
+		diffs = append(diffs, FieldDiff{Path: path + "." + field, Live: existing, Desired: required})
+	}
+
+	if existing.Name != required.Name {
+		add("name", existing.Name, required.Name)
+	}
+	if existing.Image != required.Image {
+		add("image", existing.Image, required.Image)
+	}
+	if !equality.Semantic.DeepEqual(existing.Command, required.Command) {
+		add("command", existing.Command, required.Command)
+	}
+	if !equality.Semantic.DeepEqual(existing.Args, required.Args) {
+		add("args", existing.Args, required.Args)
+	}
+	if !equality.Semantic.DeepEqual(existing.Env, required.Env) {
+		add("env", existing.Env, required.Env)
+	}
+	if !equality.Semantic.DeepEqual(existing.Resources, required.Resources) {
+		add("resources", existing.Resources, required.Resources)
+	}
+	if !equality.Semantic.DeepEqual(existing.Ports, required.Ports) {
+		add("ports", existing.Ports, required.Ports)
+	}
+	if !equality.Semantic.DeepEqual(existing.VolumeMounts, required.VolumeMounts) {
+		add("volumeMounts", existing.VolumeMounts, required.VolumeMounts)
+	}
+	if required.ImagePullPolicy != "" && existing.ImagePullPolicy != required.ImagePullPolicy {
+		add("imagePullPolicy", existing.ImagePullPolicy, required.ImagePullPolicy)
+	}
+	if !equality.Semantic.DeepEqual(existing.SecurityContext, required.SecurityContext) {
+		add("securityContext", existing.SecurityContext, required.SecurityContext)
+	}
+	return diffs
+}
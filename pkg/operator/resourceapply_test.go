@@ -0,0 +1,376 @@
+package operator
+
+import (
+	"testing"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+func newAppliedDeployment(t *testing.T, mutate func(d *appsv1.Deployment)) *appsv1.Deployment {
+	d := newDeployment(newOperatorConfig(false), false)
+	if err := setSpecHashAnnotation(&d.ObjectMeta, d.Spec); err != nil {
+		t.Fatalf("Failed to set spec hash annotation: %v", err)
+	}
+	// emulate fields defaulted by the API server
+	d.Spec.Template.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+	d.Spec.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+	d.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	d.Spec.RevisionHistoryLimit = pointer.Int32Ptr(10)
+	if mutate != nil {
+		mutate(d)
+	}
+	return d
+}
+
+func newAppliedService(config *Config) *corev1.Service {
+	s := newService(config)
+	// emulate fields defaulted by the API server
+	s.Spec.Type = corev1.ServiceTypeClusterIP
+	s.Spec.ClusterIP = "172.30.0.10"
+	s.Spec.SessionAffinity = corev1.ServiceAffinityNone
+	return s
+}
+
+func newAppliedValidatingWebhookConfiguration(config *Config) *admissionregistrationv1beta1.ValidatingWebhookConfiguration {
+	wc := newValidatingWebhookConfiguration(config)
+	// emulate the CA bundle injected by the service CA operator
+	wc.Webhooks[0].ClientConfig.CABundle = []byte("service-ca")
+	return wc
+}
+
+func TestApplyDeployment(t *testing.T) {
+	tests := []struct {
+		name             string
+		existing         []runtime.Object
+		expectedModified bool
+	}{{
+		name:             "deployment does not exist",
+		expectedModified: true,
+	}, {
+		name:             "deployment with defaulted fields only",
+		existing:         []runtime.Object{newAppliedDeployment(t, nil)},
+		expectedModified: false,
+	}, {
+		name: "deployment with a different image",
+		existing: []runtime.Object{newAppliedDeployment(t, func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers[0].Image = "quay.io/openshift/origin-machine-api-operator:v3.11.0"
+		})},
+		expectedModified: true,
+	}, {
+		name: "deployment with edited args",
+		existing: []runtime.Object{newAppliedDeployment(t, func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers[0].Args = append(d.Spec.Template.Spec.Containers[0].Args, "--namespace=default")
+		})},
+		expectedModified: true,
+	}, {
+		name: "deployment with edited resources",
+		existing: []runtime.Object{newAppliedDeployment(t, func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}
+		})},
+		expectedModified: true,
+	}, {
+		name: "deployment with removed tolerations",
+		existing: []runtime.Object{newAppliedDeployment(t, func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.Tolerations = nil
+		})},
+		expectedModified: true,
+	}, {
+		name: "deployment with edited node selector",
+		existing: []runtime.Object{newAppliedDeployment(t, func(d *appsv1.Deployment) {
+			d.Spec.Template.Spec.NodeSelector = map[string]string{"node-role.kubernetes.io/worker": ""}
+		})},
+		expectedModified: true,
+	}, {
+		name: "deployment with outdated spec hash",
+		existing: []runtime.Object{newAppliedDeployment(t, func(d *appsv1.Deployment) {
+			d.Annotations[SpecHashAnnotation] = "outdated"
+		})},
+		expectedModified: true,
+	}}
+
+	for _, tc := range tests {
+		kubeClient := fakekube.NewSimpleClientset(tc.existing...)
+		required := newDeployment(newOperatorConfig(false), false)
+
+		actual, modified, err := applyDeployment(kubeClient.AppsV1(), required)
+		if err != nil {
+			t.Errorf("%s: failed to apply deployment: %v", tc.name, err)
+			continue
+		}
+		if modified != tc.expectedModified {
+			t.Errorf("%s: expected modified %t, got %t", tc.name, tc.expectedModified, modified)
+		}
+		if drift := deploymentDrift(actual, required); len(drift) != 0 {
+			t.Errorf("%s: expected no drift after apply, got %v", tc.name, drift)
+		}
+		if _, ok := actual.Annotations[SpecHashAnnotation]; !ok {
+			t.Errorf("%s: expected %q annotation on the applied deployment", tc.name, SpecHashAnnotation)
+		}
+
+		// the second apply should be a no-op
+		if _, modified, err := applyDeployment(kubeClient.AppsV1(), required); err != nil || modified {
+			t.Errorf("%s: expected the second apply to be a no-op, got modified %t, err %v", tc.name, modified, err)
+		}
+	}
+}
+
+func TestApplyDeploymentKeepsForeignMetadata(t *testing.T) {
+	existing := newAppliedDeployment(t, func(d *appsv1.Deployment) {
+		d.Labels["example.com/owner"] = "sre"
+		d.Spec.Template.Spec.Containers[0].Image = "quay.io/openshift/origin-machine-api-operator:v3.11.0"
+	})
+	kubeClient := fakekube.NewSimpleClientset(existing)
+
+	actual, _, err := applyDeployment(kubeClient.AppsV1(), newDeployment(newOperatorConfig(false), false))
+	if err != nil {
+		t.Fatalf("Failed to apply deployment: %v", err)
+	}
+	if actual.Labels["example.com/owner"] != "sre" {
+		t.Errorf("Expected foreign labels to be preserved, got %v", actual.Labels)
+	}
+	if actual.Labels[ManagedByLabel] != ManagedByLabelOperatorValue {
+		t.Errorf("Expected %q label to be set, got %v", ManagedByLabel, actual.Labels)
+	}
+	if _, err := kubeClient.AppsV1().Deployments(targetNamespace).Get(deploymentName, metav1.GetOptions{}); err != nil {
+		t.Errorf("Failed to get deployment: %v", err)
+	}
+}
+
+func TestApplyClusterRole(t *testing.T) {
+	tests := []struct {
+		name             string
+		existing         []runtime.Object
+		required         *rbacv1.ClusterRole
+		expectedModified bool
+	}{{
+		name:             "cluster role does not exist",
+		expectedModified: true,
+	}, {
+		name:             "cluster role up to date",
+		existing:         []runtime.Object{newClusterRole()},
+		expectedModified: false,
+	}, {
+		name: "cluster role with edited rules",
+		existing: func() []runtime.Object {
+			cr := newClusterRole()
+			cr.Rules = cr.Rules[1:]
+			return []runtime.Object{cr}
+		}(),
+		expectedModified: true,
+	}, {
+		name: "cluster role without the managed by label",
+		existing: func() []runtime.Object {
+			cr := newClusterRole()
+			cr.Labels = nil
+			return []runtime.Object{cr}
+		}(),
+		expectedModified: true,
+	}, {
+		name: "aggregated cluster role with the rules of the aggregated roles",
+		existing: func() []runtime.Object {
+			cr := newRemediationClusterRole()
+			cr.Rules = newClusterRole().Rules
+			return []runtime.Object{cr}
+		}(),
+		required:         newRemediationClusterRole(),
+		expectedModified: false,
+	}, {
+		name: "aggregated cluster role with an edited aggregation rule",
+		existing: func() []runtime.Object {
+			cr := newRemediationClusterRole()
+			cr.AggregationRule = &rbacv1.AggregationRule{}
+			return []runtime.Object{cr}
+		}(),
+		required:         newRemediationClusterRole(),
+		expectedModified: true,
+	}}
+
+	for _, tc := range tests {
+		kubeClient := fakekube.NewSimpleClientset(tc.existing...)
+		required := tc.required
+		if required == nil {
+			required = newClusterRole()
+		}
+
+		actual, modified, err := applyClusterRole(kubeClient.RbacV1(), required)
+		if err != nil {
+			t.Errorf("%s: failed to apply cluster role: %v", tc.name, err)
+			continue
+		}
+		if modified != tc.expectedModified {
+			t.Errorf("%s: expected modified %t, got %t", tc.name, tc.expectedModified, modified)
+		}
+		if diffs := DiffClusterRole(actual, required); len(diffs) != 0 {
+			t.Errorf("%s: expected no diff after apply, got %v", tc.name, diffs)
+		}
+	}
+}
+
+func TestApplyRoleBinding(t *testing.T) {
+	config := newOperatorConfig(false)
+	tests := []struct {
+		name             string
+		existing         []runtime.Object
+		expectedModified bool
+	}{{
+		name:             "role binding does not exist",
+		expectedModified: true,
+	}, {
+		name:             "role binding up to date",
+		existing:         []runtime.Object{newRoleBinding(config)},
+		expectedModified: false,
+	}, {
+		name: "role binding with an additional subject",
+		existing: func() []runtime.Object {
+			rb := newRoleBinding(config)
+			rb.Subjects = append(rb.Subjects, rbacv1.Subject{Kind: rbacv1.UserKind, Name: "admin"})
+			return []runtime.Object{rb}
+		}(),
+		expectedModified: true,
+	}, {
+		name: "role binding referencing another role",
+		existing: func() []runtime.Object {
+			rb := newRoleBinding(config)
+			rb.RoleRef.Name = "admin"
+			return []runtime.Object{rb}
+		}(),
+		expectedModified: true,
+	}}
+
+	for _, tc := range tests {
+		kubeClient := fakekube.NewSimpleClientset(tc.existing...)
+		required := newRoleBinding(config)
+
+		actual, modified, err := applyRoleBinding(kubeClient.RbacV1(), required)
+		if err != nil {
+			t.Errorf("%s: failed to apply role binding: %v", tc.name, err)
+			continue
+		}
+		if modified != tc.expectedModified {
+			t.Errorf("%s: expected modified %t, got %t", tc.name, tc.expectedModified, modified)
+		}
+		if diffs := DiffRoleBinding(actual, required); len(diffs) != 0 {
+			t.Errorf("%s: expected no diff after apply, got %v", tc.name, diffs)
+		}
+	}
+}
+
+func TestApplyService(t *testing.T) {
+	config := newOperatorConfig(false)
+	tests := []struct {
+		name             string
+		existing         []runtime.Object
+		expectedModified bool
+	}{{
+		name:             "service does not exist",
+		expectedModified: true,
+	}, {
+		name:             "service with defaulted fields only",
+		existing:         []runtime.Object{newAppliedService(config)},
+		expectedModified: false,
+	}, {
+		name: "service with an edited target port",
+		existing: func() []runtime.Object {
+			s := newAppliedService(config)
+			s.Spec.Ports[0].TargetPort = intstr.FromInt(8443)
+			return []runtime.Object{s}
+		}(),
+		expectedModified: true,
+	}, {
+		name: "service without the serving certificate annotation",
+		existing: func() []runtime.Object {
+			s := newAppliedService(config)
+			s.Annotations = nil
+			return []runtime.Object{s}
+		}(),
+		expectedModified: true,
+	}}
+
+	for _, tc := range tests {
+		kubeClient := fakekube.NewSimpleClientset(tc.existing...)
+		required := newService(config)
+
+		actual, modified, err := applyService(kubeClient.CoreV1(), required)
+		if err != nil {
+			t.Errorf("%s: failed to apply service: %v", tc.name, err)
+			continue
+		}
+		if modified != tc.expectedModified {
+			t.Errorf("%s: expected modified %t, got %t", tc.name, tc.expectedModified, modified)
+		}
+		if diffs := DiffService(actual, required); len(diffs) != 0 {
+			t.Errorf("%s: expected no diff after apply, got %v", tc.name, diffs)
+		}
+		if len(tc.existing) > 0 && actual.Spec.ClusterIP != "172.30.0.10" {
+			t.Errorf("%s: expected the cluster IP to be kept, got %q", tc.name, actual.Spec.ClusterIP)
+		}
+	}
+}
+
+func TestApplyValidatingWebhookConfiguration(t *testing.T) {
+	config := newOperatorConfig(false)
+	tests := []struct {
+		name             string
+		existing         []runtime.Object
+		expectedModified bool
+		expectedCABundle string
+	}{{
+		name:             "webhook configuration does not exist",
+		expectedModified: true,
+	}, {
+		name:             "webhook configuration with the injected CA bundle",
+		existing:         []runtime.Object{newAppliedValidatingWebhookConfiguration(config)},
+		expectedModified: false,
+		expectedCABundle: "service-ca",
+	}, {
+		name: "webhook configuration with edited rules",
+		existing: func() []runtime.Object {
+			wc := newAppliedValidatingWebhookConfiguration(config)
+			wc.Webhooks[0].Rules = nil
+			return []runtime.Object{wc}
+		}(),
+		expectedModified: true,
+		expectedCABundle: "service-ca",
+	}, {
+		name: "webhook configuration with a failing failure policy",
+		existing: func() []runtime.Object {
+			wc := newAppliedValidatingWebhookConfiguration(config)
+			failurePolicy := admissionregistrationv1beta1.Fail
+			wc.Webhooks[0].FailurePolicy = &failurePolicy
+			return []runtime.Object{wc}
+		}(),
+		expectedModified: true,
+		expectedCABundle: "service-ca",
+	}}
+
+	for _, tc := range tests {
+		kubeClient := fakekube.NewSimpleClientset(tc.existing...)
+		required := newValidatingWebhookConfiguration(config)
+
+		actual, modified, err := applyValidatingWebhookConfiguration(kubeClient.AdmissionregistrationV1beta1(), required)
+		if err != nil {
+			t.Errorf("%s: failed to apply validating webhook configuration: %v", tc.name, err)
+			continue
+		}
+		if modified != tc.expectedModified {
+			t.Errorf("%s: expected modified %t, got %t", tc.name, tc.expectedModified, modified)
+		}
+		if diffs := DiffValidatingWebhookConfiguration(actual, required); len(diffs) != 0 {
+			t.Errorf("%s: expected no diff after apply, got %v", tc.name, diffs)
+		}
+		if caBundle := string(actual.Webhooks[0].ClientConfig.CABundle); caBundle != tc.expectedCABundle {
+			t.Errorf("%s: expected CA bundle %q, got %q", tc.name, tc.expectedCABundle, caBundle)
+		}
+	}
+}
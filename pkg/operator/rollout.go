@@ -0,0 +1,74 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/machine-health-check-operator/pkg/metrics"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultRolloutDeadline contains the default time the operand deployment has to make
+	// a rollout progress before the operator reports it as failed
+	DefaultRolloutDeadline = 5 * time.Minute
+
+	// progressDeadlineExceededReason is the reason set by the deployment controller on the
+	// Progressing condition once the deployment progress deadline exceeded
+	progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+)
+
+// RolloutState describes the rollout state of the operand deployment
+type RolloutState string
+
+const (
+	// RolloutStateComplete means that all replicas of the deployment are updated and available
+	RolloutStateComplete RolloutState = "Complete"
+	// RolloutStateProgressing means that the deployment rollout is still in progress
+	RolloutStateProgressing RolloutState = "Progressing"
+	// RolloutStateFailed means that the deployment did not make any progress within its deadline
+	RolloutStateFailed RolloutState = "Failed"
+)
+
+// deploymentRolloutState returns the rollout state of the deployment together with
+// a human readable message that explains it.
+func deploymentRolloutState(d *appsv1.Deployment) (RolloutState, string) {
+	if d.DeletionTimestamp != nil {
+		return RolloutStateProgressing, fmt.Sprintf("deployment %q is being deleted", d.Name)
+	}
+
+	if d.Generation > d.Status.ObservedGeneration {
+		return RolloutStateProgressing, fmt.Sprintf("waiting for deployment %q spec update to be observed", d.Name)
+	}
+
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse && c.Reason == progressDeadlineExceededReason {
+			return RolloutStateFailed, fmt.Sprintf("deployment %q exceeded its rollout deadline: %s", d.Name, c.Message)
+		}
+	}
+
+	// the same checks as done by "kubectl rollout status"
+	if d.Spec.Replicas != nil && d.Status.UpdatedReplicas < *d.Spec.Replicas ||
+		d.Status.Replicas > d.Status.UpdatedReplicas ||
+		d.Status.AvailableReplicas < d.Status.UpdatedReplicas {
+		return RolloutStateProgressing, fmt.Sprintf(
+			"waiting for deployment %q rollout to finish: (replicas: %d, updated: %d, available: %d, unavailable: %d)",
+			d.Name, d.Status.Replicas, d.Status.UpdatedReplicas, d.Status.AvailableReplicas, d.Status.UnavailableReplicas,
+		)
+	}
+
+	return RolloutStateComplete, fmt.Sprintf("deployment %q is rolled out", d.Name)
+}
+
+// reportRolloutState publishes the rollout state of the given operand deployment
+func reportRolloutState(deployment string, state RolloutState) {
+	for _, s := range []RolloutState{RolloutStateComplete, RolloutStateProgressing, RolloutStateFailed} {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		metrics.OperandRolloutState.WithLabelValues(deployment, string(s)).Set(value)
+	}
+}
@@ -0,0 +1,92 @@
+package operator
+
+import (
+	"testing"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/machine-health-check-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+)
+
+func newRolloutDeployment(generation, observedGeneration int64, status appsv1.DeploymentStatus) *appsv1.Deployment {
+	d := newDeployment(newOperatorConfig(false), false)
+	d.Generation = generation
+	d.Status = status
+	d.Status.ObservedGeneration = observedGeneration
+	return d
+}
+
+func TestDeploymentRolloutState(t *testing.T) {
+	tests := []struct {
+		name          string
+		deployment    *appsv1.Deployment
+		expectedState RolloutState
+	}{{
+		name:          "spec update is not observed",
+		deployment:    newRolloutDeployment(2, 1, appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1}),
+		expectedState: RolloutStateProgressing,
+	}, {
+		name:          "replicas are not updated",
+		deployment:    newRolloutDeployment(2, 2, appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, UnavailableReplicas: 1}),
+		expectedState: RolloutStateProgressing,
+	}, {
+		name: "progress deadline exceeded",
+		deployment: newRolloutDeployment(2, 2, appsv1.DeploymentStatus{
+			Replicas:            2,
+			UpdatedReplicas:     1,
+			UnavailableReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentProgressing,
+				Status:  corev1.ConditionFalse,
+				Reason:  progressDeadlineExceededReason,
+				Message: "ReplicaSet has timed out progressing.",
+			}},
+		}),
+		expectedState: RolloutStateFailed,
+	}, {
+		name:          "old replicas are still running",
+		deployment:    newRolloutDeployment(2, 2, appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1}),
+		expectedState: RolloutStateProgressing,
+	}, {
+		name:          "rollout complete",
+		deployment:    newRolloutDeployment(2, 2, appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}),
+		expectedState: RolloutStateComplete,
+	}}
+
+	for _, tc := range tests {
+		state, message := deploymentRolloutState(tc.deployment)
+		if state != tc.expectedState {
+			t.Errorf("%s: expected rollout state %q, got %q (%s)", tc.name, tc.expectedState, state, message)
+		}
+	}
+}
+
+func TestSyncAllDoesNotWaitForRollout(t *testing.T) {
+	optr := newFakeStatusOperator()
+	optr.kubeClient = fakekube.NewSimpleClientset()
+
+	// the fake client never rolls out the deployment, so the sync has to return
+	// immediately and report the rollout through the Progressing condition
+	if err := optr.syncAll(newOperatorConfig(false)); err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+	if status := getConditionStatus(t, optr, osconfigv1.OperatorProgressing); status != osconfigv1.ConditionTrue {
+		t.Errorf("Expected Progressing %q, got %q", osconfigv1.ConditionTrue, status)
+	}
+
+	d, err := optr.kubeClient.AppsV1().Deployments(targetNamespace).Get(deploymentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if d.Spec.ProgressDeadlineSeconds == nil || *d.Spec.ProgressDeadlineSeconds != int32(DefaultRolloutDeadline.Seconds()) {
+		t.Errorf("Expected progress deadline %v, got %v", DefaultRolloutDeadline, d.Spec.ProgressDeadlineSeconds)
+	}
+	if value := testutil.ToFloat64(metrics.OperandRolloutState.WithLabelValues(deploymentName, string(RolloutStateProgressing))); value != 1 {
+		t.Errorf("Expected %q rollout state metric to be 1, got %v", RolloutStateProgressing, value)
+	}
+}
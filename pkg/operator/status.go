@@ -0,0 +1,325 @@
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/golang/glog"
+	osconfigv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/machine-health-check-operator/pkg/metrics"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusReason is a MixedCaps string representing the reason for a
+// status condition change.
+type StatusReason string
+
+// The default set of status change reasons.
+const (
+	ReasonAsExpected StatusReason = "AsExpected"
+	ReasonEmpty      StatusReason = ""
+	ReasonSyncing    StatusReason = "SyncingResources"
+	ReasonSyncFailed StatusReason = "SyncingFailed"
+	// ReasonInvalidConfiguration is used when the operator fails to build its configuration
+	ReasonInvalidConfiguration StatusReason = "InvalidConfiguration"
+	// ReasonRollingOut is used while the operand deployment is being rolled out
+	ReasonRollingOut StatusReason = "RollingOut"
+	// ReasonRolloutDeadlineExceeded is used when the operand deployment did not progress within its deadline
+	ReasonRolloutDeadlineExceeded StatusReason = "RolloutDeadlineExceeded"
+	// ReasonUnmanaged is used when the operator config management state is Unmanaged
+	ReasonUnmanaged StatusReason = "Unmanaged"
+	// ReasonRemoving is used while the operands are being removed
+	ReasonRemoving StatusReason = "Removing"
+	// ReasonRemoved is used once all the operands were removed
+	ReasonRemoved StatusReason = "Removed"
+)
+
+const (
+	// clusterOperatorName contains the name of the ClusterOperator object reported by the operator
+	clusterOperatorName = "machine-health-check"
+	// releaseVersionEnvVariableName contains the name of the environment variable with the release version
+	releaseVersionEnvVariableName = "RELEASE_VERSION"
+	// operatorVersionName contains the name of the operator entry under the ClusterOperator versions
+	operatorVersionName = "operator"
+)
+
+// statusProgressing sets the Progressing condition to True when the operand
+// versions reported by the ClusterOperator differ from the desired ones and to
+// False otherwise.
+func (optr *Operator) statusProgressing() error {
+	desiredVersions := optr.operandVersions
+	co, err := optr.getOrCreateClusterOperator()
+	if err != nil {
+		glog.Errorf("Failed to get or create Cluster Operator: %v", err)
+		return err
+	}
+
+	var isProgressing osconfigv1.ConditionStatus
+	var message string
+	if !reflect.DeepEqual(desiredVersions, co.Status.Versions) {
+		glog.V(2).Info("Syncing status: progressing")
+		message = fmt.Sprintf("Progressing towards %s", printOperandVersions(desiredVersions))
+		optr.eventRecorder.Event(co, corev1.EventTypeNormal, "StatusUpgrade", message)
+		isProgressing = osconfigv1.ConditionTrue
+	} else {
+		glog.V(2).Info("Syncing status: re-syncing")
+		message = fmt.Sprintf("Running resync for %s", printOperandVersions(desiredVersions))
+		isProgressing = osconfigv1.ConditionFalse
+	}
+
+	conds := []osconfigv1.ClusterOperatorStatusCondition{
+		newClusterOperatorStatusCondition(osconfigv1.OperatorProgressing, isProgressing, string(ReasonSyncing), message),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, string(ReasonAsExpected), ""),
+	}
+	return optr.syncStatus(co, conds)
+}
+
+// statusAvailable sets the Available condition to True, sets both the
+// Progressing and Degraded conditions to False and publishes the operand
+// versions.
+func (optr *Operator) statusAvailable() error {
+	conds := []osconfigv1.ClusterOperatorStatusCondition{
+		newClusterOperatorStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, string(ReasonAsExpected),
+			fmt.Sprintf("Cluster Machine Health Check Operator is available at %s", printOperandVersions(optr.operandVersions))),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(ReasonEmpty), ""),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionFalse, string(ReasonEmpty), ""),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, string(ReasonAsExpected), ""),
+	}
+
+	co, err := optr.getOrCreateClusterOperator()
+	if err != nil {
+		return err
+	}
+
+	// important: we only write the version field if we report available at the present level
+	co.Status.Versions = optr.operandVersions
+	glog.V(2).Info("Syncing status: available")
+	return optr.syncStatus(co, conds)
+}
+
+// statusRollingOut sets the Progressing condition to True with the given rollout
+// message and sets the Degraded condition to False.
+func (optr *Operator) statusRollingOut(message string) error {
+	conds := []osconfigv1.ClusterOperatorStatusCondition{
+		newClusterOperatorStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionTrue, string(ReasonRollingOut), message),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionFalse, string(ReasonEmpty), ""),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, string(ReasonAsExpected), ""),
+	}
+
+	co, err := optr.getOrCreateClusterOperator()
+	if err != nil {
+		return err
+	}
+	glog.V(2).Infof("Syncing status: rolling out: %s", message)
+	return optr.syncStatus(co, conds)
+}
+
+// statusUnmanaged reports the observed state of the operands, which are not
+// reconciled by the operator, and sets both the Progressing and Degraded
+// conditions to False.
+func (optr *Operator) statusUnmanaged(available bool, message string) error {
+	isAvailable := osconfigv1.ConditionFalse
+	if available {
+		isAvailable = osconfigv1.ConditionTrue
+	}
+	conds := []osconfigv1.ClusterOperatorStatusCondition{
+		newClusterOperatorStatusCondition(osconfigv1.OperatorAvailable, isAvailable, string(ReasonUnmanaged), message),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(ReasonUnmanaged),
+			"The operator is not reconciling its operands because the management state is Unmanaged"),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionFalse, string(ReasonEmpty), ""),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, string(ReasonAsExpected), ""),
+	}
+
+	co, err := optr.getOrCreateClusterOperator()
+	if err != nil {
+		return err
+	}
+
+	// the operator itself is running at the present level even when it does not manage its operands
+	co.Status.Versions = optr.operandVersions
+	glog.V(2).Infof("Syncing status: unmanaged: %s", message)
+	return optr.syncStatus(co, conds)
+}
+
+// statusRemoving sets the Progressing condition to True while the operands
+// are being deleted and sets the Degraded condition to False.
+func (optr *Operator) statusRemoving(message string) error {
+	conds := []osconfigv1.ClusterOperatorStatusCondition{
+		newClusterOperatorStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionTrue, string(ReasonRemoving), message),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionFalse, string(ReasonEmpty), ""),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, string(ReasonAsExpected), ""),
+	}
+
+	co, err := optr.getOrCreateClusterOperator()
+	if err != nil {
+		return err
+	}
+	glog.V(2).Infof("Syncing status: removing: %s", message)
+	return optr.syncStatus(co, conds)
+}
+
+// statusRemoved sets the Available condition to True once all the operands
+// were removed, sets both the Progressing and Degraded conditions to False and
+// publishes the operand versions.
+func (optr *Operator) statusRemoved() error {
+	conds := []osconfigv1.ClusterOperatorStatusCondition{
+		newClusterOperatorStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, string(ReasonRemoved),
+			"The machine health check controller was removed because the management state is Removed"),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(ReasonRemoved), ""),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionFalse, string(ReasonEmpty), ""),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, string(ReasonAsExpected), ""),
+	}
+
+	co, err := optr.getOrCreateClusterOperator()
+	if err != nil {
+		return err
+	}
+
+	co.Status.Versions = optr.operandVersions
+	glog.V(2).Info("Syncing status: removed")
+	return optr.syncStatus(co, conds)
+}
+
+// statusDegraded sets the Degraded condition to True, with the given reason and
+// message, and sets the Progressing condition to False.
+func (optr *Operator) statusDegraded(reason StatusReason, errMsg string) error {
+	co, err := optr.getOrCreateClusterOperator()
+	if err != nil {
+		return err
+	}
+
+	desiredVersions := optr.operandVersions
+	var message string
+	if !reflect.DeepEqual(desiredVersions, co.Status.Versions) {
+		message = fmt.Sprintf("Failed when progressing towards %s because %s", printOperandVersions(desiredVersions), errMsg)
+	} else {
+		message = fmt.Sprintf("Failed to resync for %s because %s", printOperandVersions(desiredVersions), errMsg)
+	}
+
+	conds := []osconfigv1.ClusterOperatorStatusCondition{
+		newClusterOperatorStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(reason), message),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(ReasonEmpty), ""),
+		newClusterOperatorStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, string(ReasonAsExpected), ""),
+	}
+
+	metrics.SyncErrors.WithLabelValues(string(reason)).Inc()
+	optr.eventRecorder.Event(co, corev1.EventTypeWarning, "StatusDegraded", errMsg)
+	glog.V(2).Infof("Syncing status: degraded: %s", message)
+	return optr.syncStatus(co, conds)
+}
+
+func (optr *Operator) syncStatus(co *osconfigv1.ClusterOperator, conds []osconfigv1.ClusterOperatorStatusCondition) error {
+	for _, c := range conds {
+		setClusterOperatorStatusCondition(&co.Status.Conditions, c)
+	}
+
+	co.Status.RelatedObjects = optr.relatedObjects()
+
+	_, err := optr.osClient.ConfigV1().ClusterOperators().UpdateStatus(co)
+	return err
+}
+
+func (optr *Operator) relatedObjects() []osconfigv1.ObjectReference {
+	return []osconfigv1.ObjectReference{
+		{
+			Group:    "",
+			Resource: "namespaces",
+			Name:     optr.namespace,
+		},
+		{
+			Group:     "apps",
+			Resource:  "deployments",
+			Namespace: optr.namespace,
+			Name:      machineHealthCheckControllerName,
+		},
+		{
+			Group:     "",
+			Resource:  "configmaps",
+			Namespace: optr.namespace,
+			Name:      machineAPIOperatorImages,
+		},
+		{
+			Group:    "admissionregistration.k8s.io",
+			Resource: "validatingwebhookconfigurations",
+			Name:     machineHealthCheckControllerName,
+		},
+	}
+}
+
+func (optr *Operator) getOrCreateClusterOperator() (*osconfigv1.ClusterOperator, error) {
+	co := &osconfigv1.ClusterOperator{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ClusterOperator",
+			APIVersion: "config.openshift.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterOperatorName,
+		},
+		Status: osconfigv1.ClusterOperatorStatus{},
+	}
+	existing, err := optr.osClient.ConfigV1().ClusterOperators().Get(clusterOperatorName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		glog.Infof("ClusterOperator does not exist, creating a new one.")
+		return optr.osClient.ConfigV1().ClusterOperators().Create(co)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clusterOperator %q: %v", clusterOperatorName, err)
+	}
+	return existing, nil
+}
+
+// newClusterOperatorStatusCondition returns a new condition with the last
+// transition time set to now.
+func newClusterOperatorStatusCondition(conditionType osconfigv1.ClusterStatusConditionType,
+	conditionStatus osconfigv1.ConditionStatus, reason string,
+	message string) osconfigv1.ClusterOperatorStatusCondition {
+	return osconfigv1.ClusterOperatorStatusCondition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// setClusterOperatorStatusCondition sets the corresponding condition in
+// conditions to newCondition. The last transition time is only updated when
+// the condition status changes.
+func setClusterOperatorStatusCondition(conditions *[]osconfigv1.ClusterOperatorStatusCondition, newCondition osconfigv1.ClusterOperatorStatusCondition) {
+	existingCondition := findClusterOperatorStatusCondition(*conditions, newCondition.Type)
+	if existingCondition == nil {
+		*conditions = append(*conditions, newCondition)
+		return
+	}
+
+	if existingCondition.Status != newCondition.Status {
+		existingCondition.Status = newCondition.Status
+		existingCondition.LastTransitionTime = newCondition.LastTransitionTime
+	}
+
+	existingCondition.Reason = newCondition.Reason
+	existingCondition.Message = newCondition.Message
+}
+
+// findClusterOperatorStatusCondition returns the condition with the given type
+// or nil when the condition does not exist.
+func findClusterOperatorStatusCondition(conditions []osconfigv1.ClusterOperatorStatusCondition, conditionType osconfigv1.ClusterStatusConditionType) *osconfigv1.ClusterOperatorStatusCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func printOperandVersions(versions []osconfigv1.OperandVersion) string {
+	versionsOutput := []string{}
+	for _, operand := range versions {
+		versionsOutput = append(versionsOutput, fmt.Sprintf("%s: %s", operand.Name, operand.Version))
+	}
+	return strings.Join(versionsOutput, ", ")
+}
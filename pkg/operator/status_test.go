@@ -0,0 +1,111 @@
+package operator
+
+import (
+	"testing"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	fakeos "github.com/openshift/client-go/config/clientset/versioned/fake"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+func newFakeStatusOperator(osObjects ...runtime.Object) *Operator {
+	return &Operator{
+		namespace:     targetNamespace,
+		osClient:      fakeos.NewSimpleClientset(osObjects...),
+		eventRecorder: record.NewFakeRecorder(50),
+		operandVersions: []osconfigv1.OperandVersion{
+			{Name: operatorVersionName, Version: "4.2.0"},
+		},
+	}
+}
+
+func newClusterOperator(versions []osconfigv1.OperandVersion) *osconfigv1.ClusterOperator {
+	return &osconfigv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterOperatorName,
+		},
+		Status: osconfigv1.ClusterOperatorStatus{
+			Versions: versions,
+		},
+	}
+}
+
+func getConditionStatus(t *testing.T, optr *Operator, conditionType osconfigv1.ClusterStatusConditionType) osconfigv1.ConditionStatus {
+	co, err := optr.osClient.ConfigV1().ClusterOperators().Get(clusterOperatorName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get ClusterOperator: %v", err)
+	}
+	condition := findClusterOperatorStatusCondition(co.Status.Conditions, conditionType)
+	if condition == nil {
+		return osconfigv1.ConditionUnknown
+	}
+	return condition.Status
+}
+
+func TestStatusProgressing(t *testing.T) {
+	tests := []struct {
+		name                string
+		existing            []runtime.Object
+		expectedProgressing osconfigv1.ConditionStatus
+	}{{
+		name:                "ClusterOperator does not exist",
+		expectedProgressing: osconfigv1.ConditionTrue,
+	}, {
+		name:                "ClusterOperator reports older version",
+		existing:            []runtime.Object{newClusterOperator([]osconfigv1.OperandVersion{{Name: operatorVersionName, Version: "4.1.0"}})},
+		expectedProgressing: osconfigv1.ConditionTrue,
+	}, {
+		name:                "ClusterOperator reports desired version",
+		existing:            []runtime.Object{newClusterOperator([]osconfigv1.OperandVersion{{Name: operatorVersionName, Version: "4.2.0"}})},
+		expectedProgressing: osconfigv1.ConditionFalse,
+	}}
+
+	for _, tc := range tests {
+		optr := newFakeStatusOperator(tc.existing...)
+		if err := optr.statusProgressing(); err != nil {
+			t.Errorf("%s: failed to sync status: %v", tc.name, err)
+			continue
+		}
+		if status := getConditionStatus(t, optr, osconfigv1.OperatorProgressing); status != tc.expectedProgressing {
+			t.Errorf("%s: expected Progressing %q, got %q", tc.name, tc.expectedProgressing, status)
+		}
+	}
+}
+
+func TestStatusAvailable(t *testing.T) {
+	optr := newFakeStatusOperator()
+	if err := optr.statusDegraded(ReasonSyncFailed, "failed to apply deployment"); err != nil {
+		t.Fatalf("Failed to sync degraded status: %v", err)
+	}
+	if status := getConditionStatus(t, optr, osconfigv1.OperatorDegraded); status != osconfigv1.ConditionTrue {
+		t.Errorf("Expected Degraded %q, got %q", osconfigv1.ConditionTrue, status)
+	}
+
+	if err := optr.statusAvailable(); err != nil {
+		t.Fatalf("Failed to sync available status: %v", err)
+	}
+	for conditionType, expected := range map[osconfigv1.ClusterStatusConditionType]osconfigv1.ConditionStatus{
+		osconfigv1.OperatorAvailable:   osconfigv1.ConditionTrue,
+		osconfigv1.OperatorProgressing: osconfigv1.ConditionFalse,
+		osconfigv1.OperatorDegraded:    osconfigv1.ConditionFalse,
+		osconfigv1.OperatorUpgradeable: osconfigv1.ConditionTrue,
+	} {
+		if status := getConditionStatus(t, optr, conditionType); status != expected {
+			t.Errorf("Expected %s %q, got %q", conditionType, expected, status)
+		}
+	}
+
+	co, err := optr.osClient.ConfigV1().ClusterOperators().Get(clusterOperatorName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get ClusterOperator: %v", err)
+	}
+	if len(co.Status.Versions) != 1 || co.Status.Versions[0].Version != "4.2.0" {
+		t.Errorf("Expected operand versions %v, got %v", optr.operandVersions, co.Status.Versions)
+	}
+	if len(co.Status.RelatedObjects) != len(optr.relatedObjects()) {
+		t.Errorf("Expected %d related objects, got %d", len(optr.relatedObjects()), len(co.Status.RelatedObjects))
+	}
+}
@@ -6,61 +6,154 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
-	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
 	"k8s.io/utils/pointer"
 
 	"github.com/golang/glog"
 )
 
 const (
-	deploymentRolloutPollInterval = time.Second
-	deploymentRolloutTimeout      = 5 * time.Minute
+	// machineHealthCheckControllerName contains the name of the machine health check controller deployment
+	machineHealthCheckControllerName = "machine-health-check-controller"
 )
 
 func (optr *Operator) syncAll(config *Config) error {
-	controller := newDeployment(config, config.TechPreviewEnabled)
-	updated, err := applyDeploymentReplicas(optr.kubeClient.AppsV1(), controller)
+	if err := optr.statusProgressing(); err != nil {
+		glog.Errorf("Error syncing ClusterOperator status: %v", err)
+		return fmt.Errorf("error syncing ClusterOperator status: %v", err)
+	}
+
+	if err := optr.syncMachineHealthCheckControllerRBAC(config); err != nil {
+		if errStatus := optr.statusDegraded(ReasonSyncFailed, err.Error()); errStatus != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", errStatus)
+		}
+		glog.Errorf("Error syncing machine health check controller RBAC: %v", err)
+		return err
+	}
+
+	if err := optr.syncMachineHealthCheckWebhook(config); err != nil {
+		if errStatus := optr.statusDegraded(ReasonSyncFailed, err.Error()); errStatus != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", errStatus)
+		}
+		glog.Errorf("Error syncing machine health check webhook: %v", err)
+		return err
+	}
+
+	state, message, err := optr.syncMachineHealthCheckController(config)
 	if err != nil {
+		if errStatus := optr.statusDegraded(ReasonSyncFailed, err.Error()); errStatus != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", errStatus)
+		}
+		glog.Errorf("Error syncing machine health check controller: %v", err)
 		return err
 	}
-	if updated {
-		glog.V(4).Infof("Update deployment %s with replicas %d", controller.Name, *controller.Spec.Replicas)
-		return optr.waitForDeploymentRollout(controller)
+
+	// the rollout progress is tracked by the deployment informer events, so we do not
+	// requeue the key while the rollout is in progress or once it failed
+	switch state {
+	case RolloutStateFailed:
+		glog.Errorf("Failed to roll out machine health check controller: %s", message)
+		if err := optr.statusDegraded(ReasonRolloutDeadlineExceeded, message); err != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", err)
+			return fmt.Errorf("error syncing ClusterOperator status: %v", err)
+		}
+		return nil
+	case RolloutStateProgressing:
+		glog.V(3).Infof("Rolling out machine health check controller: %s", message)
+		if err := optr.statusRollingOut(message); err != nil {
+			glog.Errorf("Error syncing ClusterOperator status: %v", err)
+			return fmt.Errorf("error syncing ClusterOperator status: %v", err)
+		}
+		return nil
+	}
+	glog.V(3).Info("Synced up all machine health check components")
+
+	if err := optr.statusAvailable(); err != nil {
+		glog.Errorf("Error syncing ClusterOperator status: %v", err)
+		return fmt.Errorf("error syncing ClusterOperator status: %v", err)
 	}
 	return nil
 }
 
-func (optr *Operator) waitForDeploymentRollout(resource *appsv1.Deployment) error {
-	return wait.Poll(deploymentRolloutPollInterval, deploymentRolloutTimeout, func() (bool, error) {
-		d, err := optr.deployLister.Deployments(resource.Namespace).Get(resource.Name)
-		if apierrors.IsNotFound(err) {
-			return false, nil
-		}
-		if err != nil {
-			// Do not return error here, as we could be updating the API Server itself, in which case we
-			// want to continue waiting.
-			glog.Errorf("Error getting Deployment %q during rollout: %v", resource.Name, err)
-			return false, nil
-		}
+// syncMachineHealthCheckControllerRBAC applies the service account the machine health check
+// controller runs as and the roles granting it the permissions it needs.
+func (optr *Operator) syncMachineHealthCheckControllerRBAC(config *Config) error {
+	serviceAccount := newServiceAccount(config)
+	if _, updated, err := applyServiceAccount(optr.kubeClient.CoreV1(), serviceAccount); err != nil {
+		return fmt.Errorf("error applying service account %s: %v", serviceAccount.Name, err)
+	} else if updated {
+		glog.V(4).Infof("Applied service account %s", serviceAccount.Name)
+	}
 
-		if d.DeletionTimestamp != nil {
-			return false, fmt.Errorf("deployment %q is being deleted", resource.Name)
+	for _, clusterRole := range []*rbacv1.ClusterRole{newClusterRole(), newRemediationClusterRole()} {
+		if _, updated, err := applyClusterRole(optr.kubeClient.RbacV1(), clusterRole); err != nil {
+			return fmt.Errorf("error applying cluster role %s: %v", clusterRole.Name, err)
+		} else if updated {
+			glog.V(4).Infof("Applied cluster role %s", clusterRole.Name)
 		}
+	}
 
-		if d.Generation <= d.Status.ObservedGeneration && d.Status.UpdatedReplicas == d.Status.Replicas && d.Status.UnavailableReplicas == 0 {
-			return true, nil
+	for _, clusterRoleBinding := range []*rbacv1.ClusterRoleBinding{newClusterRoleBinding(config), newRemediationClusterRoleBinding(config)} {
+		if _, updated, err := applyClusterRoleBinding(optr.kubeClient.RbacV1(), clusterRoleBinding); err != nil {
+			return fmt.Errorf("error applying cluster role binding %s: %v", clusterRoleBinding.Name, err)
+		} else if updated {
+			glog.V(4).Infof("Applied cluster role binding %s", clusterRoleBinding.Name)
 		}
-		glog.V(4).Infof("Deployment %q is not ready. status: (replicas: %d, updated: %d, ready: %d, unavailable: %d)", d.Name, d.Status.Replicas, d.Status.UpdatedReplicas, d.Status.ReadyReplicas, d.Status.UnavailableReplicas)
-		return false, nil
-	})
+	}
+
+	role := newRole(config)
+	if _, updated, err := applyRole(optr.kubeClient.RbacV1(), role); err != nil {
+		return fmt.Errorf("error applying role %s: %v", role.Name, err)
+	} else if updated {
+		glog.V(4).Infof("Applied role %s", role.Name)
+	}
+
+	roleBinding := newRoleBinding(config)
+	if _, updated, err := applyRoleBinding(optr.kubeClient.RbacV1(), roleBinding); err != nil {
+		return fmt.Errorf("error applying role binding %s: %v", roleBinding.Name, err)
+	} else if updated {
+		glog.V(4).Infof("Applied role binding %s", roleBinding.Name)
+	}
+	return nil
+}
+
+// syncMachineHealthCheckWebhook applies the service of the admission webhook served by the machine
+// health check controller and the webhook configuration validating the machine health checks.
+func (optr *Operator) syncMachineHealthCheckWebhook(config *Config) error {
+	service := newService(config)
+	if _, updated, err := applyService(optr.kubeClient.CoreV1(), service); err != nil {
+		return fmt.Errorf("error applying service %s: %v", service.Name, err)
+	} else if updated {
+		glog.V(4).Infof("Applied service %s", service.Name)
+	}
+
+	webhookConfiguration := newValidatingWebhookConfiguration(config)
+	if _, updated, err := applyValidatingWebhookConfiguration(optr.kubeClient.AdmissionregistrationV1beta1(), webhookConfiguration); err != nil {
+		return fmt.Errorf("error applying validating webhook configuration %s: %v", webhookConfiguration.Name, err)
+	} else if updated {
+		glog.V(4).Infof("Applied validating webhook configuration %s", webhookConfiguration.Name)
+	}
+	return nil
+}
+
+func (optr *Operator) syncMachineHealthCheckController(config *Config) (RolloutState, string, error) {
+	controller := newDeployment(config, config.TechPreviewEnabled)
+	actual, updated, err := applyDeployment(optr.kubeClient.AppsV1(), controller)
+	if err != nil {
+		return "", "", err
+	}
+	if updated {
+		glog.V(4).Infof("Applied deployment %s", controller.Name)
+	}
+
+	state, message := deploymentRolloutState(actual)
+	reportRolloutState(actual.Name, state)
+	return state, message, nil
 }
 
 func newDeployment(config *Config, techPreviewEnabled bool) *appsv1.Deployment {
-	replicas := int32(1)
+	replicas := *config.Spec.Replicas
 	if techPreviewEnabled {
 		replicas = int32(0)
 	}
@@ -74,14 +167,15 @@ func newDeployment(config *Config, techPreviewEnabled bool) *appsv1.Deployment {
 
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "machine-health-check-controller",
+			Name:      machineHealthCheckControllerName,
 			Namespace: config.TargetNamespace,
 			Labels: map[string]string{
 				ManagedByLabel: ManagedByLabelOperatorValue,
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
+			Replicas:                &replicas,
+			ProgressDeadlineSeconds: progressDeadlineSeconds(config.RolloutDeadline),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					ManagedByLabel: ManagedByLabelOperatorValue,
@@ -92,6 +186,13 @@ func newDeployment(config *Config, techPreviewEnabled bool) *appsv1.Deployment {
 	}
 }
 
+func progressDeadlineSeconds(deadline time.Duration) *int32 {
+	if deadline <= 0 {
+		return nil
+	}
+	return pointer.Int32Ptr(int32(deadline / time.Second))
+}
+
 func newPodTemplateSpec(config *Config) *corev1.PodTemplateSpec {
 	containers := newContainers(config)
 
@@ -100,29 +201,6 @@ func newPodTemplateSpec(config *Config) *corev1.PodTemplateSpec {
 		return nil
 	}
 
-	tolerations := []corev1.Toleration{
-		{
-			Key:    "node-role.kubernetes.io/master",
-			Effect: corev1.TaintEffectNoSchedule,
-		},
-		{
-			Key:      "CriticalAddonsOnly",
-			Operator: corev1.TolerationOpExists,
-		},
-		{
-			Key:               "node.kubernetes.io/not-ready",
-			Effect:            corev1.TaintEffectNoExecute,
-			Operator:          corev1.TolerationOpExists,
-			TolerationSeconds: pointer.Int64Ptr(120),
-		},
-		{
-			Key:               "node.kubernetes.io/unreachable",
-			Effect:            corev1.TaintEffectNoExecute,
-			Operator:          corev1.TolerationOpExists,
-			TolerationSeconds: pointer.Int64Ptr(120),
-		},
-	}
-
 	return &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels: map[string]string{
@@ -132,60 +210,63 @@ func newPodTemplateSpec(config *Config) *corev1.PodTemplateSpec {
 		Spec: corev1.PodSpec{
 			Containers:        containers,
 			PriorityClassName: "system-node-critical",
-			NodeSelector:      map[string]string{"node-role.kubernetes.io/master": ""},
+			NodeSelector:      config.Spec.NodeSelector,
 			SecurityContext: &corev1.PodSecurityContext{
 				RunAsNonRoot: pointer.BoolPtr(true),
 				RunAsUser:    pointer.Int64Ptr(65534),
 			},
-			ServiceAccountName: "machine-api-controllers",
-			Tolerations:        tolerations,
+			ServiceAccountName: machineHealthCheckControllerServiceAccount,
+			Tolerations:        config.Spec.Tolerations,
+			Volumes: []corev1.Volume{
+				{
+					Name: "webhook-cert",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName:  machineHealthCheckWebhookCertSecret,
+							DefaultMode: pointer.Int32Ptr(0644),
+						},
+					},
+				},
+			},
 		},
 	}
 }
 
 func newContainers(config *Config) []corev1.Container {
-	resources := corev1.ResourceRequirements{
-		Requests: map[corev1.ResourceName]resource.Quantity{
-			corev1.ResourceMemory: resource.MustParse("20Mi"),
-			corev1.ResourceCPU:    resource.MustParse("10m"),
-		},
-	}
 	args := []string{
+		"controller",
 		"--logtostderr=true",
-		"--v=3",
-		// Available only in 4.2
-		//fmt.Sprintf("--namespace=%s", config.TargetNamespace),
+		fmt.Sprintf("--v=%d", *config.Spec.LogLevel),
+		fmt.Sprintf("--namespace=%s", config.TargetNamespace),
+		fmt.Sprintf("--webhook-addr=:%d", machineHealthCheckWebhookPort),
+		fmt.Sprintf("--webhook-cert-dir=%s", machineHealthCheckWebhookCertDir),
+	}
+	if config.Spec.DryRun {
+		args = append(args, "--dry-run=true")
 	}
+	args = append(args, config.Spec.ExtraArgs...)
 
 	return []corev1.Container{
 		corev1.Container{
-			Name:      "machine-health-check-controller",
+			Name:      machineHealthCheckControllerName,
 			Image:     config.Controllers.MachineHealthCheck,
-			Command:   []string{"/machine-healthcheck"},
+			Command:   []string{"/usr/bin/machine-health-check-operator"},
 			Args:      args,
-			Resources: resources,
+			Resources: *config.Spec.Resources,
+			Ports: []corev1.ContainerPort{
+				{
+					Name:          "webhook",
+					ContainerPort: machineHealthCheckWebhookPort,
+					Protocol:      corev1.ProtocolTCP,
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      "webhook-cert",
+					MountPath: machineHealthCheckWebhookCertDir,
+					ReadOnly:  true,
+				},
+			},
 		},
 	}
 }
-
-// applyDeploymentReplicas applies the required deployment replicas to the cluster
-func applyDeploymentReplicas(client appsclientv1.DeploymentsGetter, deployment *appsv1.Deployment) (bool, error) {
-	existing, err := client.Deployments(deployment.Namespace).Get(deployment.Name, metav1.GetOptions{})
-	if apierrors.IsNotFound(err) {
-		_, err := client.Deployments(deployment.Namespace).Create(deployment)
-		return true, err
-	}
-	if err != nil {
-		return false, err
-	}
-
-	modified := false
-	if *existing.Spec.Replicas != *deployment.Spec.Replicas {
-		_, err = client.Deployments(deployment.Namespace).Update(deployment)
-		if err != nil {
-			return modified, err
-		}
-		modified = true
-	}
-	return modified, nil
-}
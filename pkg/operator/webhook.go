@@ -0,0 +1,97 @@
+package operator
+
+import (
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+	"github.com/openshift/machine-health-check-operator/pkg/webhook"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// machineHealthCheckWebhookName contains the name of the webhook validating the machine health checks
+	machineHealthCheckWebhookName = "validation.machinehealthchecks.healthchecking.openshift.io"
+	// machineHealthCheckWebhookCertSecret contains the name of the secret with the serving certificate of the webhook
+	machineHealthCheckWebhookCertSecret = machineHealthCheckControllerName + "-webhook-cert"
+	// machineHealthCheckWebhookCertDir contains the directory the serving certificate of the webhook is mounted at
+	machineHealthCheckWebhookCertDir = "/etc/machine-health-check-controller/webhook-certs"
+	// machineHealthCheckWebhookPort contains the port the webhook server of the controller listens on
+	machineHealthCheckWebhookPort = 9443
+
+	// servingCertSecretAnnotation requests the service CA operator to create the secret with the serving certificate of the service
+	servingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	// injectCABundleAnnotation requests the service CA operator to inject the service CA bundle into the webhook client configs
+	injectCABundleAnnotation = "service.beta.openshift.io/inject-cabundle"
+)
+
+// newService returns the service of the admission webhook served by the machine health check
+// controller, the service CA operator creates the secret with its serving certificate
+func newService(config *Config) *corev1.Service {
+	objectMeta := newObjectMeta(machineHealthCheckControllerName, config.TargetNamespace)
+	objectMeta.Annotations = map[string]string{
+		servingCertSecretAnnotation: machineHealthCheckWebhookCertSecret,
+	}
+	return &corev1.Service{
+		ObjectMeta: objectMeta,
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				ManagedByLabel: ManagedByLabelOperatorValue,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "webhook",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       443,
+					TargetPort: intstr.FromInt(machineHealthCheckWebhookPort),
+				},
+			},
+		},
+	}
+}
+
+// newValidatingWebhookConfiguration returns the webhook configuration rejecting the machine health
+// checks with an invalid spec on admission. The machine health checks are admitted while the
+// controller is not available, their spec is then reported by the SpecValid condition instead.
+func newValidatingWebhookConfiguration(config *Config) *admissionregistrationv1beta1.ValidatingWebhookConfiguration {
+	objectMeta := newObjectMeta(machineHealthCheckControllerName, "")
+	objectMeta.Annotations = map[string]string{
+		injectCABundleAnnotation: "true",
+	}
+	failurePolicy := admissionregistrationv1beta1.Ignore
+	sideEffects := admissionregistrationv1beta1.SideEffectClassNone
+	scope := admissionregistrationv1beta1.NamespacedScope
+	return &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: objectMeta,
+		Webhooks: []admissionregistrationv1beta1.Webhook{
+			{
+				Name: machineHealthCheckWebhookName,
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Namespace: config.TargetNamespace,
+						Name:      machineHealthCheckControllerName,
+						Path:      pointer.StringPtr(webhook.ValidateMachineHealthCheckPath),
+					},
+				},
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{healthcheckingv1alpha1.GroupName},
+							APIVersions: []string{healthcheckingv1alpha1.GroupVersion.Version},
+							Resources:   []string{"machinehealthchecks"},
+							Scope:       &scope,
+						},
+					},
+				},
+				FailurePolicy:           &failurePolicy,
+				NamespaceSelector:       &metav1.LabelSelector{},
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          pointer.Int32Ptr(10),
+				AdmissionReviewVersions: []string{"v1beta1"},
+			},
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package resourcelock
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	coordinationv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// ConfigMapsLeasesResourceLock is the lock type that holds both the configmap and the lease lock.
+	// It is used to migrate from the configmap lock to the lease lock without two leaders
+	// running at the same time while the operator is being upgraded.
+	ConfigMapsLeasesResourceLock = "configmapsleases"
+
+	// UnknownLeader is the holder identity reported when the primary and the secondary
+	// locks are held by different identities
+	UnknownLeader = "leaderelection.k8s.io/unknown"
+)
+
+// MultiLock is used for lock's migration. It acquires the primary lock first and
+// keeps the secondary lock in sync with it.
+type MultiLock struct {
+	Primary   rl.Interface
+	Secondary rl.Interface
+}
+
+// Get returns the election record of the primary lock. The holder identity is reported
+// as unknown when the locks are held by different identities.
+func (ml *MultiLock) Get() (*rl.LeaderElectionRecord, error) {
+	primary, err := ml.Primary.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	secondary, err := ml.Secondary.Get()
+	if err != nil {
+		// the lock is held by a client that knows only the primary lock
+		if apierrors.IsNotFound(err) && primary.HolderIdentity != ml.Identity() {
+			return primary, nil
+		}
+		return nil, err
+	}
+
+	if primary.HolderIdentity != secondary.HolderIdentity {
+		primary.HolderIdentity = UnknownLeader
+	}
+	return primary, nil
+}
+
+// Create attempts to create both the primary and the secondary lock
+func (ml *MultiLock) Create(ler rl.LeaderElectionRecord) error {
+	if err := ml.Primary.Create(ler); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return ml.Secondary.Create(ler)
+}
+
+// Update updates the primary lock and creates or updates the secondary one
+func (ml *MultiLock) Update(ler rl.LeaderElectionRecord) error {
+	if err := ml.Primary.Update(ler); err != nil {
+		return err
+	}
+	if _, err := ml.Secondary.Get(); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ml.Secondary.Create(ler)
+		}
+		return err
+	}
+	return ml.Secondary.Update(ler)
+}
+
+// RecordEvent records the event on both locks
+func (ml *MultiLock) RecordEvent(s string) {
+	ml.Primary.RecordEvent(s)
+	ml.Secondary.RecordEvent(s)
+}
+
+// Identity returns the identity of the primary lock
+func (ml *MultiLock) Identity() string {
+	return ml.Primary.Identity()
+}
+
+// Describe describes the primary lock
+func (ml *MultiLock) Describe() string {
+	return ml.Primary.Describe()
+}
+
+// New creates a lock of the given type. Besides the lock types supported by
+// client-go, it supports the configmapsleases lock type.
+func New(lockType string, ns string, name string, coreClient corev1.CoreV1Interface, coordinationClient coordinationv1.CoordinationV1Interface, rlc rl.ResourceLockConfig) (rl.Interface, error) {
+	if lockType != ConfigMapsLeasesResourceLock {
+		return rl.New(lockType, ns, name, coreClient, coordinationClient, rlc)
+	}
+
+	primary, err := rl.New(rl.ConfigMapsResourceLock, ns, name, coreClient, coordinationClient, rlc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create primary lock: %v", err)
+	}
+	secondary, err := rl.New(rl.LeasesResourceLock, ns, name, coreClient, coordinationClient, rlc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secondary lock: %v", err)
+	}
+	return &MultiLock{
+		Primary:   primary,
+		Secondary: secondary,
+	}, nil
+}
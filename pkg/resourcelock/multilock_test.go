@@ -0,0 +1,126 @@
+package resourcelock
+
+import (
+	"testing"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	lockNamespace = "openshift-machine-api"
+	lockName      = "machine-health-check-operator"
+)
+
+func newConfigMapLockObject(holder string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: lockNamespace,
+			Name:      lockName,
+			Annotations: map[string]string{
+				rl.LeaderElectionRecordAnnotationKey: `{"holderIdentity":"` + holder + `","leaseDurationSeconds":90}`,
+			},
+		},
+	}
+}
+
+func newLeaseLockObject(holder string) *coordinationv1.Lease {
+	now := metav1.NowMicro()
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: lockNamespace,
+			Name:      lockName,
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       pointer.StringPtr(holder),
+			LeaseDurationSeconds: pointer.Int32Ptr(90),
+			AcquireTime:          &now,
+			RenewTime:            &now,
+			LeaseTransitions:     pointer.Int32Ptr(0),
+		},
+	}
+}
+
+func newMultiLock(t *testing.T, identity string, objects ...runtime.Object) (rl.Interface, *fakekube.Clientset) {
+	kubeClient := fakekube.NewSimpleClientset(objects...)
+	lock, err := New(ConfigMapsLeasesResourceLock, lockNamespace, lockName, kubeClient.CoreV1(), kubeClient.CoordinationV1(), rl.ResourceLockConfig{Identity: identity})
+	if err != nil {
+		t.Fatalf("Failed to create lock: %v", err)
+	}
+	return lock, kubeClient
+}
+
+func TestMultiLockGet(t *testing.T) {
+	tests := []struct {
+		name           string
+		existing       []runtime.Object
+		expectedHolder string
+	}{{
+		name:           "both locks held by the same identity",
+		existing:       []runtime.Object{newConfigMapLockObject("a"), newLeaseLockObject("a")},
+		expectedHolder: "a",
+	}, {
+		name:           "only the configmap lock held by an old client",
+		existing:       []runtime.Object{newConfigMapLockObject("old")},
+		expectedHolder: "old",
+	}, {
+		name:           "locks held by different identities",
+		existing:       []runtime.Object{newConfigMapLockObject("a"), newLeaseLockObject("b")},
+		expectedHolder: UnknownLeader,
+	}}
+
+	for _, tc := range tests {
+		lock, _ := newMultiLock(t, "me", tc.existing...)
+		record, err := lock.Get()
+		if err != nil {
+			t.Errorf("%s: failed to get lock: %v", tc.name, err)
+			continue
+		}
+		if record.HolderIdentity != tc.expectedHolder {
+			t.Errorf("%s: expected holder %q, got %q", tc.name, tc.expectedHolder, record.HolderIdentity)
+		}
+	}
+}
+
+func TestMultiLockCreateAndUpdate(t *testing.T) {
+	lock, kubeClient := newMultiLock(t, "me")
+	if _, err := lock.Get(); !apierrors.IsNotFound(err) {
+		t.Fatalf("Expected not found error for missing locks, got %v", err)
+	}
+
+	if err := lock.Create(rl.LeaderElectionRecord{HolderIdentity: "me", LeaseDurationSeconds: 90}); err != nil {
+		t.Fatalf("Failed to create lock: %v", err)
+	}
+	if _, err := kubeClient.CoreV1().ConfigMaps(lockNamespace).Get(lockName, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected configmap lock to be created: %v", err)
+	}
+	if _, err := kubeClient.CoordinationV1().Leases(lockNamespace).Get(lockName, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected lease lock to be created: %v", err)
+	}
+
+	// the lease lock is created on update when only the configmap lock exists
+	lock, kubeClient = newMultiLock(t, "me", newConfigMapLockObject("old"))
+	if _, err := lock.Get(); err != nil {
+		t.Fatalf("Failed to get lock: %v", err)
+	}
+	if err := lock.Update(rl.LeaderElectionRecord{HolderIdentity: "me", LeaseDurationSeconds: 90}); err != nil {
+		t.Fatalf("Failed to update lock: %v", err)
+	}
+	lease, err := kubeClient.CoordinationV1().Leases(lockNamespace).Get(lockName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected lease lock to be created: %v", err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "me" {
+		t.Errorf("Expected lease lock to be held by %q, got %v", "me", lease.Spec.HolderIdentity)
+	}
+	record, err := lock.Get()
+	if err != nil || record.HolderIdentity != "me" {
+		t.Errorf("Expected lock to be held by %q, got %v (%v)", "me", record, err)
+	}
+}
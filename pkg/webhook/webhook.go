@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/glog"
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// DefaultWebhookAddress contains the default address the webhook server listens on
+	DefaultWebhookAddress = ":9443"
+	// ValidateMachineHealthCheckPath contains the path the machine health checks are validated at
+	ValidateMachineHealthCheckPath = "/validate-machinehealthcheck"
+
+	// certFile and keyFile contain the names of the serving certificate files in the certificate directory
+	certFile = "tls.crt"
+	keyFile  = "tls.key"
+
+	serverShutdownTimeout = 5 * time.Second
+)
+
+// machineHealthCheckKind is the kind the validation errors of the machine health checks are reported for
+var machineHealthCheckKind = schema.GroupKind{Group: healthcheckingv1alpha1.GroupName, Kind: "MachineHealthCheck"}
+
+// MachineHealthCheckValidator is an http.Handler that reviews the admission of the machine health
+// checks and rejects the ones whose spec is invalid with the errors of the spec validation.
+type MachineHealthCheckValidator struct{}
+
+// ServeHTTP decodes the admission review of the request and responds with the reviewed one
+func (v *MachineHealthCheckValidator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read the request: %v", err), http.StatusBadRequest)
+		return
+	}
+	review := &admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("failed to decode the admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	review.Response = v.review(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		glog.Errorf("Failed to write the admission review: %v", err)
+	}
+}
+
+// review validates the defaulted spec of the machine health check of the request. An update keeping
+// the spec is allowed, so the metadata of a machine health check admitted before the validation was
+// enabled can still be updated.
+func (v *MachineHealthCheckValidator) review(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	mhc := &healthcheckingv1alpha1.MachineHealthCheck{}
+	if err := json.Unmarshal(req.Object.Raw, mhc); err != nil {
+		return denied(apierrors.NewBadRequest(fmt.Sprintf("failed to decode the machine health check: %v", err)))
+	}
+	if req.Operation == admissionv1beta1.Update {
+		old := &healthcheckingv1alpha1.MachineHealthCheck{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err == nil && equality.Semantic.DeepEqual(old.Spec, mhc.Spec) {
+			return &admissionv1beta1.AdmissionResponse{Allowed: true}
+		}
+	}
+
+	spec := mhc.Spec.DeepCopy()
+	healthcheckingv1alpha1.SetDefaultsMachineHealthCheckSpec(spec)
+	if errs := healthcheckingv1alpha1.ValidateMachineHealthCheckSpec(spec, field.NewPath("spec")); len(errs) > 0 {
+		glog.V(2).Infof("Rejected machine health check %s/%s: %v", req.Namespace, mhc.Name, errs.ToAggregate())
+		return denied(apierrors.NewInvalid(machineHealthCheckKind, mhc.Name, errs))
+	}
+	return &admissionv1beta1.AdmissionResponse{Allowed: true}
+}
+
+func denied(err *apierrors.StatusError) *admissionv1beta1.AdmissionResponse {
+	status := err.Status()
+	return &admissionv1beta1.AdmissionResponse{Allowed: false, Result: &status}
+}
+
+// StartWebhookServer starts serving the admission webhooks over TLS until the stop channel is
+// closed, the serving certificate and its key are read from the certificate directory.
+func StartWebhookServer(addr, certDir string, stopCh <-chan struct{}) {
+	mux := http.NewServeMux()
+	mux.Handle(ValidateMachineHealthCheckPath, &MachineHealthCheckValidator{})
+	server := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	go func() {
+		glog.Infof("Starting webhook server at %s", addr)
+		if err := server.ListenAndServeTLS(filepath.Join(certDir, certFile), filepath.Join(certDir, keyFile)); err != nil && err != http.ErrServerClosed {
+			glog.Errorf("Webhook server failed: %v", err)
+		}
+	}()
+
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			glog.Errorf("Failed to shut down the webhook server: %v", err)
+		}
+	}()
+}
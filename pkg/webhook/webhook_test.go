@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	healthcheckingv1alpha1 "github.com/openshift/machine-health-check-operator/pkg/apis/healthchecking/v1alpha1"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newMachineHealthCheck(expression string) *healthcheckingv1alpha1.MachineHealthCheck {
+	return &healthcheckingv1alpha1.MachineHealthCheck{
+		TypeMeta:   metav1.TypeMeta{APIVersion: healthcheckingv1alpha1.GroupVersion.String(), Kind: "MachineHealthCheck"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-machine-api", Name: "workers"},
+		Spec: healthcheckingv1alpha1.MachineHealthCheckSpec{
+			UnhealthyExpressions: []healthcheckingv1alpha1.UnhealthyExpression{{
+				Name:       "memory",
+				Expression: expression,
+			}},
+		},
+	}
+}
+
+func rawExtension(t *testing.T, mhc *healthcheckingv1alpha1.MachineHealthCheck) runtime.RawExtension {
+	if mhc == nil {
+		return runtime.RawExtension{}
+	}
+	raw, err := json.Marshal(mhc)
+	if err != nil {
+		t.Fatalf("Failed to encode machine health check: %v", err)
+	}
+	return runtime.RawExtension{Raw: raw}
+}
+
+func TestMachineHealthCheckValidator(t *testing.T) {
+	valid := newMachineHealthCheck(`condition("MemoryPressure") == "True"`)
+	invalid := newMachineHealthCheck(`condition("MemoryPressure") = "True"`)
+	relabeled := invalid.DeepCopy()
+	relabeled.Labels = map[string]string{"team": "infra"}
+
+	tests := []struct {
+		name            string
+		operation       admissionv1beta1.Operation
+		object          *healthcheckingv1alpha1.MachineHealthCheck
+		oldObject       *healthcheckingv1alpha1.MachineHealthCheck
+		expectedAllowed bool
+		// expectedMessage is a part of the message of the rejection
+		expectedMessage string
+	}{{
+		name:            "valid machine health check created",
+		operation:       admissionv1beta1.Create,
+		object:          valid,
+		expectedAllowed: true,
+	}, {
+		name:            "invalid expression created",
+		operation:       admissionv1beta1.Create,
+		object:          invalid,
+		expectedMessage: "spec.unhealthyExpressions[0].expression",
+	}, {
+		name:            "valid spec updated with an invalid expression",
+		operation:       admissionv1beta1.Update,
+		object:          invalid,
+		oldObject:       valid,
+		expectedMessage: "spec.unhealthyExpressions[0].expression",
+	}, {
+		name:            "invalid spec kept by the update",
+		operation:       admissionv1beta1.Update,
+		object:          relabeled,
+		oldObject:       invalid,
+		expectedAllowed: true,
+	}}
+
+	for _, tc := range tests {
+		review := &admissionv1beta1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: admissionv1beta1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+			Request: &admissionv1beta1.AdmissionRequest{
+				UID:       types.UID(tc.name),
+				Namespace: tc.object.Namespace,
+				Name:      tc.object.Name,
+				Operation: tc.operation,
+				Object:    rawExtension(t, tc.object),
+				OldObject: rawExtension(t, tc.oldObject),
+			},
+		}
+		body, err := json.Marshal(review)
+		if err != nil {
+			t.Fatalf("%s: failed to encode admission review: %v", tc.name, err)
+		}
+
+		w := httptest.NewRecorder()
+		(&MachineHealthCheckValidator{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, ValidateMachineHealthCheckPath, bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d: %s", tc.name, http.StatusOK, w.Code, w.Body.String())
+		}
+		reviewed := &admissionv1beta1.AdmissionReview{}
+		if err := json.Unmarshal(w.Body.Bytes(), reviewed); err != nil || reviewed.Response == nil {
+			t.Fatalf("%s: failed to decode admission review %q: %v", tc.name, w.Body.String(), err)
+		}
+		response := reviewed.Response
+		if response.UID != review.Request.UID {
+			t.Errorf("%s: expected response uid %q, got %q", tc.name, review.Request.UID, response.UID)
+		}
+		if response.Allowed != tc.expectedAllowed {
+			t.Errorf("%s: expected allowed %t, got %t: %v", tc.name, tc.expectedAllowed, response.Allowed, response.Result)
+		}
+		if tc.expectedMessage == "" {
+			continue
+		}
+		if response.Result == nil || response.Result.Reason != metav1.StatusReasonInvalid || !strings.Contains(response.Result.Message, tc.expectedMessage) {
+			t.Errorf("%s: expected invalid status with message containing %q, got %v", tc.name, tc.expectedMessage, response.Result)
+		}
+	}
+}
+
+func TestMachineHealthCheckValidatorMalformedReview(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&MachineHealthCheckValidator{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, ValidateMachineHealthCheckPath, strings.NewReader("{}")))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an admission review without request, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -7,12 +7,20 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 )
 
+const (
+	// operatorName contains the name of the machine-health-check-operator deployment, service account and roles
+	operatorName = "machine-health-check-operator"
+	// metricsPort contains the port the operator serves its metrics and health probes on
+	metricsPort = 8080
+)
+
 // NewOperatorDeployment returns deployment object that represents machine-health-check-operator
 func NewOperatorDeployment(namespace string, repository string, version string, pullPolicy corev1.PullPolicy, verbosity string) (*appsv1.Deployment, error) {
-	name := "machine-health-check-operator"
+	name := operatorName
 	image := fmt.Sprintf("%s/%s:%s", repository, name, version)
 	labels := map[string]string{"k8s-app": "machine-health-check-operator"}
 	tolerations := []corev1.Toleration{
@@ -67,7 +75,7 @@ func NewOperatorDeployment(namespace string, repository string, version string,
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
-					ServiceAccountName: "machine-api-operator",
+					ServiceAccountName: operatorName,
 					PriorityClassName:  "system-node-critical",
 					NodeSelector:       map[string]string{"node-role.kubernetes.io/master": ""},
 					SecurityContext: &corev1.PodSecurityContext{
@@ -89,6 +97,10 @@ func NewOperatorDeployment(namespace string, repository string, version string,
 								verbosity,
 							},
 							Env: []corev1.EnvVar{
+								{
+									Name:  "RELEASE_VERSION",
+									Value: version,
+								},
 								{
 									Name: "COMPONENT_NAMESPACE",
 									ValueFrom: &corev1.EnvVarSource{
@@ -98,7 +110,15 @@ func NewOperatorDeployment(namespace string, repository string, version string,
 									},
 								},
 							},
-							Resources: resources,
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "metrics",
+									ContainerPort: metricsPort,
+								},
+							},
+							LivenessProbe:  newProbe("/healthz"),
+							ReadinessProbe: newProbe("/readyz"),
+							Resources:      resources,
 						},
 					},
 				},
@@ -108,3 +128,19 @@ func NewOperatorDeployment(namespace string, repository string, version string,
 
 	return deployment, nil
 }
+
+// newProbe returns a probe checking the given path served next to the operator metrics
+func newProbe(path string) *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: path,
+				Port: intstr.FromInt(metricsPort),
+			},
+		},
+		InitialDelaySeconds: 10,
+		PeriodSeconds:       10,
+		TimeoutSeconds:      5,
+		FailureThreshold:    3,
+	}
+}
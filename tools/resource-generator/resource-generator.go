@@ -31,7 +31,7 @@ import (
 )
 
 func main() {
-	resourceType := flag.String("type", "", "Type of resource to generate. vmi | vmipreset | vmirs | vm | vmim | kv | rbac")
+	resourceType := flag.String("type", "", "Type of resource to generate. machine-health-check-operator | namespace | rbac")
 	namespace := flag.String("namespace", "kube-system", "Namespace to use.")
 	repository := flag.String("repository", "kubevirt", "Image Repository to use.")
 	version := flag.String("version", "latest", "version to use.")
@@ -50,6 +50,19 @@ func main() {
 
 		}
 		utils.MarshallObject(operator, os.Stdout)
+	case "namespace":
+		utils.MarshallObject(components.NewNamespace(*namespace), os.Stdout)
+	case "rbac":
+		all := []interface{}{
+			components.NewOperatorServiceAccount(*namespace),
+			components.NewOperatorClusterRole(),
+			components.NewOperatorClusterRoleBinding(*namespace),
+			components.NewOperatorRole(*namespace),
+			components.NewOperatorRoleBinding(*namespace),
+		}
+		for _, r := range all {
+			utils.MarshallObject(r, os.Stdout)
+		}
 	default:
 		panic(fmt.Errorf("unknown resource type %s", *resourceType))
 	}